@@ -2,8 +2,14 @@ package main
 
 import (
 	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"time"
 
 	"github.com/alecthomas/kong"
+	"github.com/walles/ftop/internal/ftop"
+	"github.com/walles/ftop/internal/snapshots"
 )
 
 type commandLine struct {
@@ -11,6 +17,25 @@ type commandLine struct {
 	Theme         ThemeName `help:"auto, dark or light" default:"auto"`
 	Debug         bool      `help:"print debug logs after exit"`
 	InitialFilter string    `arg:"" optional:"" name:"filter" help:"initial process filter"`
+	Sort          SortName  `help:"score, cpu, ram, pid, newest, launches or io, press s to change it while running"`
+
+	Batch      bool         `help:"print snapshots to stdout instead of running interactively"`
+	Output     OutputFormat `help:"text, json or ndjson, implies --batch"`
+	Iterations int          `help:"number of snapshots to print in batch mode, 0 means no limit" default:"1"`
+	Interval   Seconds      `help:"seconds between batch mode snapshots" default:"2"`
+
+	Record string        `help:"save all snapshots to this file, for replaying later" type:"path"`
+	Replay string        `help:"replay a recording made using --record" type:"existingfile"`
+	Rewind time.Duration `help:"how far back the left arrow key can step, like 5m. Uses more RAM the longer it is." default:"10m"`
+
+	ServeMetrics string `help:"serve Prometheus metrics on this address, like :9642, instead of running interactively" placeholder:"ADDRESS"`
+	ServeHttp    string `help:"serve a live web dashboard on this address, like :8080, instead of running interactively" placeholder:"ADDRESS"`
+
+	Agent     bool   `help:"stream snapshots to a remote ftop --connect, over stdin and stdout unless --listen is given"`
+	Listen    string `help:"with --agent, accept connections on this address, like :9643. Unencrypted and unauthenticated, so it can't be combined with --allow-kill." placeholder:"ADDRESS"`
+	AllowKill bool   `help:"with --agent over stdin and stdout, let clients signal processes. With --connect or --hosts over SSH, start the agents with this option."`
+	Connect   string `help:"show another machine's processes. HOST:PORT connects to ftop --agent --listen, anything else is an SSH destination to run ftop --agent on." placeholder:"HOST"`
+	Hosts     string `help:"show an overview of the machines listed in this file, one SSH destination per line" type:"existingfile"`
 
 	// Hidden options for development use
 	Profile bool `help:"generate profile-*.out files before exiting" hidden:"true"`
@@ -19,6 +44,110 @@ type commandLine struct {
 
 var CLI commandLine
 
+func (c commandLine) Validate() error {
+	modes := c.nonInteractiveModes()
+	if len(modes) > 1 {
+		return fmt.Errorf("%s can't be combined with %s", modes[0], modes[1])
+	}
+
+	if c.Sort != "" && len(modes) > 0 && modes[0] != "batch mode" {
+		return fmt.Errorf("--sort can't be combined with %s", modes[0])
+	}
+
+	if c.Rewind < 0 {
+		return fmt.Errorf("--rewind must not be negative: %s", c.Rewind)
+	}
+
+	if c.Listen != "" && !c.Agent {
+		return fmt.Errorf("--listen only works with --agent")
+	}
+	if c.AllowKill && !c.Agent && c.Connect == "" && c.Hosts == "" {
+		return fmt.Errorf("--allow-kill only works with --agent, --connect or --hosts")
+	}
+	if c.AllowKill && c.Listen != "" {
+		// Anybody who can reach the port could kill anything we can kill
+		return fmt.Errorf("--allow-kill can't be combined with --listen, killing only works over SSH")
+	}
+
+	if c.Hosts != "" {
+		if len(modes) > 0 {
+			return fmt.Errorf("--hosts can't be combined with %s", modes[0])
+		}
+		if c.Connect != "" {
+			return fmt.Errorf("--hosts can't be combined with --connect")
+		}
+		if c.Replay != "" {
+			return fmt.Errorf("--hosts can't be combined with --replay")
+		}
+		if c.Record != "" {
+			return fmt.Errorf("--hosts can't be combined with --record")
+		}
+	}
+
+	if c.Connect != "" {
+		if c.Agent {
+			return fmt.Errorf("--connect can't be combined with --agent")
+		}
+		if c.Replay != "" {
+			return fmt.Errorf("--connect can't be combined with --replay")
+		}
+		if c.AllowKill && c.connectsOverTcp() {
+			return fmt.Errorf("--allow-kill only works over SSH, not when connecting to HOST:PORT")
+		}
+	}
+
+	if c.Replay == "" {
+		return nil
+	}
+
+	if c.Record != "" {
+		return fmt.Errorf("--replay can't be combined with --record")
+	}
+	if len(modes) > 0 {
+		return fmt.Errorf("--replay can't be combined with %s", modes[0])
+	}
+
+	return nil
+}
+
+// True if --connect is a HOST:PORT, false if it's an SSH destination
+func (c commandLine) connectsOverTcp() bool {
+	_, port, err := net.SplitHostPort(c.Connect)
+	if err != nil {
+		return false
+	}
+
+	_, err = strconv.Atoi(port)
+	return err == nil
+}
+
+// The command to run for starting an agent on the --connect SSH destination
+func (c commandLine) sshAgentCommand() []string {
+	// Interactive, we haven't started the UI yet, so SSH can prompt for
+	// passwords
+	return snapshots.SshAgentCommand(c.Connect, c.AllowKill, true)
+}
+
+// Names of the requested modes that replace the interactive UI. At most one of
+// these can be used at a time.
+func (c commandLine) nonInteractiveModes() []string {
+	var modes []string
+	if c.Batch || c.Output != "" {
+		modes = append(modes, "batch mode")
+	}
+	if c.ServeMetrics != "" {
+		modes = append(modes, "--serve-metrics")
+	}
+	if c.ServeHttp != "" {
+		modes = append(modes, "--serve-http")
+	}
+	if c.Agent {
+		modes = append(modes, "--agent")
+	}
+
+	return modes
+}
+
 func newArgsParser() (*kong.Kong, error) {
 	return kong.New(
 		&CLI,
@@ -40,3 +169,52 @@ func (t ThemeName) Validate() error {
 func (t ThemeName) String() string {
 	return string(t)
 }
+
+type SortName string
+
+func (s SortName) Validate() error {
+	if s == "" || slices.Contains(ftop.SortModes(), ftop.SortMode(s)) {
+		return nil
+	}
+	return fmt.Errorf(`must be "score", "cpu", "ram", "pid", "newest", "launches" or "io": <%s>`, s)
+}
+
+// Score unless something else was asked for
+func (s SortName) SortMode() ftop.SortMode {
+	if s == "" {
+		return ftop.SortModeScore
+	}
+	return ftop.SortMode(s)
+}
+
+type Seconds float64
+
+func (s Seconds) Validate() error {
+	if s <= 0 {
+		return fmt.Errorf("must be positive: <%g>", float64(s))
+	}
+	return nil
+}
+
+func (s Seconds) Duration() time.Duration {
+	return time.Duration(float64(s) * float64(time.Second))
+}
+
+type OutputFormat string
+
+func (o OutputFormat) Validate() error {
+	switch o {
+	case "", "text", "json", "ndjson":
+		return nil
+	default:
+		return fmt.Errorf(`must be "text", "json" or "ndjson": <%s>`, o)
+	}
+}
+
+// Batch mode format, text unless something else was asked for
+func (o OutputFormat) BatchFormat() ftop.BatchFormat {
+	if o == "" {
+		return ftop.BatchFormatText
+	}
+	return ftop.BatchFormat(o)
+}
@@ -1,9 +1,12 @@
 package main
 
 import (
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/walles/ftop/internal/assert"
+	"github.com/walles/ftop/internal/ftop"
 )
 
 func resetCLI() {
@@ -37,3 +40,189 @@ func TestParseCommandLine_WithoutInitialFilter(t *testing.T) {
 	assert.Equal(t, CLI.InitialFilter, "")
 	assert.Equal(t, CLI.Theme.String(), "auto")
 }
+
+func TestParseCommandLine_Batch(t *testing.T) {
+	resetCLI()
+	t.Cleanup(resetCLI)
+
+	argsParser, err := newArgsParser()
+	assert.Equal(t, err, nil)
+
+	_, err = argsParser.Parse([]string{"--batch", "--iterations", "3", "--interval", "0.5", "firefox"})
+	assert.Equal(t, err, nil)
+
+	assert.Equal(t, CLI.Batch, true)
+	assert.Equal(t, CLI.Iterations, 3)
+	assert.Equal(t, CLI.Interval.Duration(), 500*time.Millisecond)
+	assert.Equal(t, CLI.InitialFilter, "firefox")
+
+	_, err = argsParser.Parse([]string{"--batch", "--interval", "0"})
+	assert.Equal(t, err != nil, true)
+}
+
+func TestParseCommandLine_Output(t *testing.T) {
+	resetCLI()
+	t.Cleanup(resetCLI)
+
+	argsParser, err := newArgsParser()
+	assert.Equal(t, err, nil)
+
+	_, err = argsParser.Parse([]string{})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, CLI.Output.BatchFormat(), ftop.BatchFormatText)
+
+	_, err = argsParser.Parse([]string{"--output", "ndjson"})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, CLI.Output.BatchFormat(), ftop.BatchFormatNdjson)
+
+	_, err = argsParser.Parse([]string{"--output", "xml"})
+	assert.Equal(t, err != nil, true)
+}
+
+func TestParseCommandLine_Replay(t *testing.T) {
+	resetCLI()
+	t.Cleanup(resetCLI)
+
+	parse := func(args ...string) error {
+		argsParser, err := newArgsParser()
+		assert.Equal(t, err, nil)
+
+		_, err = argsParser.Parse(args)
+		return err
+	}
+
+	// Any existing file will do for testing the parsing
+	assert.Equal(t, parse("--replay", "commandline.go"), nil)
+	assert.Equal(t, filepath.Base(CLI.Replay), "commandline.go")
+
+	assert.Equal(t, parse("--replay", "commandline.go", "--record", "x.ftop") != nil, true)
+	assert.Equal(t, parse("--replay", "commandline.go", "--batch") != nil, true)
+	assert.Equal(t, parse("--replay", "does-not-exist.ftop") != nil, true)
+}
+
+func TestParseCommandLine_Rewind(t *testing.T) {
+	resetCLI()
+	t.Cleanup(resetCLI)
+
+	parse := func(args ...string) error {
+		argsParser, err := newArgsParser()
+		assert.Equal(t, err, nil)
+
+		_, err = argsParser.Parse(args)
+		return err
+	}
+
+	assert.Equal(t, parse(), nil)
+	assert.Equal(t, CLI.Rewind, 10*time.Minute)
+
+	assert.Equal(t, parse("--rewind", "2m"), nil)
+	assert.Equal(t, CLI.Rewind, 2*time.Minute)
+
+	assert.Equal(t, parse("--rewind", "-1m") != nil, true)
+}
+
+func TestParseCommandLine_Serve(t *testing.T) {
+	resetCLI()
+	t.Cleanup(resetCLI)
+
+	parse := func(args ...string) error {
+		argsParser, err := newArgsParser()
+		assert.Equal(t, err, nil)
+
+		_, err = argsParser.Parse(args)
+		return err
+	}
+
+	assert.Equal(t, parse("--serve-metrics", ":9642"), nil)
+	assert.Equal(t, CLI.ServeMetrics, ":9642")
+
+	assert.Equal(t, parse("--serve-metrics", ":9642", "--output", "json") != nil, true)
+	assert.Equal(t, parse("--serve-metrics", ":9642", "--replay", "commandline.go") != nil, true)
+
+	assert.Equal(t, parse("--serve-http", ":8080"), nil)
+	assert.Equal(t, CLI.ServeHttp, ":8080")
+	assert.Equal(t, parse("--serve-http", ":8080", "--serve-metrics", ":9642") != nil, true)
+	assert.Equal(t, parse("--serve-http", ":8080", "--batch") != nil, true)
+}
+
+func TestParseCommandLine_Remote(t *testing.T) {
+	resetCLI()
+	t.Cleanup(resetCLI)
+
+	parse := func(args ...string) error {
+		argsParser, err := newArgsParser()
+		assert.Equal(t, err, nil)
+
+		_, err = argsParser.Parse(args)
+		return err
+	}
+
+	assert.Equal(t, parse("--agent", "--listen", ":9643"), nil)
+	assert.Equal(t, parse("--agent", "--allow-kill"), nil)
+	assert.Equal(t, parse("--agent", "--listen", ":9643", "--allow-kill") != nil, true)
+	assert.Equal(t, parse("--listen", ":9643") != nil, true)
+	assert.Equal(t, parse("--allow-kill") != nil, true)
+	assert.Equal(t, parse("--agent", "--batch") != nil, true)
+
+	assert.Equal(t, parse("--connect", "server.example.com:9643"), nil)
+	assert.Equal(t, CLI.connectsOverTcp(), true)
+	assert.Equal(t, parse("--connect", "[::1]:9643"), nil)
+	assert.Equal(t, CLI.connectsOverTcp(), true)
+	assert.Equal(t, parse("--connect", "server.example.com:9643", "--allow-kill") != nil, true)
+
+	assert.Equal(t, parse("--connect", "johan@server.example.com", "--allow-kill"), nil)
+	assert.Equal(t, CLI.connectsOverTcp(), false)
+	assert.SlicesEqual(t, CLI.sshAgentCommand(), []string{"ssh", "--", "johan@server.example.com", "ftop", "--agent", "--allow-kill"})
+
+	// Watching a remote machine in batch mode is fine
+	assert.Equal(t, parse("--connect", "server", "--batch"), nil)
+	assert.Equal(t, parse("--connect", "server", "--agent") != nil, true)
+	assert.Equal(t, parse("--connect", "server", "--replay", "commandline.go") != nil, true)
+}
+
+func TestParseCommandLine_Hosts(t *testing.T) {
+	resetCLI()
+	t.Cleanup(resetCLI)
+
+	parse := func(args ...string) error {
+		argsParser, err := newArgsParser()
+		assert.Equal(t, err, nil)
+
+		_, err = argsParser.Parse(args)
+		return err
+	}
+
+	assert.Equal(t, parse("--hosts", "commandline.go"), nil)
+	assert.Equal(t, parse("--hosts", "commandline.go", "--allow-kill"), nil)
+	assert.Equal(t, parse("--hosts", "does-not-exist") != nil, true)
+	assert.Equal(t, parse("--hosts", "commandline.go", "--connect", "server") != nil, true)
+	assert.Equal(t, parse("--hosts", "commandline.go", "--batch") != nil, true)
+	assert.Equal(t, parse("--hosts", "commandline.go", "--replay", "commandline.go") != nil, true)
+	assert.Equal(t, parse("--hosts", "commandline.go", "--record", "/tmp/x.ftop") != nil, true)
+}
+
+func TestParseCommandLine_Sort(t *testing.T) {
+	resetCLI()
+	t.Cleanup(resetCLI)
+
+	parse := func(args ...string) error {
+		argsParser, err := newArgsParser()
+		assert.Equal(t, err, nil)
+
+		_, err = argsParser.Parse(args)
+		return err
+	}
+
+	assert.Equal(t, parse(), nil)
+	assert.Equal(t, CLI.Sort.SortMode(), ftop.SortModeScore)
+
+	assert.Equal(t, parse("--sort", "ram"), nil)
+	assert.Equal(t, CLI.Sort.SortMode(), ftop.SortModeRam)
+
+	assert.Equal(t, parse("--sort", "size") != nil, true)
+
+	assert.Equal(t, parse("--sort", "ram", "--batch"), nil)
+	assert.Equal(t, CLI.Sort.SortMode(), ftop.SortModeRam)
+
+	assert.Equal(t, parse("--sort", "ram", "--serve-http", ":8080") != nil, true)
+}
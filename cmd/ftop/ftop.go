@@ -12,6 +12,7 @@ import (
 
 	"github.com/walles/ftop/internal/ftop"
 	"github.com/walles/ftop/internal/log"
+	"github.com/walles/ftop/internal/snapshots"
 	"github.com/walles/ftop/internal/themes"
 	"github.com/walles/moor/v2/twin"
 )
@@ -84,6 +85,35 @@ func main() {
 		os.Exit(0)
 	}
 
+	if CLI.Batch || CLI.Output != "" {
+		os.Exit(batchMainLoop())
+	}
+
+	if CLI.ServeMetrics != "" {
+		os.Exit(serverMainLoop("metrics", ftop.ServeMetrics, CLI.ServeMetrics))
+	}
+
+	if CLI.ServeHttp != "" {
+		columnIds, _, err := readColumnsConfig()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+
+		serveHttp := func(source snapshots.Source, address string, filter string) error {
+			return ftop.ServeHttp(source, address, filter, columnIds)
+		}
+		os.Exit(serverMainLoop("web dashboard", serveHttp, CLI.ServeHttp))
+	}
+
+	if CLI.Agent {
+		os.Exit(agentMainLoop())
+	}
+
+	if CLI.Hosts != "" {
+		os.Exit(multiHostMainLoop())
+	}
+
 	if CLI.Profile {
 		if detectrace.WithRace() {
 			fmt.Fprintln(os.Stderr, "ERROR: Profiling is not supported when built with --race")
@@ -156,6 +186,23 @@ func profilingMainLoop(pleasePanic bool) int {
 }
 
 func mainLoop(pleasePanic bool) int {
+	columnIds, columnsConfigPath, err := readColumnsConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+
+	source, closeSource, err := newSource()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+	defer closeSource()
+	if CLI.Replay == "" {
+		// Replays have their own stepping back and forth
+		source = snapshots.NewRewinder(source, CLI.Rewind)
+	}
+
 	screen, err := twin.NewScreen()
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "Error creating screen:", err)
@@ -178,11 +225,222 @@ func mainLoop(pleasePanic bool) int {
 	theme := themes.NewTheme(CLI.Theme.String(), screen.TerminalBackground())
 
 	ui := ftop.NewUi(screen, theme, CLI.InitialFilter)
-	ui.MainLoop()
+	ui.SetSortMode(CLI.Sort.SortMode())
+	ui.SetColumns(columnIds, columnsConfigPath)
+	ui.MainLoop(source)
+
+	return 0
+}
+
+// Like mainLoop(), but showing all machines in the --hosts file
+func multiHostMainLoop() int {
+	hosts, err := ftop.ReadHostsFile(CLI.Hosts, CLI.AllowKill)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+
+	columnIds, columnsConfigPath, err := readColumnsConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+
+	screen, err := twin.NewScreen()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error creating screen:", err)
+		return 1
+	}
+
+	defer onExit(screen, CLI.Debug)
+	log.SetPanicShutdownHook(func() {
+		onExit(screen, true)
+	})
+
+	defer func() {
+		log.PanicHandler("main", recover(), debug.Stack())
+	}()
+
+	theme := themes.NewTheme(CLI.Theme.String(), screen.TerminalBackground())
+
+	ui := ftop.NewUi(screen, theme, CLI.InitialFilter)
+	ui.SetSortMode(CLI.Sort.SortMode())
+	ui.SetColumns(columnIds, columnsConfigPath)
+	ui.MultiHostMainLoop(hosts, CLI.Rewind)
 
 	return 0
 }
 
+// The per-process columns the user wants, and where to save changes to them.
+// Nil IDs means the defaults.
+func readColumnsConfig() (ids []string, path string, err error) {
+	path, err = ftop.ColumnsConfigPath()
+	if err != nil {
+		// No config directory, go with the defaults and don't save anything
+		log.Infof("Not reading any columns config: %v", err)
+		return nil, "", nil
+	}
+
+	ids, err = ftop.ReadColumnsConfig(path)
+	return ids, path, err
+}
+
+// Live snapshots, unless we were asked to replay a recording or to connect to
+// an agent. Call the returned close function before exiting, it finishes any
+// recording we are making.
+func newSource() (source snapshots.Source, closeSource func(), err error) {
+	if CLI.Replay != "" {
+		replay, err := snapshots.NewReplay(CLI.Replay)
+		if err != nil {
+			return nil, nil, err
+		}
+		return replay, replay.Close, nil
+	}
+
+	closeSource = func() {}
+	if CLI.Connect == "" {
+		source = snapshots.NewLiveSource()
+	} else {
+		var remote *snapshots.Remote
+		if CLI.connectsOverTcp() {
+			remote, err = snapshots.DialAgent(CLI.Connect)
+		} else {
+			remote, err = snapshots.StartAgent(CLI.sshAgentCommand())
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		source = remote
+		closeSource = remote.Close
+	}
+
+	if CLI.Record == "" {
+		return source, closeSource, nil
+	}
+
+	recorder, err := snapshots.NewRecorder(source, CLI.Record)
+	if err != nil {
+		closeSource()
+		return nil, nil, err
+	}
+	return recorder, func() {
+		recorder.Close()
+		closeSource()
+	}, nil
+}
+
+func batchMainLoop() int {
+	log.SetPanicShutdownHook(func() {
+		// No screen to restore, just tell the user what happened
+		fmt.Fprintln(os.Stderr, log.String(true))
+		os.Exit(1)
+	})
+
+	defer func() {
+		log.PanicHandler("batch", recover(), debug.Stack())
+	}()
+
+	// No terminal to ask for its background color, so no auto detection
+	theme := themes.NewTheme(CLI.Theme.String(), nil)
+
+	columnIds, _, err := readColumnsConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+
+	source, closeSource, err := newSource()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+	defer closeSource()
+
+	err = ftop.RunBatch(source, theme, CLI.InitialFilter, columnIds, CLI.Sort.SortMode(), CLI.Iterations, CLI.Interval.Duration(), CLI.Output.BatchFormat())
+	if err != nil {
+		log.Infof("Batch mode failed: %v", err)
+		return 1
+	}
+
+	if CLI.Debug || log.HasErrors() {
+		fmt.Fprintln(os.Stderr, log.String(true))
+	}
+	if log.HasErrors() {
+		return 1
+	}
+
+	return 0
+}
+
+// Stream snapshots over stdin / stdout until the client goes away, or to
+// whoever connects to the --listen address until that fails
+func agentMainLoop() int {
+	log.SetPanicShutdownHook(func() {
+		// No screen to restore, just tell the user what happened
+		fmt.Fprintln(os.Stderr, log.String(true))
+		os.Exit(1)
+	})
+
+	defer func() {
+		log.PanicHandler("agent", recover(), debug.Stack())
+	}()
+
+	source, closeSource, err := newSource()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+	defer closeSource()
+
+	agent := snapshots.NewAgent(source, CLI.AllowKill)
+	if CLI.Listen != "" {
+		err = agent.Listen(CLI.Listen)
+	} else {
+		err = agent.Serve(os.Stdin, os.Stdout)
+	}
+
+	if CLI.Debug || log.HasErrors() {
+		fmt.Fprintln(os.Stderr, log.String(true))
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+	if log.HasErrors() {
+		return 1
+	}
+
+	return 0
+}
+
+// Serve something over HTTP until that fails. Serve is expected to block.
+func serverMainLoop(what string, serve func(source snapshots.Source, address string, filter string) error, address string) int {
+	log.SetPanicShutdownHook(func() {
+		// No screen to restore, just tell the user what happened
+		fmt.Fprintln(os.Stderr, log.String(true))
+		os.Exit(1)
+	})
+
+	defer func() {
+		log.PanicHandler(what, recover(), debug.Stack())
+	}()
+
+	source, closeSource, err := newSource()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+	defer closeSource()
+
+	err = serve(source, address, CLI.InitialFilter)
+	fmt.Fprintf(os.Stderr, "Error serving %s: %v\n", what, err)
+	if CLI.Debug || log.HasErrors() {
+		fmt.Fprintln(os.Stderr, log.String(true))
+	}
+
+	return 1
+}
+
 func onExit(screen twin.Screen, forcePrintLogs bool) {
 	screen.Close()
 
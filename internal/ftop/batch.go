@@ -0,0 +1,265 @@
+package ftop
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/walles/ftop/internal/processes"
+	"github.com/walles/ftop/internal/snapshots"
+	"github.com/walles/ftop/internal/themes"
+	"github.com/walles/ftop/internal/util"
+	"github.com/walles/moor/v2/twin"
+)
+
+// Width of the virtual screen we render the overview and the launched commands
+// on in batch mode. Wide enough that nothing gets clipped in practice.
+const batchScreenWidth = 200
+
+type BatchFormat string
+
+const (
+	// Human readable, like the interactive UI
+	BatchFormatText BatchFormat = "text"
+
+	// One indented JSON document per snapshot, see jsonSample
+	BatchFormatJson BatchFormat = "json"
+
+	// One JSON document per line, see jsonSample
+	BatchFormatNdjson BatchFormat = "ndjson"
+)
+
+// Print snapshots to stdout until we have printed iterations of them. If
+// iterations is 0, keep going until killed. columnIds are the per-process
+// columns to show in the text format, nil means the defaults.
+//
+// Returns an error if writing to stdout fails, which it will for example when
+// piping into "head" and head exits.
+func RunBatch(source snapshots.Source, theme themes.Theme, filter string, columnIds []string, sortMode SortMode, iterations int, interval time.Duration, format BatchFormat) error {
+	<-source.OnUpdate() // Wait for the first process list
+
+	for i := 0; iterations == 0 || i < iterations; i++ {
+		// CPU usage is counted from when we started, so give the trackers some
+		// time to collect numbers before the first snapshot as well
+		time.Sleep(interval)
+
+		snapshot := source.Snapshot()
+		snapshot.Processes = processes.Filter(snapshot.Processes, filter)
+		formatted, err := formatSnapshot(theme, filter, columnIds, sortMode, format, snapshot)
+		if err != nil {
+			return err
+		}
+		if i > 0 && format == BatchFormatText {
+			formatted = "\n" + formatted
+		}
+
+		_, err = fmt.Fprint(os.Stdout, formatted)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// The snapshot's processes should already be filtered
+func formatSnapshot(theme themes.Theme, filter string, columnIds []string, sortMode SortMode, format BatchFormat, snapshot snapshots.Snapshot) (string, error) {
+	if format == BatchFormatText {
+		return batchSnapshot(theme, filter, columnIds, sortMode, snapshot), nil
+	}
+
+	sample := jsonSnapshot(snapshot, sortMode)
+
+	var bytes []byte
+	var err error
+	if format == BatchFormatNdjson {
+		bytes, err = json.Marshal(sample)
+	} else {
+		bytes, err = json.MarshalIndent(sample, "", "  ")
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return string(bytes) + "\n", nil
+}
+
+// Render one snapshot using the same sorting and aggregation as the
+// interactive UI
+func batchSnapshot(theme themes.Theme, filter string, columnIds []string, sortMode SortMode, snapshot snapshots.Snapshot) string {
+	procs := snapshot.Processes
+
+	var sb strings.Builder
+
+	sb.WriteString("=== " + snapshot.Timestamp.Format(DISPLAY_TIME_FORMAT) + " ===\n")
+	for _, line := range overviewLines(theme, snapshot) {
+		sb.WriteString(line + "\n")
+	}
+
+	u := newTablesUi(theme, filter, columnIds, sortMode)
+
+	sb.WriteString("\n")
+	sb.WriteString(formatTextTable(u.processesTextTable(procs)))
+
+	sb.WriteString("\n")
+	sb.WriteString(formatTextTable(u.usersTextTable(procs)))
+
+	sb.WriteString("\n")
+	sb.WriteString(formatTextTable(u.commandsTextTable(procs)))
+
+	launchedLines := launchedCommandsLines(theme, snapshot.Launches)
+	if len(launchedLines) > 0 {
+		sb.WriteString("\nLaunched Commands\n")
+		for _, line := range launchedLines {
+			sb.WriteString(line + "\n")
+		}
+	}
+
+	return sb.String()
+}
+
+// The sysload, memory usage and IO load lines from the top of the UI
+func overviewLines(theme themes.Theme, snapshot snapshots.Snapshot) []string {
+	overview := twin.NewFakeScreen(batchScreenWidth, 5)
+	renderSysload(overview, theme, snapshot.Sysload, batchScreenWidth)
+	renderMemoryUsage(overview, theme, snapshot.Sysload, batchScreenWidth)
+	renderIOLoad(overview, theme, snapshot.IoStats, batchScreenWidth)
+
+	var lines []string
+	for y := 1; y <= 3; y++ {
+		lines = append(lines, screenRowToString(overview, y, 1, batchScreenWidth-1))
+	}
+	return lines
+}
+
+// The launched commands tree, without its frame. Empty if nothing has been
+// launched.
+func launchedCommandsLines(theme themes.Theme, launches *processes.LaunchNode) []string {
+	launchedHeight := getLaunchedCommandsHeight(launches)
+	if launchedHeight == 0 {
+		return nil
+	}
+
+	// +2 for the frame, which we won't include
+	launched := twin.NewFakeScreen(batchScreenWidth, launchedHeight+2)
+	renderLaunchedCommands(launched, theme, launches, 0, launchedHeight+1)
+
+	var lines []string
+	for y := 1; y <= launchedHeight; y++ {
+		lines = append(lines, screenRowToString(launched, y, 1, batchScreenWidth-2))
+	}
+	return lines
+}
+
+// A table with a header row, and with cells formatted like in the interactive
+// UI
+type textTable struct {
+	rows        [][]string
+	leftAligned []bool
+}
+
+// Processes sorted like in the interactive UI
+func (u *Ui) processesTextTable(procs []processes.Process) textTable {
+	columns := u.processColumns()
+
+	rows := [][]string{u.processesHeaders(columns)}
+	for _, p := range u.sortProcesses(procs) {
+		rows = append(rows, u.processRow(columns, p))
+	}
+
+	// Align columns just like the UI does
+	leftAligned := make([]bool, 0, len(columns))
+	for _, column := range columns {
+		leftAligned = append(leftAligned, column.leftAligned)
+	}
+	return textTable{rows: rows, leftAligned: leftAligned}
+}
+
+func (u *Ui) usersTextTable(procs []processes.Process) textTable {
+	rows := [][]string{{"User", "CPU", "RAM"}}
+	for _, user := range u.aggregateUsers(procs) {
+		rows = append(rows, statsRow(user.stats))
+	}
+	return textTable{rows: rows, leftAligned: []bool{true, false, false}}
+}
+
+func (u *Ui) commandsTextTable(procs []processes.Process) textTable {
+	rows := [][]string{{"Command", "CPU", "RAM"}}
+	for _, command := range aggregateCommands(procs, u.sortMode, false) {
+		rows = append(rows, statsRow(command.stats))
+	}
+	return textTable{rows: rows, leftAligned: []bool{true, false, false}}
+}
+
+func statsRow(stat stats) []string {
+	return []string{
+		stat.name,
+		util.FormatDuration(stat.cpuTime),
+		statsRamString(stat),
+	}
+}
+
+// Pad all columns to the same width, separated by single spaces. Columns are
+// right aligned unless leftAligned says otherwise.
+func formatTextTable(table textTable) string {
+	widths := make([]int, len(table.leftAligned))
+	for _, row := range table.rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+
+	var sb strings.Builder
+	for _, row := range table.rows {
+		var line strings.Builder
+		for i, cell := range row {
+			if i > 0 {
+				line.WriteString(" ")
+			}
+
+			padding := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+			if table.leftAligned[i] {
+				line.WriteString(cell + padding)
+			} else {
+				line.WriteString(padding + cell)
+			}
+		}
+
+		sb.WriteString(strings.TrimRight(line.String(), " ") + "\n")
+	}
+
+	return sb.String()
+}
+
+// Text of one screen row between two columns (inclusive), with trailing
+// whitespace removed
+func screenRowToString(screen twin.Screen, y int, x0 int, x1 int) string {
+	var sb strings.Builder
+	for x := x0; x <= x1; x++ {
+		cell := screen.GetCell(x, y)
+		if cell.Rune == 0 {
+			// Never written to
+			sb.WriteRune(' ')
+			continue
+		}
+
+		sb.WriteRune(cell.Rune)
+		if cell.Width() > 1 {
+			// Skip the cells covered by this wide character
+			x += cell.Width() - 1
+		}
+	}
+
+	return strings.TrimRight(sb.String(), " ")
+}
+
+// For access to the same table building code as the interactive UI uses
+func newTablesUi(theme themes.Theme, filter string, columnIds []string, sortMode SortMode) *Ui {
+	u := NewUi(twin.NewFakeScreen(batchScreenWidth, 1), theme, filter)
+	u.SetColumns(columnIds, "")
+	u.SetSortMode(sortMode)
+	return u
+}
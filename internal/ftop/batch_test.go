@@ -0,0 +1,89 @@
+package ftop
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/walles/ftop/internal/assert"
+	"github.com/walles/ftop/internal/processes"
+	"github.com/walles/ftop/internal/snapshots"
+	"github.com/walles/ftop/internal/sysload"
+	"github.com/walles/ftop/internal/themes"
+)
+
+func TestFormatTextTable(t *testing.T) {
+	assert.Equal(t, formatTextTable(textTable{
+		rows: [][]string{
+			{"PID", "Command", "RAM"},
+			{"1", "init", "10M"},
+			{"1234", "bäsh", "5k"},
+		},
+		leftAligned: []bool{false, true, false},
+	}),
+		" PID Command RAM\n"+
+			"   1 init    10M\n"+
+			"1234 bäsh     5k\n")
+}
+
+func TestBatchSnapshot(t *testing.T) {
+	procs := []processes.Process{
+		{Pid: 2, Cmdline: "small", Username: "www", RssKb: 20, CpuTime: toDuration(20)},
+		{Pid: 1, Cmdline: "big", Username: "root", RssKb: 10, CpuTime: toDuration(60)},
+	}
+
+	snapshot := batchSnapshot(themes.NewTheme("dark", nil), "", nil, SortModeScore, snapshots.Snapshot{
+		Timestamp: time.Now(),
+		Processes: procs,
+		Sysload:   sysload.Sysload{RamUsedBytes: 1024, RamTotalBytes: 4096, CpuCoresLogical: 2, CpuCoresPhysical: 1},
+	})
+	lines := strings.Split(snapshot, "\n")
+
+	// Timestamp, then three overview lines, then an empty line
+	assert.Equal(t, strings.HasPrefix(lines[1], "Sysload: "), true)
+	assert.Equal(t, lines[4], "")
+
+	// Sorted the same way as in the interactive UI
+	assert.Equal(t, strings.Fields(lines[5])[0], "PID")
+	assert.Equal(t, strings.Fields(lines[6])[1], "big")
+	assert.Equal(t, strings.Fields(lines[7])[1], "small")
+	assert.Equal(t, lines[8], "")
+
+	assert.SlicesEqual(t, strings.Fields(lines[9]), []string{"User", "CPU", "RAM"})
+	assert.SlicesEqual(t, strings.Fields(lines[10]), []string{"root", "1m00s", "10k"})
+}
+
+func TestBatchSnapshot_Columns(t *testing.T) {
+	procs := []processes.Process{
+		{Pid: 1, Cmdline: "big", Username: "root", RssKb: 10, CpuTime: toDuration(60)},
+	}
+
+	snapshot := batchSnapshot(themes.NewTheme("dark", nil), "", []string{"ram"}, SortModeScore, snapshots.Snapshot{
+		Timestamp: time.Now(),
+		Processes: procs,
+		Sysload:   sysload.Sysload{RamUsedBytes: 1024, RamTotalBytes: 4096, CpuCoresLogical: 2, CpuCoresPhysical: 1},
+	})
+	lines := strings.Split(snapshot, "\n")
+
+	assert.SlicesEqual(t, strings.Fields(lines[5]), []string{"Command", "RAM"})
+	assert.SlicesEqual(t, strings.Fields(lines[6]), []string{"big", "10k"})
+}
+
+func TestBatchSnapshot_SortMode(t *testing.T) {
+	procs := []processes.Process{
+		{Pid: 2, Cmdline: "small", Username: "www", RssKb: 20, CpuTime: toDuration(20)},
+		{Pid: 1, Cmdline: "big", Username: "root", RssKb: 10, CpuTime: toDuration(60)},
+	}
+
+	snapshot := batchSnapshot(themes.NewTheme("dark", nil), "", nil, SortModeRam, snapshots.Snapshot{
+		Timestamp: time.Now(),
+		Processes: procs,
+		Sysload:   sysload.Sysload{RamUsedBytes: 1024, RamTotalBytes: 4096, CpuCoresLogical: 2, CpuCoresPhysical: 1},
+	})
+	lines := strings.Split(snapshot, "\n")
+
+	// Processes, users and commands all sorted by RAM
+	assert.Equal(t, strings.Fields(lines[6])[1], "small")
+	assert.Equal(t, strings.Fields(lines[10])[0], "www")
+	assert.Equal(t, strings.Fields(lines[14])[0], "small")
+}
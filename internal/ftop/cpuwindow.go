@@ -0,0 +1,78 @@
+package ftop
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/walles/ftop/internal/processes"
+)
+
+// Which CPU time we show and sort by
+type cpuWindow int
+
+const (
+	// Since ftop started, or since the user last reset the baseline
+	cpuWindowSinceStart cpuWindow = iota
+	cpuWindow10s
+	cpuWindow60s
+)
+
+func (w cpuWindow) next() cpuWindow {
+	return (w + 1) % (cpuWindow60s + 1)
+}
+
+// Zero for cpuWindowSinceStart
+func (w cpuWindow) duration() time.Duration {
+	switch w {
+	case cpuWindow10s:
+		return 10 * time.Second
+	case cpuWindow60s:
+		return 60 * time.Second
+	}
+	return 0
+}
+
+// Make everything after this count CPU from now. Switches to the since-start
+// window, since that's where the baseline matters.
+func (u *Ui) resetCpuBaseline() {
+	snapshot := u.source.Snapshot()
+
+	u.cpuBaseline = make(map[int]*processes.Process, len(snapshot.Processes))
+	for _, p := range snapshot.Processes {
+		u.cpuBaseline[p.Pid] = &p
+	}
+	u.cpuBaselineTime = snapshot.Timestamp
+	u.cpuWindow = cpuWindowSinceStart
+}
+
+// Turn CpuTime into whatever the current CPU window says it should be
+func (u *Ui) applyCpuWindow(procs []processes.Process) []processes.Process {
+	if u.cpuWindow == cpuWindowSinceStart && u.cpuBaseline == nil {
+		// The tracker already did this for us
+		return procs
+	}
+
+	windowed := make([]processes.Process, 0, len(procs))
+	for _, p := range procs {
+		if u.cpuWindow == cpuWindowSinceStart {
+			windowed = append(windowed, p.WithCpuBaseline(u.cpuBaseline[p.Pid]))
+		} else {
+			windowed = append(windowed, p.WithCpuWindow(u.cpuWindow.duration(), u.now()))
+		}
+	}
+	return windowed
+}
+
+// Empty when counting CPU since ftop started. Example return values:
+//
+//	CPU last 10s
+//	CPU since 12:34:56
+func (u *Ui) cpuWindowDescription() string {
+	if u.cpuWindow != cpuWindowSinceStart {
+		return fmt.Sprintf("CPU last %ds", int(u.cpuWindow.duration().Seconds()))
+	}
+	if u.cpuBaseline != nil {
+		return "CPU since " + u.cpuBaselineTime.Format(time.TimeOnly)
+	}
+	return ""
+}
@@ -0,0 +1,50 @@
+package ftop
+
+import (
+	"testing"
+	"time"
+
+	"github.com/walles/ftop/internal/assert"
+	"github.com/walles/ftop/internal/processes"
+	"github.com/walles/ftop/internal/snapshots"
+)
+
+type fakeSource struct {
+	snapshot snapshots.Snapshot
+}
+
+func (s *fakeSource) OnUpdate() <-chan struct{} {
+	return nil
+}
+
+func (s *fakeSource) Snapshot() snapshots.Snapshot {
+	return s.snapshot
+}
+
+func TestCpuWindowCycle(t *testing.T) {
+	assert.Equal(t, cpuWindowSinceStart.next(), cpuWindow10s)
+	assert.Equal(t, cpuWindow10s.next(), cpuWindow60s)
+	assert.Equal(t, cpuWindow60s.next(), cpuWindowSinceStart)
+}
+
+func TestResetCpuBaseline(t *testing.T) {
+	timestamp := time.Date(2026, 10, 17, 12, 34, 56, 0, time.Local)
+	source := &fakeSource{snapshot: snapshots.Snapshot{
+		Timestamp: timestamp,
+		Processes: []processes.Process{{Pid: 1, Cmdline: "busy", CpuTime: toDuration(5), CpuTimeTotal: toDuration(100)}},
+	}}
+	u := Ui{source: source, cpuWindow: cpuWindow60s}
+	assert.Equal(t, u.cpuWindowDescription(), "CPU last 60s")
+
+	u.resetCpuBaseline()
+	assert.Equal(t, u.cpuWindow, cpuWindowSinceStart)
+	assert.Equal(t, u.cpuWindowDescription(), "CPU since 12:34:56")
+
+	later := []processes.Process{
+		{Pid: 1, Cmdline: "busy", CpuTime: toDuration(8), CpuTimeTotal: toDuration(103)},
+		{Pid: 2, Cmdline: "new", CpuTime: toDuration(1), CpuTimeTotal: toDuration(1)},
+	}
+	windowed := u.applyCpuWindow(later)
+	assert.Equal(t, *windowed[0].CpuTime, 3*time.Second)
+	assert.Equal(t, *windowed[1].CpuTime, 1*time.Second)
+}
@@ -14,22 +14,60 @@ func (h *eventHandlerBase) onRune(r rune) {
 		return
 	}
 
+	if h.ui.onReplayRune(r) {
+		return
+	}
+
 	if r == '/' || r == 'f' {
 		// Switch to the filter event handler
 		h.ui.eventHandler = &eventHandlerFilter{ui: h.ui}
 	}
 
-	if r == 'k' && h.ui.pickedProcess != nil {
-		h.ui.eventHandler = &eventHandlerKill{ui: h.ui, process: h.ui.pickedProcess}
+	// The processes in a recording may not even exist any more, don't kill
+	// whatever has taken over their PIDs
+	if r == 'k' && h.ui.pickedProcess != nil && h.ui.replay() == nil {
+		h.ui.eventHandler = newEventHandlerKill(h.ui, h.ui.pickedProcess)
 	}
 
 	proc := h.ui.pickedProcess
 	if r == 'i' && proc != nil {
 		h.ui.pageProcessInfo(proc)
 	}
+
+	if r == 'g' && h.ui.cgroupsAvailable {
+		h.ui.groupByCgroup = !h.ui.groupByCgroup
+	}
+
+	if r == 'm' && h.ui.pssAvailable {
+		h.ui.showPss = !h.ui.showPss
+	}
+
+	if r == 's' {
+		h.ui.sortMode = h.ui.sortMode.next()
+	}
+
+	if r == 'c' {
+		h.ui.cpuWindow = h.ui.cpuWindow.next()
+	}
+
+	if r == 'r' {
+		h.ui.resetCpuBaseline()
+	}
+
+	if r == 'o' {
+		h.ui.eventHandler = newEventHandlerColumns(h.ui)
+	}
+
+	if r == 'z' && len(h.ui.stuckProcesses) > 0 {
+		h.ui.pageStuckProcesses(h.ui.stuckProcesses)
+	}
 }
 
 func (h *eventHandlerBase) onKeyCode(keyCode twin.KeyCode) {
+	if h.ui.onReplayKeyCode(keyCode) || h.ui.onRewindKeyCode(keyCode) {
+		return
+	}
+
 	if keyCode == twin.KeyEscape {
 		if h.ui.pickedLine != nil {
 			// Clear the pick
@@ -44,6 +82,15 @@ func (h *eventHandlerBase) onKeyCode(keyCode twin.KeyCode) {
 			return
 		}
 
+		if rewinder := h.ui.rewinder(); rewinder != nil && rewinder.IsRewound() {
+			rewinder.Live()
+			return
+		}
+
+		if h.ui.leaveHost() {
+			return
+		}
+
 		h.ui.done = true
 		return
 	}
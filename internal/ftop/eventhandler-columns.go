@@ -0,0 +1,84 @@
+package ftop
+
+import (
+	"slices"
+
+	"github.com/walles/ftop/internal/log"
+	"github.com/walles/moor/v2/twin"
+)
+
+// Lets the user pick which per-process columns to show
+type eventHandlerColumns struct {
+	ui *Ui
+
+	// Index into processColumns
+	picked int
+
+	// IDs of the columns shown when the picker was opened
+	shownBefore []string
+}
+
+func newEventHandlerColumns(ui *Ui) *eventHandlerColumns {
+	return &eventHandlerColumns{ui: ui, shownBefore: ui.shownColumnIds()}
+}
+
+func (h *eventHandlerColumns) onRune(r rune) {
+	if r == ' ' {
+		h.toggle()
+		return
+	}
+
+	if r == 'q' || r == 'o' {
+		h.close()
+	}
+}
+
+func (h *eventHandlerColumns) onKeyCode(keyCode twin.KeyCode) {
+	switch keyCode {
+	case twin.KeyEscape:
+		h.close()
+	case twin.KeyEnter:
+		h.toggle()
+	case twin.KeyUp:
+		h.picked = max(h.picked-1, 0)
+	case twin.KeyDown:
+		h.picked = min(h.picked+1, len(processColumns)-1)
+	}
+}
+
+// Show the picked column if it's hidden, hide it if it's shown. The command
+// column is always shown.
+func (h *eventHandlerColumns) toggle() {
+	toggled := processColumns[h.picked].id
+	if toggled == columnIdCommand {
+		return
+	}
+
+	ids := []string{}
+	for _, column := range processColumns {
+		shown := h.isShown(column.id)
+		if column.id == toggled {
+			shown = !shown
+		}
+		if shown {
+			ids = append(ids, column.id)
+		}
+	}
+	h.ui.columnIds = ids
+}
+
+func (h *eventHandlerColumns) isShown(id string) bool {
+	return columnIndex(h.ui.processColumns(), id) >= 0
+}
+
+func (h *eventHandlerColumns) close() {
+	if !slices.Equal(h.ui.shownColumnIds(), h.shownBefore) {
+		err := h.ui.saveColumnsConfig()
+		if err != nil {
+			log.Infof("Saving columns config failed: %v", err)
+		}
+	}
+
+	// Switch back to the default event handler
+	h.ui.eventHandler = &eventHandlerBase{ui: h.ui}
+}
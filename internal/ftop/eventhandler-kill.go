@@ -1,8 +1,13 @@
 package ftop
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"os"
+	"os/exec"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"syscall"
@@ -13,6 +18,9 @@ import (
 	"github.com/walles/moor/v2/twin"
 )
 
+// Press this after failing to kill a process to retry with sudo
+const sudoRetryKey = 's'
+
 // This controls the framerate of the waiting-for-process-to-die progress bar
 // in renderkillui.go.
 const deathPollFramerate = 20
@@ -27,6 +35,30 @@ type eventHandlerKill struct {
 	// If true, we will stop waiting for any outstanding kill attempt
 	closing atomic.Bool
 
+	// Set when a signal failed because we aren't allowed to send it, which
+	// means retrying with sudo could help
+	permissionDenied atomic.Bool
+
+	// If true, signals are sent through "sudo kill". Set when the user has
+	// asked us to retry with sudo, and can't be unset after that.
+	useSudo atomic.Bool
+
+	// Non-nil while the user is typing a signal name
+	signalName *string
+
+	// The signal the user asked for, nil until they have picked one. Only
+	// accessed from the UI goroutine.
+	picked *killSignal
+
+	// What to signal. Can't change after we start signalling, so these are
+	// safe to read from the death-awaiting goroutine.
+	scope   killScope
+	targets []*processes.Process
+	pgid    int // Only set for killScopeGroup
+
+	handlesLock sync.Mutex // Protects handles
+	handles     []targetHandle
+
 	lock   sync.RWMutex // Protects excuse and lastSignal
 	excuse string
 
@@ -34,6 +66,60 @@ type eventHandlerKill struct {
 	lastSignalTimestamp *time.Time
 }
 
+// One per kill target, opened right before we send the first signal. With a
+// handle, we can't end up signalling some other process if ours dies and its
+// PID gets reused while the user is looking at the kill UI.
+type targetHandle struct {
+	// nil if we couldn't open one, in which case we fall back to the PID
+	handle *processes.ProcessHandle
+
+	// True if the process was gone when we tried to open the handle, or if
+	// its handle has told us it exited
+	gone bool
+}
+
+func newEventHandlerKill(ui *Ui, process *processes.Process) *eventHandlerKill {
+	killer := &eventHandlerKill{ui: ui, process: process}
+	killer.setScope(killScopeProcess)
+	if remote := ui.remote(); remote != nil && !remote.SignalsAllowed() {
+		killer.setExcuse("not allowed, start the agent with --allow-kill to allow it")
+	}
+	return killer
+}
+
+func (killer *eventHandlerKill) setScope(scope killScope) {
+	ownPid := os.Getpid()
+	if killer.ui.remote() != nil {
+		if scope == killScopeGroup {
+			// We can't look up process groups on the remote machine
+			killer.setExcuse("process groups can't be signalled remotely")
+			return
+		}
+
+		// The agent refuses to signal itself, and we aren't on that machine
+		ownPid = 0
+	}
+
+	targets, err := killTargets(killer.process, scope, ownPid, syscall.Getpgid)
+	if err != nil {
+		killer.setExcuse(err.Error())
+		return
+	}
+
+	pgid := 0
+	if scope == killScopeGroup {
+		pgid, err = syscall.Getpgid(killer.process.Pid)
+		if err != nil {
+			killer.setExcuse(err.Error())
+			return
+		}
+	}
+
+	killer.scope = scope
+	killer.targets = targets
+	killer.pgid = pgid
+}
+
 func (killer *eventHandlerKill) getExcuse() string {
 	killer.lock.RLock()
 	defer killer.lock.RUnlock()
@@ -80,26 +166,303 @@ func (killer *eventHandlerKill) setLastSignal(signal syscall.Signal) {
 
 // Returns an explanation if the kill failed, or the empty string if it succeeded
 func (killer *eventHandlerKill) kill(signal syscall.Signal) string {
-	// Kill the process
+	if killer.useSudo.Load() {
+		// sudo should have cached our credentials by now, so we won't need
+		// to prompt for a password again
+		return killer.sudoKill(signal, false)
+	}
 
-	p, err := os.FindProcess(killer.process.Pid)
-	if err != nil {
-		return fmt.Sprintf("Not found for killing: %v", err)
+	if remote := killer.ui.remote(); remote != nil {
+		err := remote.Signal(killer.targets, signalName(signal))
+		if err != nil {
+			return err.Error()
+		}
+
+		killer.setLastSignal(signal)
+		log.Debugf("Sent signal %d to %s on %s", signal, killer.describeTargets(), remote.Hostname())
+		return ""
+	}
+
+	if killer.scope == killScopeGroup {
+		err := syscall.Kill(-killer.pgid, signal)
+		if errors.Is(err, syscall.EPERM) {
+			killer.permissionDenied.Store(true)
+		}
+		if err != nil {
+			return err.Error()
+		}
+
+		killer.setLastSignal(signal)
+		log.Debugf("Sent signal %d to process group %d", signal, killer.pgid)
+		return ""
+	}
+
+	killer.handlesLock.Lock()
+	defer killer.handlesLock.Unlock()
+
+	// Kill the processes, parents first
+	var firstErr error
+	failures := 0
+	for i, target := range killer.targets {
+		var err error
+		if killer.handles[i].gone {
+			continue
+		} else if handle := killer.handles[i].handle; handle != nil {
+			err = handle.Signal(signal)
+		} else {
+			var p *os.Process
+			p, err = os.FindProcess(target.Pid)
+			if err == nil {
+				err = p.Signal(signal)
+			}
+		}
+		if errors.Is(err, os.ErrProcessDone) || errors.Is(err, syscall.ESRCH) {
+			// Already gone, that's what we wanted
+			continue
+		}
+		if errors.Is(err, syscall.EPERM) {
+			killer.permissionDenied.Store(true)
+		}
+		if err != nil {
+			failures++
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		log.Debugf("Sent signal %d to process %s", signal, target.String())
+	}
+
+	if failures == len(killer.targets) && firstErr != nil {
+		return firstErr.Error()
 	}
 
-	err = p.Signal(signal)
+	// Remember what we just did
+	killer.setLastSignal(signal)
+
+	if firstErr != nil {
+		return fmt.Sprintf("%v (%d of %d processes)", firstErr, failures, len(killer.targets))
+	}
+
+	return ""
+}
+
+// Send a signal using "sudo kill". If interactive is true, the screen is
+// suspended so that sudo can prompt for a password. Otherwise, sudo fails
+// rather than prompting.
+//
+// Returns an explanation if the kill failed, or the empty string if it
+// succeeded.
+func (killer *eventHandlerKill) sudoKill(signal syscall.Signal, interactive bool) string {
+	// sudo kill can only do PIDs, but at least we can skip the ones we know
+	// are gone, since their PIDs could belong to something else by now
+	killer.handlesLock.Lock()
+	pids := make([]int, 0, len(killer.targets))
+	for i, target := range killer.targets {
+		if !killer.handles[i].gone {
+			pids = append(pids, target.Pid)
+		}
+	}
+	killer.handlesLock.Unlock()
+	args := sudoKillArgs(signal, pids, killer.scope, killer.pgid, interactive)
+
+	var err error
+	var output []byte
+	if interactive {
+		err = killer.ui.screen.PauseAndCall(func() error {
+			fmt.Println("Running: sudo " + strings.Join(args, " "))
+
+			cmd := exec.Command("sudo", args...)
+			cmd.Stdin = os.Stdin
+			cmd.Stdout = os.Stdout
+			cmd.Stderr = os.Stderr
+			return cmd.Run()
+		})
+	} else {
+		output, err = exec.Command("sudo", args...).CombinedOutput()
+	}
 	if err != nil {
-		return err.Error()
+		log.Infof("sudo %s failed: %v", strings.Join(args, " "), err)
+		if len(bytes.TrimSpace(output)) > 0 {
+			return "sudo kill failed: " + string(bytes.TrimSpace(output))
+		}
+		return "sudo kill failed: " + err.Error()
 	}
 
 	// Remember what we just did
 	killer.setLastSignal(signal)
 
-	log.Debugf("Sent signal %d to process %s", signal, killer.process.String())
+	log.Debugf("Sent signal %d using sudo %s", signal, strings.Join(args, " "))
 	return ""
 }
 
+// Arguments for sudo, for sending signal to either the PIDs or to the process
+// group.
+//
+// Non-interactive invocations get -n, making sudo fail rather than prompting
+// for a password.
+func sudoKillArgs(signal syscall.Signal, pids []int, scope killScope, pgid int, interactive bool) []string {
+	args := []string{}
+	if !interactive {
+		args = append(args, "-n")
+	}
+	args = append(args, "kill", "-s", signalName(signal))
+
+	if scope == killScopeGroup {
+		// Negative PIDs are process groups, "--" keeps kill from parsing
+		// this one as an option
+		return append(args, "--", "-"+strconv.Itoa(pgid))
+	}
+
+	for _, pid := range pids {
+		args = append(args, strconv.Itoa(pid))
+	}
+	return args
+}
+
+// True if the last kill failed for lack of permissions, and the user can ask
+// us to retry it with sudo
+func (killer *eventHandlerKill) canRetryWithSudo() bool {
+	return killer.getExcuse() != "" &&
+		killer.picked != nil &&
+		killer.permissionDenied.Load() &&
+		!killer.useSudo.Load()
+}
+
+// Re-send the signal that failed using sudo, then carry on as if the first
+// attempt had worked
+func (killer *eventHandlerKill) retryWithSudo() {
+	killer.useSudo.Store(true)
+	killer.setExcuse("")
+
+	signal := *killer.picked
+	excuse := killer.sudoKill(signal.signal, true)
+	if excuse != "" {
+		killer.setExcuse(excuse)
+		killer.ui.requestRedraw()
+		return
+	}
+
+	killer.awaitOutcome(signal)
+}
+
+// Open handles for all targets, skipping the ones that have gone away since
+// the user picked them. Handles are closed by close().
+func (killer *eventHandlerKill) openHandles() {
+	killer.handlesLock.Lock()
+	defer killer.handlesLock.Unlock()
+
+	if killer.handles != nil {
+		// Already open
+		return
+	}
+
+	killer.handles = make([]targetHandle, len(killer.targets))
+	if killer.ui.remote() != nil {
+		// The agent opens its own handles
+		return
+	}
+
+	for i, target := range killer.targets {
+		handle, err := target.OpenHandle()
+		if errors.Is(err, processes.ErrPidReused) || errors.Is(err, syscall.ESRCH) || errors.Is(err, os.ErrNotExist) {
+			// Whatever has this PID now, it's not what the user picked
+			log.Infof("Not signalling %s, it's gone: %v", target.String(), err)
+			killer.handles[i].gone = true
+			continue
+		}
+		if err != nil {
+			if !errors.Is(err, errors.ErrUnsupported) {
+				log.Infof("Failed to open a handle for %s, falling back to its PID: %v", target.String(), err)
+			}
+			continue
+		}
+
+		killer.handles[i].handle = handle
+	}
+}
+
+func (killer *eventHandlerKill) closeHandles() {
+	killer.handlesLock.Lock()
+	defer killer.handlesLock.Unlock()
+
+	for i := range killer.handles {
+		if killer.handles[i].handle == nil {
+			continue
+		}
+
+		err := killer.handles[i].handle.Close()
+		if err != nil {
+			log.Infof("Failed to close handle for %s: %v", killer.targets[i].String(), err)
+		}
+		killer.handles[i].handle = nil
+	}
+}
+
+// True if any of the processes we are signalling is still around
+func (killer *eventHandlerKill) anyAlive() bool {
+	killer.handlesLock.Lock()
+	defer killer.handlesLock.Unlock()
+
+	remote := killer.ui.remote()
+	for i, target := range killer.targets {
+		if killer.handles[i].gone {
+			continue
+		}
+
+		handle := killer.handles[i].handle
+		if handle == nil {
+			if remote != nil && remote.IsAlive(target) {
+				return true
+			}
+			if remote == nil && target.IsAlive() {
+				return true
+			}
+			continue
+		}
+
+		exited, err := processes.WaitForAnyExit([]*processes.ProcessHandle{handle}, 0)
+		if err != nil || len(exited) == 0 {
+			return true
+		}
+		killer.handles[i].gone = true
+	}
+
+	return false
+}
+
+// Sleep for at most timeout, waking up early if any of our targets exits.
+// Targets without handles are only noticed when we are done sleeping.
+func (killer *eventHandlerKill) sleepUntilAnyExit(timeout time.Duration) {
+	killer.handlesLock.Lock()
+	handles := []*processes.ProcessHandle{}
+	for _, targetHandle := range killer.handles {
+		if !targetHandle.gone && targetHandle.handle != nil {
+			handles = append(handles, targetHandle.handle)
+		}
+	}
+	killer.handlesLock.Unlock()
+
+	if len(handles) == 0 {
+		time.Sleep(timeout)
+		return
+	}
+
+	// The next anyAlive() call will tell us which ones exited
+	_, err := processes.WaitForAnyExit(handles, timeout)
+	if err != nil {
+		log.Infof("Waiting for processes to exit failed: %v", err)
+		time.Sleep(timeout)
+	}
+}
+
 func (killer *eventHandlerKill) onRune(r rune) {
+	if r == sudoRetryKey && killer.canRetryWithSudo() {
+		killer.retryWithSudo()
+		return
+	}
+
 	if killer.getExcuse() != "" {
 		// Kill was attempted but failed, user should have been informed, exit
 		// on any key
@@ -107,19 +470,68 @@ func (killer *eventHandlerKill) onRune(r rune) {
 		return
 	}
 
+	if killer.isDone() {
+		// Signal sent, user should have been informed, exit on any key
+		killer.close()
+		return
+	}
+
 	if killer.hasLastSignal() {
 		// We have already started signalling, ignore all keyboard input except
 		// for ESC, but that's handled in onKeyCode().
 		return
 	}
 
-	if r != 'k' {
+	if killer.signalName != nil {
+		*killer.signalName += string(r)
+		return
+	}
+
+	if r == signalByNameKey {
+		killer.signalName = new(string)
+		return
+	}
+
+	if scope, found := killScopeByKey(r); found {
+		killer.setScope(scope)
+		killer.ui.requestRedraw()
+		return
+	}
+
+	signal, found := killSignalByKey(r)
+	if !found {
 		// Abort
 		killer.close()
 		return
 	}
 
-	excuse := killer.kill(syscall.SIGTERM)
+	killer.send(signal)
+}
+
+// True until the user has picked a signal
+func (killer *eventHandlerKill) isAwaitingConfirmation() bool {
+	return killer.getExcuse() == "" && !killer.hasLastSignal() && killer.signalName == nil
+}
+
+func (killer *eventHandlerKill) describeTargets() string {
+	return describeKillTargets(killer.process, killer.scope, len(killer.targets), killer.pgid)
+}
+
+// True if we have sent a signal that we don't expect the process to die from
+func (killer *eventHandlerKill) isDone() bool {
+	return killer.picked != nil && !killer.picked.waitsForDeath && killer.hasLastSignal()
+}
+
+func (killer *eventHandlerKill) send(signal killSignal) {
+	killer.picked = &signal
+	killer.openHandles()
+	if !killer.anyAlive() {
+		killer.setExcuse("Already gone")
+		killer.ui.requestRedraw()
+		return
+	}
+
+	excuse := killer.kill(signal.signal)
 	if excuse != "" {
 		// Kill failed, set an excuse that we can show to the user
 		killer.setExcuse(excuse)
@@ -127,29 +539,27 @@ func (killer *eventHandlerKill) onRune(r rune) {
 		return
 	}
 
+	killer.awaitOutcome(signal)
+}
+
+// Call after sending a signal. Waits for the process to die if we expect it to,
+// and escalates to SIGKILL if that was a SIGTERM and it didn't work.
+func (killer *eventHandlerKill) awaitOutcome(signal killSignal) {
 	killer.ui.requestRedraw()
 
+	if !signal.waitsForDeath {
+		// The UI will tell the user we sent it, nothing more to do
+		return
+	}
+
 	go func() {
-		// Wait 5s for the process to die
-		deadline := time.Now().Add(KillTimeout)
-		for time.Now().Before(deadline) {
-			if killer.closing.Load() {
-				// User aborted, stop waiting and don't send any more signals
-				killer.ui.requestRedraw()
-				return
-			}
-			if !killer.process.IsAlive() {
-				// It's gone!
-				killer.close()
-				killer.ui.requestRedraw()
-				return
-			}
-			time.Sleep(deathPollInterval)
-			killer.ui.requestRedraw()
+		if killer.awaitDeath() {
+			return
 		}
 
-		if killer.closing.Load() {
-			// User aborted, we are done
+		if signal.signal != syscall.SIGTERM {
+			// Only SIGTERM escalates, tell the user we failed
+			killer.setExcuse("Process is still alive after SIG" + signal.name())
 			killer.ui.requestRedraw()
 			return
 		}
@@ -163,22 +573,8 @@ func (killer *eventHandlerKill) onRune(r rune) {
 			return
 		}
 
-		// Wait 5s for the process to die
-		deadline = time.Now().Add(KillTimeout)
-		for time.Now().Before(deadline) {
-			if killer.closing.Load() {
-				// User aborted, stop waiting
-				killer.ui.requestRedraw()
-				return
-			}
-			if !killer.process.IsAlive() {
-				// It's gone!
-				killer.close()
-				killer.ui.requestRedraw()
-				return
-			}
-			time.Sleep(deathPollInterval)
-			killer.ui.requestRedraw()
+		if killer.awaitDeath() {
+			return
 		}
 
 		// Tell the user we failed
@@ -187,8 +583,42 @@ func (killer *eventHandlerKill) onRune(r rune) {
 	}()
 }
 
+// Wait KillTimeout for the process to die. Returns true if we are done, either
+// because the process died or because the user aborted. Returns false if the
+// process is still alive.
+func (killer *eventHandlerKill) awaitDeath() bool {
+	deadline := time.Now().Add(KillTimeout)
+	for time.Now().Before(deadline) {
+		if killer.closing.Load() {
+			// User aborted, stop waiting and don't send any more signals
+			killer.ui.requestRedraw()
+			return true
+		}
+		if !killer.anyAlive() {
+			// It's gone!
+			killer.close()
+			killer.ui.requestRedraw()
+			return true
+		}
+
+		// Wakes up early when our process dies, but no later than we need to
+		// update the progress bar
+		killer.sleepUntilAnyExit(deathPollInterval)
+		killer.ui.requestRedraw()
+	}
+
+	if killer.closing.Load() {
+		// User aborted, we are done
+		killer.ui.requestRedraw()
+		return true
+	}
+
+	return false
+}
+
 func (killer *eventHandlerKill) close() {
 	killer.closing.Store(true)
+	killer.closeHandles()
 
 	select {
 	case killer.ui.events <- replaceEventHandler{
@@ -206,9 +636,33 @@ func (killer *eventHandlerKill) onKeyCode(keyCode twin.KeyCode) {
 		return
 	}
 
-	if killer.getExcuse() != "" {
-		// Kill was attempted but failed, exit on any key
+	if killer.getExcuse() != "" || killer.isDone() {
+		// Kill was attempted, exit on any key
 		killer.close()
 		return
 	}
+
+	if killer.signalName == nil {
+		return
+	}
+
+	if keyCode == twin.KeyBackspace {
+		// Unicode friendly delete-last-character
+		runes := []rune(*killer.signalName)
+		if len(runes) > 0 {
+			*killer.signalName = string(runes[:len(runes)-1])
+		}
+		return
+	}
+
+	if keyCode == twin.KeyEnter {
+		signal, err := parseKillSignal(*killer.signalName)
+		killer.signalName = nil
+		if err != nil {
+			killer.setExcuse(err.Error())
+			return
+		}
+
+		killer.send(signal)
+	}
 }
@@ -0,0 +1,23 @@
+package ftop
+
+import (
+	"syscall"
+	"testing"
+
+	"github.com/walles/ftop/internal/assert"
+)
+
+func TestSudoKillArgs(t *testing.T) {
+	assert.SlicesEqual(t,
+		sudoKillArgs(syscall.SIGTERM, []int{1234, 1235}, killScopeTree, 0, true),
+		[]string{"kill", "-s", "TERM", "1234", "1235"})
+
+	// Don't prompt for a password when escalating in the background
+	assert.SlicesEqual(t,
+		sudoKillArgs(syscall.SIGKILL, []int{1234}, killScopeProcess, 0, false),
+		[]string{"-n", "kill", "-s", "KILL", "1234"})
+
+	assert.SlicesEqual(t,
+		sudoKillArgs(syscall.SIGHUP, []int{1234, 1235}, killScopeGroup, 1234, true),
+		[]string{"kill", "-s", "HUP", "--", "-1234"})
+}
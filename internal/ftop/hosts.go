@@ -0,0 +1,83 @@
+package ftop
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/walles/ftop/internal/snapshots"
+)
+
+// One machine to show in the multi-host overview
+type Host struct {
+	Name string
+
+	// Starts an agent, which we then talk to over its stdin and stdout
+	Command []string
+}
+
+// Hosts files have one host per line. Each line is a name, optionally followed
+// by a command for starting an agent on that host:
+//
+//	# Runs "ssh webserver ftop --agent"
+//	webserver
+//
+//	# Runs the command after the name
+//	database ssh -p 2222 db.example.com /opt/ftop/ftop --agent
+//
+// Empty lines and lines starting with # are ignored. If allowKill is true,
+// the default SSH commands start their agents with --allow-kill.
+func ReadHostsFile(path string, allowKill bool) ([]Host, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	hosts, err := parseHosts(file, allowKill)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return hosts, nil
+}
+
+func parseHosts(reader io.Reader, allowKill bool) ([]Host, error) {
+	var hosts []Host
+	names := make(map[string]bool)
+
+	scanner := bufio.NewScanner(reader)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		name := fields[0]
+		if names[name] {
+			return nil, fmt.Errorf("line %d: host %s listed twice", lineNumber, name)
+		}
+		names[name] = true
+
+		command := fields[1:]
+		if len(command) == 0 {
+			// We'll be running in the background, so SSH can't prompt for
+			// passwords
+			command = snapshots.SshAgentCommand(name, allowKill, false)
+		}
+
+		hosts = append(hosts, Host{Name: name, Command: command})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("no hosts listed")
+	}
+
+	return hosts, nil
+}
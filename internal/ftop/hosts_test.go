@@ -0,0 +1,35 @@
+package ftop
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/walles/ftop/internal/assert"
+)
+
+func TestParseHosts(t *testing.T) {
+	hosts, err := parseHosts(strings.NewReader(`
+# Comments are fine
+webserver
+
+  database ssh -p 2222 db.example.com ftop --agent
+`), true)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(hosts), 2)
+
+	assert.Equal(t, hosts[0].Name, "webserver")
+	assert.SlicesEqual(t, hosts[0].Command, []string{"ssh", "-o", "BatchMode=yes", "--", "webserver", "ftop", "--agent", "--allow-kill"})
+
+	assert.Equal(t, hosts[1].Name, "database")
+	assert.SlicesEqual(t, hosts[1].Command, []string{"ssh", "-p", "2222", "db.example.com", "ftop", "--agent"})
+}
+
+func TestParseHosts_Duplicate(t *testing.T) {
+	_, err := parseHosts(strings.NewReader("webserver\ndatabase\nwebserver\n"), false)
+	assert.Equal(t, err.Error(), "line 3: host webserver listed twice")
+}
+
+func TestParseHosts_Empty(t *testing.T) {
+	_, err := parseHosts(strings.NewReader("# Nothing here\n"), false)
+	assert.Equal(t, err.Error(), "no hosts listed")
+}
@@ -0,0 +1,141 @@
+package ftop
+
+import (
+	"time"
+
+	"github.com/walles/ftop/internal/processes"
+	"github.com/walles/ftop/internal/snapshots"
+)
+
+// Bump this whenever a field is removed, renamed or changes meaning. Adding
+// fields doesn't require a bump, consumers are expected to ignore fields they
+// don't know about.
+//
+// The schema is documented in the README, keep that in sync with the types
+// below.
+const JSON_SCHEMA_VERSION = 1
+
+// One sample in --output json or --output ndjson mode
+type jsonSample struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	Timestamp     time.Time         `json:"timestamp"`
+	Sysload       jsonSysload       `json:"sysload"`
+	Io            []jsonIoStat      `json:"io"`
+	Processes     []jsonProcess     `json:"processes"`
+	Launches      *jsonLaunchedNode `json:"launches"` // null until something has been launched
+}
+
+type jsonSysload struct {
+	RamUsedBytes     uint64  `json:"ramUsedBytes"`
+	RamTotalBytes    uint64  `json:"ramTotalBytes"`
+	CpuCoresLogical  int     `json:"cpuCoresLogical"`
+	CpuCoresPhysical int     `json:"cpuCoresPhysical"`
+	LoadAverage1M    float64 `json:"loadAverage1m"`
+	LoadAverage5M    float64 `json:"loadAverage5m"`
+	LoadAverage15M   float64 `json:"loadAverage15m"`
+}
+
+type jsonIoStat struct {
+	DeviceName                  string  `json:"deviceName"`
+	BytesPerSecond              float64 `json:"bytesPerSecond"`
+	HighWatermarkBytesPerSecond float64 `json:"highWatermarkBytesPerSecond"`
+}
+
+// Pointer fields are null when unknown
+type jsonProcess struct {
+	Pid                        int      `json:"pid"`
+	Ppid                       int      `json:"ppid"`
+	Command                    string   `json:"command"`
+	DeduplicationSuffix        string   `json:"deduplicationSuffix"`
+	Cmdline                    string   `json:"cmdline"`
+	User                       string   `json:"user"`
+	CpuPercent                 *float64 `json:"cpuPercent"`
+	CpuTimeSeconds             *float64 `json:"cpuTimeSeconds"`
+	CpuTimeInclChildrenSeconds *float64 `json:"cpuTimeInclChildrenSeconds"`
+	RssKb                      int      `json:"rssKb"`
+	Nativity                   int      `json:"nativity"`
+	SubtreeNativity            int      `json:"subtreeNativity"`
+}
+
+type jsonLaunchedNode struct {
+	Command     string              `json:"command"`
+	LaunchCount int                 `json:"launchCount"`
+	Children    []*jsonLaunchedNode `json:"children"`
+}
+
+// Processes are listed in the same order as in the interactive UI when it
+// sorts by sortMode
+func jsonSnapshot(snapshot snapshots.Snapshot, sortMode SortMode) jsonSample {
+	load := snapshot.Sysload
+	sample := jsonSample{
+		SchemaVersion: JSON_SCHEMA_VERSION,
+		Timestamp:     snapshot.Timestamp,
+		Sysload: jsonSysload{
+			RamUsedBytes:     load.RamUsedBytes,
+			RamTotalBytes:    load.RamTotalBytes,
+			CpuCoresLogical:  load.CpuCoresLogical,
+			CpuCoresPhysical: load.CpuCoresPhysical,
+			LoadAverage1M:    load.LoadAverage1M,
+			LoadAverage5M:    load.LoadAverage5M,
+			LoadAverage15M:   load.LoadAverage15M,
+		},
+		Io:        []jsonIoStat{},
+		Processes: []jsonProcess{},
+		Launches:  toJsonLaunchedNode(snapshot.Launches),
+	}
+
+	for _, stat := range snapshot.IoStats {
+		sample.Io = append(sample.Io, jsonIoStat{
+			DeviceName:                  stat.DeviceName,
+			BytesPerSecond:              stat.BytesPerSecond,
+			HighWatermarkBytesPerSecond: stat.HighWatermark,
+		})
+	}
+
+	for _, p := range sortProcessesBy(snapshot.Processes, sortMode, false) {
+		var cpuTimeSeconds *float64
+		if p.CpuTime != nil {
+			seconds := p.CpuTime.Seconds()
+			cpuTimeSeconds = &seconds
+		}
+		var cpuTimeInclChildrenSeconds *float64
+		if inclChildren := p.CpuTimeInclChildren(); inclChildren != nil {
+			seconds := inclChildren.Seconds()
+			cpuTimeInclChildrenSeconds = &seconds
+		}
+
+		sample.Processes = append(sample.Processes, jsonProcess{
+			Pid:                        p.Pid,
+			Ppid:                       p.Ppid(),
+			Command:                    p.Command(),
+			DeduplicationSuffix:        p.DeduplicationSuffix,
+			Cmdline:                    p.Cmdline,
+			User:                       p.Username,
+			CpuPercent:                 p.CpuPercent(),
+			CpuTimeSeconds:             cpuTimeSeconds,
+			CpuTimeInclChildrenSeconds: cpuTimeInclChildrenSeconds,
+			RssKb:                      p.RssKb,
+			Nativity:                   p.Nativity,
+			SubtreeNativity:            p.SubtreeNativity,
+		})
+	}
+
+	return sample
+}
+
+func toJsonLaunchedNode(node *processes.LaunchNode) *jsonLaunchedNode {
+	if node == nil {
+		return nil
+	}
+
+	converted := &jsonLaunchedNode{
+		Command:     node.Command,
+		LaunchCount: node.LaunchCount,
+		Children:    []*jsonLaunchedNode{},
+	}
+	for _, child := range node.Children {
+		converted.Children = append(converted.Children, toJsonLaunchedNode(child))
+	}
+
+	return converted
+}
@@ -0,0 +1,59 @@
+package ftop
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/walles/ftop/internal/assert"
+	"github.com/walles/ftop/internal/io"
+	"github.com/walles/ftop/internal/processes"
+	"github.com/walles/ftop/internal/snapshots"
+	"github.com/walles/ftop/internal/sysload"
+)
+
+func TestJsonSnapshot(t *testing.T) {
+	procs := []processes.Process{
+		{Pid: 2, Cmdline: "small", Username: "www", RssKb: 20, CpuTime: toDuration(20)},
+		{Pid: 1, Cmdline: "big", Username: "root", RssKb: 10, CpuTime: toDuration(60), Nativity: 3},
+	}
+	launches := &processes.LaunchNode{
+		Command:  "init",
+		Children: []*processes.LaunchNode{{Command: "make", LaunchCount: 2}},
+	}
+	ioStats := []io.Stat{{DeviceName: "disk0", BytesPerSecond: 100, HighWatermark: 200}}
+
+	sample := jsonSnapshot(snapshots.Snapshot{
+		Timestamp: time.Now(),
+		Processes: procs,
+		IoStats:   ioStats,
+		Sysload:   sysload.Sysload{CpuCoresLogical: 8},
+		Launches:  launches,
+	}, SortModeScore)
+	assert.Equal(t, sample.SchemaVersion, JSON_SCHEMA_VERSION)
+	assert.Equal(t, sample.Sysload.CpuCoresLogical, 8)
+	assert.Equal(t, sample.Io[0].HighWatermarkBytesPerSecond, 200.0)
+
+	// Sorted the same way as in the interactive UI
+	assert.Equal(t, sample.Processes[0].Command, "big")
+	assert.Equal(t, *sample.Processes[0].CpuTimeSeconds, 60.0)
+	assert.Equal(t, sample.Processes[0].Nativity, 3)
+	assert.Equal(t, sample.Processes[1].Command, "small")
+
+	assert.Equal(t, sample.Launches.Children[0].Command, "make")
+	assert.Equal(t, sample.Launches.Children[0].LaunchCount, 2)
+
+	// Unknown values should be null, and empty lists should be lists
+	bytes, err := json.Marshal(jsonSnapshot(snapshots.Snapshot{Processes: []processes.Process{{Pid: 1, Cmdline: "init"}}}, SortModeScore))
+	assert.Equal(t, err, nil)
+	var parsed map[string]any
+	assert.Equal(t, json.Unmarshal(bytes, &parsed), nil)
+	assert.Equal(t, parsed["launches"], nil)
+	assert.Equal(t, len(parsed["io"].([]any)), 0)
+
+	process := parsed["processes"].([]any)[0].(map[string]any)
+	_, hasCpuPercent := process["cpuPercent"]
+	assert.Equal(t, hasCpuPercent, true)
+	assert.Equal(t, process["cpuPercent"], nil)
+	assert.Equal(t, process["cpuTimeInclChildrenSeconds"], nil)
+}
@@ -0,0 +1,166 @@
+package ftop
+
+import (
+	"fmt"
+
+	"github.com/walles/ftop/internal/processes"
+)
+
+type killScope int
+
+const (
+	// Just the picked process
+	killScopeProcess killScope = iota
+
+	// The picked process and all its descendants
+	killScopeTree
+
+	// The picked process' process group, signalled using killpg
+	killScopeGroup
+
+	// All processes with the same Command() as the picked one
+	killScopeCommand
+)
+
+// In the order we show them in the kill UI
+var killScopes = []killScope{killScopeProcess, killScopeTree, killScopeGroup, killScopeCommand}
+
+// Key to press in the kill UI to pick this scope. Mustn't collide with any of
+// the killSignals keys.
+func (scope killScope) key() rune {
+	switch scope {
+	case killScopeProcess:
+		return 'p'
+	case killScopeTree:
+		return 't'
+	case killScopeGroup:
+		return 'g'
+	case killScopeCommand:
+		return 'a'
+	}
+
+	panic(fmt.Sprintf("Unknown kill scope %d", scope))
+}
+
+// Shown next to the key in the kill UI
+func (scope killScope) name() string {
+	switch scope {
+	case killScopeProcess:
+		return "Process"
+	case killScopeTree:
+		return "Tree"
+	case killScopeGroup:
+		return "Group"
+	case killScopeCommand:
+		return "All"
+	}
+
+	panic(fmt.Sprintf("Unknown kill scope %d", scope))
+}
+
+func killScopeByKey(key rune) (killScope, bool) {
+	for _, scope := range killScopes {
+		if scope.key() == key {
+			return scope, true
+		}
+	}
+
+	return killScopeProcess, false
+}
+
+// List the processes we would signal for the given scope, parents before
+// children.
+//
+// The process with PID ownPid (that's us) is never included, unless it's the
+// picked process itself. Picking ftop's parent shell and killing its tree
+// shouldn't take ftop down with it.
+//
+// For killScopeGroup, pgidOf is used to look up process groups.
+func killTargets(proc *processes.Process, scope killScope, ownPid int, pgidOf func(pid int) (int, error)) ([]*processes.Process, error) {
+	isTarget := func(p *processes.Process) bool {
+		return p.SameAs(proc)
+	}
+
+	root := proc
+	switch scope {
+	case killScopeProcess:
+		return []*processes.Process{proc}, nil
+
+	case killScopeTree:
+		// Walk the picked process' subtree only
+		isTarget = func(p *processes.Process) bool {
+			return true
+		}
+
+	case killScopeGroup:
+		pgid, err := pgidOf(proc.Pid)
+		if err != nil {
+			return nil, err
+		}
+		ownPgid, err := pgidOf(ownPid)
+		if err == nil && ownPgid == pgid {
+			return nil, fmt.Errorf("%s is in the same process group as ftop", proc.String())
+		}
+
+		isTarget = func(p *processes.Process) bool {
+			processPgid, err := pgidOf(p.Pid)
+			return err == nil && processPgid == pgid
+		}
+
+		for root.Parent() != nil {
+			root = root.Parent()
+		}
+
+	case killScopeCommand:
+		isTarget = func(p *processes.Process) bool {
+			return p.Command() == proc.Command()
+		}
+
+		for root.Parent() != nil {
+			root = root.Parent()
+		}
+	}
+
+	targets := []*processes.Process{}
+	var walk func(p *processes.Process)
+	walk = func(p *processes.Process) {
+		if isTarget(p) && (p.Pid != ownPid || p.SameAs(proc)) {
+			targets = append(targets, p)
+		}
+		for _, child := range p.Children() {
+			walk(child)
+		}
+	}
+	walk(root)
+
+	return targets, nil
+}
+
+// What we are about to signal, for showing in the kill UI. Example return
+// values:
+//
+//	make(1234)
+//	make(1234) and 12 descendants
+//	process group 1234, 13 processes
+//	all 3 make processes
+func describeKillTargets(proc *processes.Process, scope killScope, targetCount int, pgid int) string {
+	switch scope {
+	case killScopeTree:
+		if targetCount == 2 {
+			return proc.String() + " and 1 descendant"
+		}
+		return fmt.Sprintf("%s and %d descendants", proc.String(), targetCount-1)
+	case killScopeGroup:
+		if targetCount == 1 {
+			return fmt.Sprintf("process group %d, 1 process", pgid)
+		}
+		return fmt.Sprintf("process group %d, %d processes", pgid, targetCount)
+	case killScopeCommand:
+		if targetCount == 1 {
+			return proc.String() + ", the only " + proc.Command() + " process"
+		}
+		return fmt.Sprintf("all %d %s processes", targetCount, proc.Command())
+	}
+
+	return proc.String()
+}
@@ -0,0 +1,58 @@
+package ftop
+
+import (
+	"testing"
+
+	"github.com/walles/ftop/internal/assert"
+	"github.com/walles/ftop/internal/processes"
+)
+
+func TestKillTargets_Process(t *testing.T) {
+	proc := &processes.Process{Pid: 1234, Cmdline: "make"}
+
+	targets, err := killTargets(proc, killScopeProcess, 1, nil)
+	assert.Equal(t, err, nil)
+	assert.SlicesEqual(t, targets, []*processes.Process{proc})
+}
+
+func TestKillTargets_GroupRefusesOwnGroup(t *testing.T) {
+	proc := &processes.Process{Pid: 1234, Cmdline: "make"}
+	samePgid := func(pid int) (int, error) {
+		return 42, nil
+	}
+
+	_, err := killTargets(proc, killScopeGroup, 5678, samePgid)
+	assert.Equal(t, err != nil, true)
+}
+
+func TestKillTargets_GroupIncludesPickedProcess(t *testing.T) {
+	proc := &processes.Process{Pid: 1234, Cmdline: "make"}
+	pgidOf := func(pid int) (int, error) {
+		// We are in our own group, make is in its own
+		return pid, nil
+	}
+
+	targets, err := killTargets(proc, killScopeGroup, 5678, pgidOf)
+	assert.Equal(t, err, nil)
+	assert.SlicesEqual(t, targets, []*processes.Process{proc})
+}
+
+func TestDescribeKillTargets(t *testing.T) {
+	proc := &processes.Process{Pid: 1234, Cmdline: "make"}
+
+	assert.Equal(t, describeKillTargets(proc, killScopeProcess, 1, 0), "make(1234)")
+	assert.Equal(t, describeKillTargets(proc, killScopeTree, 13, 0), "make(1234) and 12 descendants")
+	assert.Equal(t, describeKillTargets(proc, killScopeGroup, 13, 1234), "process group 1234, 13 processes")
+	assert.Equal(t, describeKillTargets(proc, killScopeCommand, 3, 0), "all 3 make processes")
+
+	assert.Equal(t, describeKillTargets(proc, killScopeTree, 2, 0), "make(1234) and 1 descendant")
+	assert.Equal(t, describeKillTargets(proc, killScopeCommand, 1, 0), "make(1234), the only make process")
+}
+
+func TestKillScopeKeysDontCollideWithSignalKeys(t *testing.T) {
+	for _, scope := range killScopes {
+		_, found := killSignalByKey(scope.key())
+		assert.Equal(t, found, false)
+		assert.Equal(t, scope.key() == signalByNameKey, false)
+	}
+}
@@ -0,0 +1,90 @@
+package ftop
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+type killSignal struct {
+	// Key to press in the kill UI to send this signal, 0 if none
+	key rune
+
+	signal syscall.Signal
+
+	// If true, we expect the process to die from this signal, and show a
+	// progress bar while waiting for that to happen
+	waitsForDeath bool
+}
+
+// The first one is the default, and the only one escalating to SIGKILL if the
+// process doesn't die.
+var killSignals = []killSignal{
+	{key: 'k', signal: syscall.SIGTERM, waitsForDeath: true},
+	{key: 'h', signal: syscall.SIGHUP},
+	{key: 'i', signal: syscall.SIGINT, waitsForDeath: true},
+	{key: 's', signal: syscall.SIGSTOP},
+	{key: 'c', signal: syscall.SIGCONT},
+	{key: 'u', signal: syscall.SIGUSR1},
+	{key: '9', signal: syscall.SIGKILL, waitsForDeath: true},
+}
+
+// Press this in the kill UI to type a signal name
+const signalByNameKey = 'n'
+
+// "TERM" for SIGTERM, see signalName()
+func (s killSignal) name() string {
+	return signalName(s.signal)
+}
+
+// "TERM" for SIGTERM
+func signalName(signal syscall.Signal) string {
+	return strings.TrimPrefix(unix.SignalName(signal), "SIG")
+}
+
+func killSignalByKey(key rune) (killSignal, bool) {
+	for _, s := range killSignals {
+		if s.key == key {
+			return s, true
+		}
+	}
+
+	return killSignal{}, false
+}
+
+// Accepts names with or without a SIG prefix, in any case, and signal numbers.
+// "usr2", "SIGUSR2" and "12" all give you SIGUSR2 on Linux.
+func parseKillSignal(name string) (killSignal, error) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if name == "" {
+		return killSignal{}, fmt.Errorf("no signal name given")
+	}
+
+	var signal syscall.Signal
+	if number, err := strconv.Atoi(name); err == nil {
+		signal = syscall.Signal(number)
+		if unix.SignalName(signal) == "" {
+			return killSignal{}, fmt.Errorf("unknown signal number %d", number)
+		}
+	} else {
+		if !strings.HasPrefix(name, "SIG") {
+			name = "SIG" + name
+		}
+		signal = unix.SignalNum(name)
+		if signal == 0 {
+			return killSignal{}, fmt.Errorf("unknown signal %s", name)
+		}
+	}
+
+	for _, s := range killSignals {
+		if s.signal == signal {
+			// Get the same behavior as when picking it from the menu
+			return s, nil
+		}
+	}
+
+	return killSignal{signal: signal}, nil
+}
@@ -0,0 +1,45 @@
+package ftop
+
+import (
+	"syscall"
+	"testing"
+
+	"github.com/walles/ftop/internal/assert"
+)
+
+func TestParseKillSignal(t *testing.T) {
+	for _, name := range []string{"usr2", "SIGUSR2", " Usr2 "} {
+		signal, err := parseKillSignal(name)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, signal.signal, syscall.SIGUSR2)
+		assert.Equal(t, signal.waitsForDeath, false)
+		assert.Equal(t, signal.name(), "USR2")
+	}
+
+	signal, err := parseKillSignal("9")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, signal.signal, syscall.SIGKILL)
+	assert.Equal(t, signal.waitsForDeath, true)
+
+	// Same behavior as when picked from the menu
+	signal, err = parseKillSignal("term")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, signal.key, 'k')
+}
+
+func TestParseKillSignal_Invalid(t *testing.T) {
+	for _, name := range []string{"", "SIGFOO", "12345", "-1"} {
+		_, err := parseKillSignal(name)
+		assert.Equal(t, err != nil, true)
+	}
+}
+
+func TestKillSignalByKey(t *testing.T) {
+	signal, found := killSignalByKey('h')
+	assert.Equal(t, found, true)
+	assert.Equal(t, signal.signal, syscall.SIGHUP)
+	assert.Equal(t, signal.name(), "HUP")
+
+	_, found = killSignalByKey('x')
+	assert.Equal(t, found, false)
+}
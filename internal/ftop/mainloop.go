@@ -3,9 +3,9 @@ package ftop
 import (
 	"runtime/debug"
 
-	"github.com/walles/ftop/internal/io"
 	"github.com/walles/ftop/internal/log"
 	"github.com/walles/ftop/internal/processes"
+	"github.com/walles/ftop/internal/snapshots"
 	"github.com/walles/moor/v2/twin"
 )
 
@@ -19,24 +19,29 @@ type replaceEventHandler struct {
 
 type redrawUi struct{}
 
-func (ui *Ui) MainLoop() {
-	procsTracker := processes.NewTracker()
-	ioTracker := io.NewTracker()
+func (ui *Ui) MainLoop(source snapshots.Source) {
+	ui.source = source
 
 	go func() {
 		defer func() {
-			log.PanicHandler("main/screen events poller", recover(), debug.Stack())
+			log.PanicHandler("main/snapshots poller", recover(), debug.Stack())
 		}()
-		for event := range ui.screen.Events() {
-			ui.events <- event
+		for range source.OnUpdate() {
+			ui.events <- redrawUi{}
 		}
 	}()
+
+	ui.runEventLoop()
+}
+
+// Handle events and redraw until the user quits
+func (ui *Ui) runEventLoop() {
 	go func() {
 		defer func() {
-			log.PanicHandler("main/processes tracker poller", recover(), debug.Stack())
+			log.PanicHandler("main/screen events poller", recover(), debug.Stack())
 		}()
-		for range procsTracker.OnUpdate {
-			ui.events <- redrawUi{}
+		for event := range ui.screen.Events() {
+			ui.events <- event
 		}
 	}()
 
@@ -70,9 +75,13 @@ func (ui *Ui) MainLoop() {
 			continue
 		}
 
-		procs := procsTracker.Processes()
-		procs = processes.Filter(procs, ui.filter)
-		ui.Render(procs, ioTracker.Stats(), procsTracker.Launches())
+		if ui.hosts != nil && ui.host == nil {
+			ui.renderHosts()
+			continue
+		}
+		snapshot := ui.source.Snapshot()
+		snapshot.Processes = processes.Filter(snapshot.Processes, ui.filter)
+		ui.Render(snapshot)
 	}
 }
 
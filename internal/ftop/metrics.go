@@ -0,0 +1,208 @@
+package ftop
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/walles/ftop/internal/io"
+	"github.com/walles/ftop/internal/processes"
+	"github.com/walles/ftop/internal/snapshots"
+)
+
+// How many commands, users and launched commands to export. Anything outside
+// of the top lists gets summed up into an "other" entry, so that the number of
+// time series stays bounded no matter what is running.
+const metricsTopListLength = 25
+
+// Label value for everything outside of the top lists
+const metricsOtherLabel = "(other)"
+
+// Serve Prometheus metrics on http://address/metrics until we fail. Processes
+// not matching filter are left out.
+func ServeMetrics(source snapshots.Source, address string, filter string) error {
+	<-source.OnUpdate() // Wait for the first process list
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		snapshot := source.Snapshot()
+		snapshot.Processes = processes.Filter(snapshot.Processes, filter)
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_, _ = w.Write([]byte(formatMetrics(snapshot)))
+	})
+
+	return http.ListenAndServe(address, mux)
+}
+
+// Render a snapshot in the Prometheus text exposition format:
+// https://prometheus.io/docs/instrumenting/exposition_formats/
+func formatMetrics(snapshot snapshots.Snapshot) string {
+	var sb strings.Builder
+
+	writeMetricHeader(&sb, "ftop_load_average", "gauge", "System load average")
+	load := snapshot.Sysload
+	writeMetric(&sb, "ftop_load_average", []string{"window", "1m"}, load.LoadAverage1M)
+	writeMetric(&sb, "ftop_load_average", []string{"window", "5m"}, load.LoadAverage5M)
+	writeMetric(&sb, "ftop_load_average", []string{"window", "15m"}, load.LoadAverage15M)
+
+	writeMetricHeader(&sb, "ftop_ram_used_bytes", "gauge", "RAM in use")
+	writeMetric(&sb, "ftop_ram_used_bytes", nil, float64(load.RamUsedBytes))
+	writeMetricHeader(&sb, "ftop_ram_total_bytes", "gauge", "Total amount of RAM")
+	writeMetric(&sb, "ftop_ram_total_bytes", nil, float64(load.RamTotalBytes))
+
+	writeMetricHeader(&sb, "ftop_device_io_bytes_total", "counter", "Bytes transferred per network interface and disk")
+	for _, stat := range topIoStats(snapshot.IoStats) {
+		writeMetric(&sb, "ftop_device_io_bytes_total", []string{"device", stat.DeviceName}, float64(stat.BytesTotal))
+	}
+
+	users := aggregate(snapshot.Processes, false, func(p processes.Process) string { return p.Username }, func(stat stats) userStats {
+		return userStats{stats: stat}
+	})
+	users = SortByScore(users, func(u userStats) stats { return u.stats })
+	writeStatsMetrics(&sb, "user", withOther(users, func(u userStats) stats { return u.stats }))
+
+	commands := aggregateCommands(snapshot.Processes, SortModeScore, false)
+	writeStatsMetrics(&sb, "command", withOther(commands, func(c commandStats) stats { return c.stats }))
+
+	writeMetricHeader(&sb, "ftop_launches_total", "counter", "Processes launched per command since ftop started")
+	for _, launch := range topLaunches(snapshot.Launches) {
+		writeMetric(&sb, "ftop_launches_total", []string{"command", launch.command}, float64(launch.count))
+	}
+
+	return sb.String()
+}
+
+// The top metricsTopListLength entries, plus one summing up the rest if there
+// are more. Input should be sorted top list first.
+func withOther[T any](sorted []T, asStats func(T) stats) []stats {
+	var returnMe []stats
+	for i, t := range sorted {
+		stat := asStats(t)
+		if i < metricsTopListLength {
+			returnMe = append(returnMe, stat)
+			continue
+		}
+
+		if i == metricsTopListLength {
+			returnMe = append(returnMe, stats{name: metricsOtherLabel})
+		}
+		other := &returnMe[len(returnMe)-1]
+		other.cpuTime += stat.cpuTime
+		other.rssKb += stat.rssKb
+		other.nativity += stat.nativity
+	}
+
+	return returnMe
+}
+
+// Kind is "user" or "command", used both in the metric names and as the label
+// name
+func writeStatsMetrics(sb *strings.Builder, kind string, stats []stats) {
+	cpuName := "ftop_" + kind + "_cpu_seconds"
+	writeMetricHeader(sb, cpuName, "gauge", "CPU time used per "+kind+", by the processes running right now")
+	for _, stat := range stats {
+		writeMetric(sb, cpuName, []string{kind, stat.name}, stat.cpuTime.Seconds())
+	}
+
+	ramName := "ftop_" + kind + "_ram_bytes"
+	writeMetricHeader(sb, ramName, "gauge", "RSS used per "+kind)
+	for _, stat := range stats {
+		writeMetric(sb, ramName, []string{kind, stat.name}, float64(stat.rssKb)*1024)
+	}
+
+	nativityName := "ftop_" + kind + "_nativity"
+	writeMetricHeader(sb, nativityName, "gauge", "Child processes launched per "+kind+" during the last minute")
+	for _, stat := range stats {
+		writeMetric(sb, nativityName, []string{kind, stat.name}, float64(stat.nativity))
+	}
+}
+
+type launchCount struct {
+	command string
+	count   int
+}
+
+// Launch counts summed up per command, most launched first, limited to
+// metricsTopListLength entries.
+//
+// Unlike for the other top lists there is no "other" entry here. Its value
+// could go down when something entered the top list, and counters must never
+// go down.
+func topLaunches(root *processes.LaunchNode) []launchCount {
+	counts := make(map[string]int)
+	var walk func(node *processes.LaunchNode)
+	walk = func(node *processes.LaunchNode) {
+		if node == nil {
+			return
+		}
+		if node.LaunchCount > 0 {
+			counts[node.Command] += node.LaunchCount
+		}
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	walk(root)
+
+	var returnMe []launchCount
+	for command, count := range counts {
+		returnMe = append(returnMe, launchCount{command: command, count: count})
+	}
+	slices.SortFunc(returnMe, func(a, b launchCount) int {
+		if a.count != b.count {
+			return b.count - a.count
+		}
+		return strings.Compare(a.command, b.command)
+	})
+
+	return returnMe[:min(len(returnMe), metricsTopListLength)]
+}
+
+// The metricsTopListLength devices that have transferred the most, sorted by
+// name. Container hosts can have a virtual network interface per container.
+//
+// Like for launches, there is no "other" entry since this is a counter too.
+func topIoStats(ioStats []io.Stat) []io.Stat {
+	top := slices.Clone(ioStats)
+	slices.SortFunc(top, func(a, b io.Stat) int {
+		return cmp.Or(
+			cmp.Compare(b.BytesTotal, a.BytesTotal),
+			strings.Compare(a.DeviceName, b.DeviceName),
+		)
+	})
+	top = top[:min(len(top), metricsTopListLength)]
+
+	slices.SortFunc(top, func(a, b io.Stat) int {
+		return strings.Compare(a.DeviceName, b.DeviceName)
+	})
+	return top
+}
+
+func writeMetricHeader(sb *strings.Builder, name string, metricType string, help string) {
+	fmt.Fprintf(sb, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(sb, "# TYPE %s %s\n", name, metricType)
+}
+
+// Labels are name, value, name, value...
+func writeMetric(sb *strings.Builder, name string, labels []string, value float64) {
+	sb.WriteString(name)
+	if len(labels) > 0 {
+		sb.WriteString("{")
+		for i := 0; i < len(labels); i += 2 {
+			if i > 0 {
+				sb.WriteString(",")
+			}
+			fmt.Fprintf(sb, "%s=\"%s\"", labels[i], escapeLabelValue(labels[i+1]))
+		}
+		sb.WriteString("}")
+	}
+	fmt.Fprintf(sb, " %g\n", value)
+}
+
+// Ref: https://prometheus.io/docs/instrumenting/exposition_formats/#text-format-details
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
@@ -0,0 +1,83 @@
+package ftop
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/walles/ftop/internal/assert"
+	"github.com/walles/ftop/internal/io"
+	"github.com/walles/ftop/internal/processes"
+	"github.com/walles/ftop/internal/snapshots"
+	"github.com/walles/ftop/internal/sysload"
+)
+
+func TestFormatMetrics(t *testing.T) {
+	procs := []processes.Process{
+		{Pid: 1, Cmdline: "init", Username: "root", RssKb: 10, CpuTime: toDuration(60), Nativity: 3},
+		{Pid: 2, Cmdline: "bash", Username: `"quoted"`, RssKb: 20, CpuTime: toDuration(20)},
+		{Pid: 3, Cmdline: "bash", Username: `"quoted"`, RssKb: 30, CpuTime: toDuration(10)},
+	}
+	launches := &processes.LaunchNode{
+		Command: "init",
+		Children: []*processes.LaunchNode{
+			{Command: "make", LaunchCount: 2, Children: []*processes.LaunchNode{{Command: "cc", LaunchCount: 5}}},
+			{Command: "cron", LaunchCount: 1, Children: []*processes.LaunchNode{{Command: "make", LaunchCount: 1}}},
+		},
+	}
+
+	metrics := formatMetrics(snapshots.Snapshot{
+		Processes: procs,
+		IoStats:   []io.Stat{{DeviceName: "eth0 (in)", BytesTotal: 12345}},
+		Sysload:   sysload.Sysload{LoadAverage1M: 1.5},
+		Launches:  launches,
+	})
+	lines := strings.Split(metrics, "\n")
+
+	contains := func(line string) bool {
+		return slices.Contains(lines, line)
+	}
+
+	assert.Equal(t, contains(`ftop_load_average{window="1m"} 1.5`), true)
+	assert.Equal(t, contains(`ftop_device_io_bytes_total{device="eth0 (in)"} 12345`), true)
+	assert.Equal(t, contains(`ftop_command_cpu_seconds{command="bash"} 30`), true)
+	assert.Equal(t, contains(`ftop_command_ram_bytes{command="bash"} 51200`), true)
+	assert.Equal(t, contains(`ftop_command_nativity{command="init"} 3`), true)
+	assert.Equal(t, contains(`ftop_user_cpu_seconds{user="\"quoted\""} 30`), true)
+	assert.Equal(t, contains(`ftop_launches_total{command="make"} 3`), true)
+	assert.Equal(t, contains(`ftop_launches_total{command="cc"} 5`), true)
+	assert.Equal(t, contains(`ftop_launches_total{command="init"} 0`), false)
+}
+
+func TestFormatMetrics_BoundedCardinality(t *testing.T) {
+	var procs []processes.Process
+	for i := range 2 * metricsTopListLength {
+		procs = append(procs, processes.Process{
+			Pid:      i + 1,
+			Cmdline:  fmt.Sprintf("command%d", i),
+			Username: "root",
+			RssKb:    1,
+			CpuTime:  toDuration(1),
+		})
+	}
+
+	metrics := formatMetrics(snapshots.Snapshot{Processes: procs})
+
+	assert.Equal(t, strings.Count(metrics, "ftop_command_ram_bytes{"), metricsTopListLength+1)
+	assert.Equal(t, strings.Contains(metrics, `ftop_command_ram_bytes{command="(other)"} 25600`+"\n"), true)
+}
+
+func TestFormatMetrics_BoundedDevices(t *testing.T) {
+	var ioStats []io.Stat
+	for i := range 2 * metricsTopListLength {
+		ioStats = append(ioStats, io.Stat{DeviceName: fmt.Sprintf("veth%d", i), BytesTotal: uint64(i)})
+	}
+
+	metrics := formatMetrics(snapshots.Snapshot{IoStats: ioStats})
+
+	// The busiest ones
+	assert.Equal(t, strings.Count(metrics, "ftop_device_io_bytes_total{"), metricsTopListLength)
+	assert.Equal(t, strings.Contains(metrics, `ftop_device_io_bytes_total{device="veth49"} 49`+"\n"), true)
+	assert.Equal(t, strings.Contains(metrics, `device="veth0"`), false)
+}
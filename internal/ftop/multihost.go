@@ -0,0 +1,291 @@
+package ftop
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/walles/ftop/internal/log"
+	"github.com/walles/ftop/internal/snapshots"
+	"github.com/walles/moor/v2/twin"
+)
+
+// How long to wait before trying to reconnect to a host
+const hostReconnectInterval = 10 * time.Second
+
+// Our connection to the agent on one of the hosts in the multi-host overview
+type hostConnection struct {
+	host Host
+
+	mutex sync.Mutex
+
+	// nil until we have connected. After that, the latest connection, which
+	// may have been lost.
+	remote *snapshots.Remote
+
+	// Non-nil while the user is looking at this host. Only then do we keep
+	// older snapshots around for rewinding.
+	feed *hostFeed
+
+	err error // Why we failed to connect last time, nil if we didn't
+}
+
+// Show an overview of many hosts, from which the user can drill into each one.
+// Inside of a host, the user can step back rewindLength in time.
+func (ui *Ui) MultiHostMainLoop(hosts []Host, rewindLength time.Duration) {
+	ui.rewindLength = rewindLength
+
+	// Closed when we're done, tells the connection goroutines to stop
+	done := make(chan struct{})
+
+	for _, host := range hosts {
+		conn := &hostConnection{host: host}
+		ui.hosts = append(ui.hosts, conn)
+
+		go func() {
+			defer func() {
+				log.PanicHandler("host connection", recover(), debug.Stack())
+			}()
+			ui.keepConnected(conn, done)
+		}()
+	}
+	ui.eventHandler = &eventHandlerHosts{ui: ui}
+
+	ui.runEventLoop()
+
+	close(done)
+	for _, conn := range ui.hosts {
+		if remote := conn.lastRemote(); remote != nil {
+			remote.Close()
+		}
+	}
+}
+
+// Connect to the host, and reconnect every time we lose the connection. Returns
+// when done is closed.
+func (ui *Ui) keepConnected(conn *hostConnection, done <-chan struct{}) {
+	// Nobody reads ui.events after done is closed, don't block on it then
+	redraw := func() bool {
+		select {
+		case ui.events <- redrawUi{}:
+			return true
+		case <-done:
+			return false
+		}
+	}
+
+	waitBeforeReconnecting := func() bool {
+		select {
+		case <-time.After(hostReconnectInterval):
+			return true
+		case <-done:
+			return false
+		}
+	}
+
+	for {
+		remote, err := snapshots.StartAgent(conn.host.Command)
+		if err != nil {
+			log.Infof("Connecting to %s failed: %v", conn.host.Name, err)
+
+			conn.mutex.Lock()
+			conn.err = err
+			conn.mutex.Unlock()
+			if !redraw() || !waitBeforeReconnecting() {
+				return
+			}
+			continue
+		}
+
+		conn.mutex.Lock()
+		conn.remote = remote
+		conn.err = nil
+		conn.mutex.Unlock()
+
+		select {
+		case <-done:
+			// Connected after MultiHostMainLoop() closed the connections
+			remote.Close()
+			return
+		default:
+		}
+
+		// Ends when the connection is lost
+		for range remote.OnUpdate() {
+			conn.notifyFeed()
+			if !redraw() {
+				return
+			}
+		}
+
+		// Reap the agent command and let go of its pipes before reconnecting
+		remote.Close()
+		if !redraw() || !waitBeforeReconnecting() {
+			return
+		}
+	}
+}
+
+// nil until we have connected
+func (conn *hostConnection) lastRemote() *snapshots.Remote {
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+
+	return conn.remote
+}
+
+// Start passing updates to a new feed, replacing any previous one
+func (conn *hostConnection) openFeed() *hostFeed {
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+
+	if conn.feed != nil {
+		close(conn.feed.onUpdate)
+	}
+	conn.feed = &hostFeed{conn: conn, onUpdate: make(chan struct{}, 1)}
+
+	// Get the latest snapshot on screen without waiting for the next one
+	conn.feed.onUpdate <- struct{}{}
+
+	return conn.feed
+}
+
+// Stop passing updates to the feed, which ends any Rewinder reading from it
+func (conn *hostConnection) closeFeed() {
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+
+	if conn.feed != nil {
+		close(conn.feed.onUpdate)
+		conn.feed = nil
+	}
+}
+
+func (conn *hostConnection) notifyFeed() {
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+
+	if conn.feed == nil {
+		return
+	}
+	select {
+	case conn.feed.onUpdate <- struct{}{}:
+	default:
+		// An update is already pending, no need for another one
+	}
+}
+
+// The latest snapshot from the host, empty until we have heard from it
+func (conn *hostConnection) snapshot() snapshots.Snapshot {
+	remote := conn.lastRemote()
+	if remote == nil {
+		return snapshots.Snapshot{}
+	}
+	return remote.Snapshot()
+}
+
+// Snapshots from whatever connection to the host is the latest one. Lives
+// across reconnects, so that the user's rewind history does too.
+type hostFeed struct {
+	conn     *hostConnection
+	onUpdate chan struct{}
+}
+
+func (f *hostFeed) OnUpdate() <-chan struct{} {
+	return f.onUpdate
+}
+
+func (f *hostFeed) Snapshot() snapshots.Snapshot {
+	return f.conn.snapshot()
+}
+
+// Lets snapshots.Find() get to the Remote, for signalling processes
+func (f *hostFeed) Wrapped() snapshots.Source {
+	remote := f.conn.lastRemote()
+	if remote == nil {
+		return nil
+	}
+	return remote
+}
+
+// Why we aren't connected, or nil if we are
+func (conn *hostConnection) problem() error {
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+
+	if conn.err != nil {
+		return conn.err
+	}
+	if conn.remote == nil {
+		return nil
+	}
+	if err := conn.remote.Err(); err != nil {
+		return fmt.Errorf("disconnected: %w", err)
+	}
+	return nil
+}
+
+// Show everything about the picked host
+func (ui *Ui) enterHost() {
+	conn := ui.hosts[ui.pickedHost]
+	if conn.lastRemote() == nil {
+		// Nothing to show yet
+		return
+	}
+
+	rewinder := snapshots.NewRewinder(conn.openFeed(), ui.rewindLength)
+
+	// Wait for the rewinder to pick up the latest snapshot, so that we don't
+	// render an empty one
+	<-rewinder.OnUpdate()
+
+	ui.host = conn
+	ui.source = rewinder
+	ui.pickedLine = nil
+	ui.pickedProcess = nil
+
+	// Baselines are per host
+	ui.cpuBaseline = nil
+	ui.eventHandler = &eventHandlerBase{ui: ui}
+}
+
+// Go back to the multi-host overview. Returns false if we aren't showing one.
+func (ui *Ui) leaveHost() bool {
+	if ui.host == nil {
+		return false
+	}
+
+	// Drops the rewind history along with the rewinder
+	ui.host.closeFeed()
+
+	ui.host = nil
+	ui.source = nil
+	ui.pickedLine = nil
+	ui.pickedProcess = nil
+	ui.eventHandler = &eventHandlerHosts{ui: ui}
+	return true
+}
+
+type eventHandlerHosts struct {
+	ui *Ui
+}
+
+func (h *eventHandlerHosts) onRune(r rune) {
+	if r == 'q' {
+		h.ui.done = true
+	}
+}
+
+func (h *eventHandlerHosts) onKeyCode(keyCode twin.KeyCode) {
+	switch keyCode {
+	case twin.KeyEscape:
+		h.ui.done = true
+	case twin.KeyDown:
+		h.ui.pickedHost = min(h.ui.pickedHost+1, len(h.ui.hosts)-1)
+	case twin.KeyUp:
+		h.ui.pickedHost = max(h.ui.pickedHost-1, 0)
+	case twin.KeyEnter:
+		h.ui.enterHost()
+	}
+}
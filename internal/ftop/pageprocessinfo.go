@@ -1,6 +1,7 @@
 package ftop
 
 import (
+	"cmp"
 	"fmt"
 	"os"
 	"slices"
@@ -91,7 +92,7 @@ func (u *Ui) buildAndPageProcessInfo(proc *processes.Process) error {
 	pt.writeLine("")
 
 	pt.writeTitle("Timings")
-	age := time.Since(proc.StartTime())
+	age := u.now().Sub(proc.StartTime())
 	cpuTime := time.Duration(0)
 	if proc.CpuTimeTotal != nil {
 		cpuTime = *proc.CpuTimeTotal
@@ -104,6 +105,30 @@ func (u *Ui) buildAndPageProcessInfo(proc *processes.Process) error {
 		u.highlight(util.FormatDuration(cpuTime)),
 		u.highlight(util.FormatPercent(percentCpu)),
 	))
+	if proc.ChildrenCpuTimeTotal != nil && *proc.ChildrenCpuTimeTotal > 0 {
+		pt.writeLine(fmt.Sprintf(
+			"Its exited children used another %s CPU.",
+			u.highlight(util.FormatDuration(*proc.ChildrenCpuTimeTotal)),
+		))
+	}
+
+	pt.writeLine("")
+	pt.writeLine("")
+
+	pt.writeTitle("State")
+	u.stateForPaging(proc, u.now(), &pt)
+
+	pt.writeLine("")
+	pt.writeLine("")
+
+	pt.writeTitle("Memory")
+	u.memoryForPaging(proc, &pt)
+
+	pt.writeLine("")
+	pt.writeLine("")
+
+	pt.writeTitle("Leak Suspects")
+	u.leakSuspectsForPaging(proc, &pt)
 
 	pt.writeLine("")
 	pt.writeLine("")
@@ -124,6 +149,92 @@ func (u *Ui) buildAndPageProcessInfo(proc *processes.Process) error {
 	return moor.PageFromString(pt.String(), moor.Options{NoLineNumbers: true})
 }
 
+func (u *Ui) stateForPaging(proc *processes.Process, now time.Time, pt *pageText) {
+	if proc.IsStuck() {
+		pt.writeLine(describeStuckProcess(proc, now, u.highlight))
+		return
+	}
+
+	line := proc.String() + " is " + u.highlight(proc.StateDescription())
+	if proc.Wchan != "" {
+		line += ", waiting in " + u.highlight(proc.Wchan)
+	}
+	pt.writeLine(line + ".")
+}
+
+func (u *Ui) memoryForPaging(proc *processes.Process, pt *pageText) {
+	formatKb := func(kb int) string {
+		return u.highlight(util.FormatMemory(1024 * int64(kb)))
+	}
+
+	if proc.PssKb == nil || proc.UssKb == nil || proc.SwapKb == nil {
+		pt.writeLine("RSS " + formatKb(proc.RssKb) + ". PSS, USS and swap usage are unknown for this process.")
+		return
+	}
+
+	pt.writeLine(fmt.Sprintf("RSS %s, PSS %s, USS %s, %s swapped out.",
+		formatKb(proc.RssKb),
+		formatKb(*proc.PssKb),
+		formatKb(*proc.UssKb),
+		formatKb(*proc.SwapKb),
+	))
+	pt.writeLine("")
+	pt.writeLine("RSS counts shared memory once for each process sharing it. PSS splits")
+	pt.writeLine("shared memory evenly between those processes. USS is the memory you would")
+	pt.writeLine("get back by killing this process.")
+}
+
+// Show the picked process' RAM trend, followed by all leak suspects in the
+// same process tree, fastest growing first
+func (u *Ui) leakSuspectsForPaging(proc *processes.Process, pt *pageText) {
+	trend := proc.MemoryTrend()
+	if trend.Duration < processes.LEAK_MIN_DURATION {
+		pt.writeLine(fmt.Sprintf("%s has been watched for %s, need %s to tell whether it's leaking.",
+			proc.String(),
+			util.FormatDuration(trend.Duration),
+			util.FormatDuration(processes.LEAK_MIN_DURATION),
+		))
+	} else if trend.IsLeakSuspect {
+		pt.writeLine(u.highlight(proc.String()) + " looks like it's leaking: " + u.highlight(formatMemoryTrend(trend)))
+	} else {
+		pt.writeLine(proc.String() + " doesn't look like it's leaking: " + formatMemoryTrend(trend))
+	}
+
+	suspects := []*processes.Process{}
+	for _, p := range getAllOtherProcesses(proc) {
+		if p.MemoryTrend().IsLeakSuspect {
+			suspects = append(suspects, p)
+		}
+	}
+	if len(suspects) == 0 {
+		pt.writeLine("")
+		pt.writeLine("<No other leak suspects found>")
+		return
+	}
+
+	slices.SortFunc(suspects, func(a, b *processes.Process) int {
+		return -cmp.Compare(a.MemoryTrend().KbPerMinute, b.MemoryTrend().KbPerMinute)
+	})
+
+	pt.writeLine("")
+	pt.writeLine("Other leak suspects:")
+	for _, p := range suspects {
+		pt.writeLine("  " + p.String() + ": " + formatMemoryTrend(p.MemoryTrend()))
+	}
+}
+
+// Example return value: "+12M/min over 40m00s"
+func formatMemoryTrend(trend processes.MemoryTrend) string {
+	sign := "+"
+	kbPerMinute := trend.KbPerMinute
+	if kbPerMinute < 0 {
+		sign = "-"
+		kbPerMinute = -kbPerMinute
+	}
+
+	return sign + util.FormatMemory(int64(1024*kbPerMinute)) + "/min over " + util.FormatDuration(trend.Duration)
+}
+
 func (u *Ui) launchHierarchyForPaging(proc *processes.Process, pt *pageText) {
 	// Build launch hierarchy from root down to current process
 	bottomUpProcs := make([]*processes.Process, 0)
@@ -52,6 +52,15 @@ func TestUsersLoggedInWhenProcessStartedForPagingShowsErrors(t *testing.T) {
 	assert.Equal(t, stringsContains(pt.String(), "\n  <Unable to inspect login history: boom>\n"), false)
 }
 
+func TestFormatMemoryTrend(t *testing.T) {
+	assert.Equal(t,
+		formatMemoryTrend(processes.MemoryTrend{KbPerMinute: 12 * 1024, Duration: 40 * time.Minute}),
+		"+12M/min over 40m00s")
+	assert.Equal(t,
+		formatMemoryTrend(processes.MemoryTrend{KbPerMinute: -512, Duration: 90 * time.Second}),
+		"-512k/min over 1m30s")
+}
+
 func stringsContains(haystack string, needle string) bool {
 	return strings.Contains(haystack, needle)
 }
@@ -0,0 +1,104 @@
+package ftop
+
+import (
+	"slices"
+	"time"
+
+	"github.com/walles/ftop/internal/log"
+	"github.com/walles/ftop/internal/processes"
+	"github.com/walles/ftop/internal/util"
+	"github.com/walles/moor/v2/pkg/moor"
+	"github.com/walles/moor/v2/twin"
+)
+
+// Return the stuck processes, the ones stuck the longest first
+func findStuckProcesses(procs []processes.Process) []processes.Process {
+	stuck := []processes.Process{}
+	for _, p := range procs {
+		if p.IsStuck() {
+			stuck = append(stuck, p)
+		}
+	}
+
+	slices.SortFunc(stuck, func(a, b processes.Process) int {
+		return a.StuckSince().Compare(b.StuckSince())
+	})
+
+	return stuck
+}
+
+// Explain why a stuck process is stuck. Names and durations are passed through
+// highlight(), so that callers can decide how to style them.
+//
+// Example return values:
+//
+//	cp(4244) has been in uninterruptible sleep for 40.0s, waiting in nfs_wait_bit_killable.
+//	bash(4243) has been a zombie for 12.0s, its parent tmux(4242) isn't reaping it.
+func describeStuckProcess(p *processes.Process, now time.Time, highlight func(string) string) string {
+	description := highlight(p.String()) + " has been " + p.StateDescription() +
+		" for " + highlight(util.FormatDuration(now.Sub(p.StuckSince())))
+
+	if p.State == "Z" {
+		if p.Parent() == nil {
+			return description + ", and its parent is unknown."
+		}
+		return description + ", its parent " + highlight(p.Parent().String()) + " isn't reaping it."
+	}
+
+	if p.Wchan != "" {
+		return description + ", waiting in " + highlight(p.Wchan) + "."
+	}
+
+	return description + "."
+}
+
+func (u *Ui) pageStuckProcesses(stuck []processes.Process) {
+	log.Infof("Paging %d stuck processes", len(stuck))
+	err := u.screen.PauseAndCall(func() error {
+		return u.buildAndPageStuckProcesses(stuck)
+	})
+	if err != nil {
+		log.Infof("Failed to page stuck processes: %v", err)
+	} else {
+		log.Infof("Done paging stuck processes")
+	}
+}
+
+func (u *Ui) buildAndPageStuckProcesses(stuck []processes.Process) error {
+	pt := pageText{
+		borderStyle: twin.StyleDefault.WithForeground(u.theme.Border()),
+		titleStyle:  twin.StyleDefault.WithForeground(u.theme.BorderTitle()),
+	}
+
+	u.stuckProcessesForPaging(stuck, u.now(), &pt)
+
+	pt.writeLine("")
+
+	// End with a separator
+	pt.writeTitle("")
+
+	return moor.PageFromString(pt.String(), moor.Options{NoLineNumbers: true})
+}
+
+func (u *Ui) stuckProcessesForPaging(stuck []processes.Process, now time.Time, pt *pageText) {
+	pt.writeTitle("Stuck Processes")
+
+	if len(stuck) == 0 {
+		pt.writeLine("<No stuck processes right now>")
+	}
+
+	for _, p := range stuck {
+		pt.writeLine(describeStuckProcess(&p, now, u.highlight))
+	}
+
+	pt.writeLine("")
+	pt.writeLine("")
+
+	pt.writeTitle("What Does This Mean?")
+	pt.writeLine("Processes in uninterruptible sleep (state D) are waiting for the kernel,")
+	pt.writeLine("usually for IO. Hung network file systems are a common reason. They won't")
+	pt.writeLine("die from any signal until the kernel lets go of them.")
+	pt.writeLine("")
+	pt.writeLine("Zombies (state Z) have exited, but their parent hasn't asked for their exit")
+	pt.writeLine("status yet. If the parent exits, init will adopt and reap them.")
+}
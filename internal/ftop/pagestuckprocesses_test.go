@@ -0,0 +1,30 @@
+package ftop
+
+import (
+	"testing"
+	"time"
+
+	"github.com/walles/ftop/internal/assert"
+	"github.com/walles/ftop/internal/processes"
+)
+
+func TestDescribeStuckProcess(t *testing.T) {
+	// Made up processes have the zero time as their StuckSince()
+	now := time.Time{}.Add(40 * time.Second)
+	plain := func(s string) string { return s }
+
+	cp := processes.Process{Pid: 4244, Cmdline: "cp", State: "D", Wchan: "nfs_wait_bit_killable"}
+	assert.Equal(t,
+		describeStuckProcess(&cp, now, plain),
+		"cp(4244) has been in uninterruptible sleep for 40.0s, waiting in nfs_wait_bit_killable.")
+
+	dd := processes.Process{Pid: 4245, Cmdline: "dd", State: "D"}
+	assert.Equal(t,
+		describeStuckProcess(&dd, now, plain),
+		"dd(4245) has been in uninterruptible sleep for 40.0s.")
+
+	orphan := processes.Process{Pid: 4243, Cmdline: "bash", State: "Z"}
+	assert.Equal(t,
+		describeStuckProcess(&orphan, now, plain),
+		"bash(4243) has been a zombie for 40.0s, and its parent is unknown.")
+}
@@ -0,0 +1,367 @@
+package ftop
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/walles/ftop/internal/processes"
+	"github.com/walles/ftop/internal/util"
+)
+
+// Over how long the Growth column measures RAM changes
+const ramGrowthWindow = 60 * time.Second
+
+// One column the per-process table can show
+type processColumn struct {
+	// Used in the columns config file
+	id string
+
+	// Shown in the column picker
+	description string
+
+	header func(u *Ui) string
+	cell   func(u *Ui, p processes.Process) string
+
+	leftAligned bool
+
+	// If the contents of this column doesn't fit, the table isn't wide enough.
+	// For numbers mostly, which are useless when truncated.
+	mustFit bool
+
+	// When the screen is too narrow, columns with lower priorities are dropped
+	// first
+	priority int
+}
+
+// All columns we can show, in the order we show them
+var processColumns = []processColumn{
+	{
+		id:          "pid",
+		description: "Process ID",
+		header:      fixedHeader("PID"),
+		cell:        func(_ *Ui, p processes.Process) string { return fmt.Sprintf("%d", p.Pid) },
+		priority:    90,
+	},
+	{
+		id:          "ppid",
+		description: "Parent process ID",
+		header:      fixedHeader("PPID"),
+		cell:        func(_ *Ui, p processes.Process) string { return fmt.Sprintf("%d", p.Ppid()) },
+		mustFit:     true,
+		priority:    20,
+	},
+	{
+		id:          columnIdCommand,
+		description: "Command name, always shown",
+		header:      fixedHeader("Command"),
+		cell:        func(_ *Ui, p processes.Process) string { return p.Command() + p.DeduplicationSuffix },
+		leftAligned: true,
+		priority:    100,
+	},
+	{
+		id:          columnIdUser,
+		description: "Who is running the process",
+		header:      fixedHeader("Username"),
+		cell:        func(_ *Ui, p processes.Process) string { return p.Username },
+		leftAligned: true,
+		priority:    60,
+	},
+	{
+		id:          "cgroup",
+		description: "Systemd unit, container or pod, Linux only",
+		header:      fixedHeader("Cgroup"),
+		cell: func(_ *Ui, p processes.Process) string {
+			if p.Cgroup == "" {
+				return "--"
+			}
+			return p.Cgroup
+		},
+		leftAligned: true,
+		priority:    15,
+	},
+	{
+		id:          "tty",
+		description: "Controlling terminal, Linux only",
+		header:      fixedHeader("TTY"),
+		cell: func(_ *Ui, p processes.Process) string {
+			if p.Tty == "" {
+				return "--"
+			}
+			return p.Tty
+		},
+		leftAligned: true,
+		mustFit:     true,
+		priority:    15,
+	},
+	{
+		id:          columnIdState,
+		description: "R running, S sleeping, D uninterruptible, Z zombie, T stopped",
+		header:      fixedHeader("S"),
+		cell:        func(_ *Ui, p processes.Process) string { return p.State },
+		leftAligned: true,
+		mustFit:     true,
+		priority:    50,
+	},
+	{
+		id:          "nice",
+		description: "Scheduling niceness, Linux only",
+		header:      fixedHeader("NI"),
+		cell: func(_ *Ui, p processes.Process) string {
+			if p.Nice == nil {
+				return "--"
+			}
+			return fmt.Sprintf("%d", *p.Nice)
+		},
+		mustFit:  true,
+		priority: 20,
+	},
+	{
+		id:          "threads",
+		description: "Thread count, Linux only",
+		header:      fixedHeader("Threads"),
+		cell: func(_ *Ui, p processes.Process) string {
+			if p.Threads == nil {
+				return "--"
+			}
+			return fmt.Sprintf("%d", *p.Threads)
+		},
+		mustFit:  true,
+		priority: 20,
+	},
+	{
+		id:          "started",
+		description: "When the process started",
+		header:      fixedHeader("Started"),
+		cell: func(u *Ui, p processes.Process) string {
+			if u.now().Sub(p.StartTime()) < 24*time.Hour {
+				return p.StartTime().Format("15:04")
+			}
+			return p.StartTime().Format("Jan02")
+		},
+		mustFit:  true,
+		priority: 25,
+	},
+	{
+		id:          "age",
+		description: "How long ago the process started",
+		header:      fixedHeader("Age"),
+		cell:        func(u *Ui, p processes.Process) string { return util.FormatDuration(u.now().Sub(p.StartTime())) },
+		mustFit:     true,
+		priority:    25,
+	},
+	{
+		id:          "cpu",
+		description: "CPU usage percentage",
+		header:      fixedHeader("CPU"),
+		cell:        func(_ *Ui, p processes.Process) string { return p.CpuPercentString() },
+		mustFit:     true,
+		priority:    80,
+	},
+	{
+		id:          "time",
+		description: "CPU time, press c to change what it counts",
+		header:      fixedHeader("Time"),
+		cell:        func(_ *Ui, p processes.Process) string { return p.CpuTimeString() },
+		mustFit:     true,
+		priority:    70,
+	},
+	{
+		id:          "children",
+		description: "CPU time including exited children, Linux only",
+		header:      fixedHeader("+Children"),
+		cell:        func(_ *Ui, p processes.Process) string { return p.CpuTimeInclChildrenString() },
+		mustFit:     true,
+		priority:    30,
+	},
+	{
+		id:          "ram",
+		description: "RSS, or PSS after pressing m",
+		header: func(u *Ui) string {
+			if u.isShowingPss() {
+				return "PSS"
+			}
+			return "RAM"
+		},
+		cell:     func(u *Ui, p processes.Process) string { return ramString(p, u.isShowingPss()) },
+		mustFit:  true,
+		priority: 75,
+	},
+	{
+		id:          "pss",
+		description: "Proportional set size, Linux only",
+		header:      fixedHeader("PSS"),
+		cell:        func(_ *Ui, p processes.Process) string { return ramString(p, true) },
+		mustFit:     true,
+		priority:    20,
+	},
+	{
+		id:          "growth",
+		description: "RAM change during the last minute",
+		header:      fixedHeader("Growth"),
+		cell: func(u *Ui, p processes.Process) string {
+			if p.History() == nil {
+				return "--"
+			}
+			change, ok := p.History().RssKbChange(ramGrowthWindow, u.now())
+			if !ok {
+				return "--"
+			}
+			if change < 0 {
+				return "-" + util.FormatMemory(-1024*int64(change))
+			}
+			return "+" + util.FormatMemory(1024*int64(change))
+		},
+		mustFit:  true,
+		priority: 20,
+	},
+	{
+		id:          "io",
+		description: "Storage bytes read and written per second",
+		header:      fixedHeader("IO"),
+		cell:        func(_ *Ui, p processes.Process) string { return p.IoRateString() },
+		mustFit:     true,
+		priority:    40,
+	},
+	{
+		id:          "cmdline",
+		description: "Full command line",
+		header:      fixedHeader("Command Line"),
+		cell:        func(_ *Ui, p processes.Process) string { return p.Cmdline },
+		leftAligned: true,
+		priority:    10,
+	},
+}
+
+// Columns the renderer treats specially
+const (
+	columnIdCommand = "command"
+	columnIdUser    = "user"
+	columnIdState   = "state"
+)
+
+// What we show unless the columns config file says otherwise
+var defaultProcessColumnIds = []string{"pid", "command", "user", "state", "cpu", "time", "children", "ram", "io"}
+
+func fixedHeader(header string) func(u *Ui) string {
+	return func(_ *Ui) string { return header }
+}
+
+// The columns the user wants, in registry order
+func (u *Ui) processColumns() []processColumn {
+	ids := u.columnIds
+	if ids == nil {
+		ids = defaultProcessColumnIds
+	}
+
+	var columns []processColumn
+	for _, column := range processColumns {
+		if column.id == columnIdCommand || slices.Contains(ids, column.id) {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+func (u *Ui) shownColumnIds() []string {
+	var ids []string
+	for _, column := range u.processColumns() {
+		ids = append(ids, column.id)
+	}
+	return ids
+}
+
+// -1 if there's no such column
+func columnIndex(columns []processColumn, id string) int {
+	return slices.IndexFunc(columns, func(c processColumn) bool { return c.id == id })
+}
+
+// Where the column picker saves the user's choices
+func ColumnsConfigPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, "ftop", "columns"), nil
+}
+
+// Returns the column IDs listed in the file at path, or nil if there is no
+// such file.
+func ReadColumnsConfig(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	ids, err := parseColumnsConfig(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return ids, nil
+}
+
+// Show these per-process columns, nil means the defaults. Changes made using
+// the column picker are saved to configPath, unless it's empty.
+func (u *Ui) SetColumns(ids []string, configPath string) {
+	u.columnIds = ids
+	u.columnsConfigPath = configPath
+}
+
+// One column ID per line. Blank lines and lines starting with # are ignored.
+func parseColumnsConfig(reader io.Reader) ([]string, error) {
+	ids := []string{}
+	scanner := bufio.NewScanner(reader)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+
+		id := strings.TrimSpace(scanner.Text())
+		if id == "" || strings.HasPrefix(id, "#") {
+			continue
+		}
+
+		if columnIndex(processColumns, id) < 0 {
+			var known []string
+			for _, column := range processColumns {
+				known = append(known, column.id)
+			}
+			return nil, fmt.Errorf("line %d: unknown column %q, must be one of %s", lineNumber, id, strings.Join(known, ", "))
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, scanner.Err()
+}
+
+func (u *Ui) saveColumnsConfig() error {
+	if u.columnsConfigPath == "" {
+		// Nowhere to save
+		return nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# Columns to show in ftop's process list, press o in ftop to change them\n")
+	for _, id := range u.shownColumnIds() {
+		sb.WriteString(id + "\n")
+	}
+
+	err := os.MkdirAll(filepath.Dir(u.columnsConfigPath), 0o755)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(u.columnsConfigPath, []byte(sb.String()), 0o644)
+}
@@ -0,0 +1,146 @@
+package ftop
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/walles/ftop/internal/assert"
+	"github.com/walles/ftop/internal/processes"
+	"github.com/walles/ftop/internal/snapshots"
+	"github.com/walles/ftop/internal/sysload"
+	"github.com/walles/ftop/internal/themes"
+	"github.com/walles/moor/v2/twin"
+)
+
+func columnIds(columns []processColumn) []string {
+	var ids []string
+	for _, column := range columns {
+		ids = append(ids, column.id)
+	}
+	return ids
+}
+
+func TestParseColumnsConfig(t *testing.T) {
+	ids, err := parseColumnsConfig(strings.NewReader("# Comment\n\nram\n  pid  \ncmdline\n"))
+	assert.Equal(t, err, nil)
+	assert.SlicesEqual(t, ids, []string{"ram", "pid", "cmdline"})
+
+	_, err = parseColumnsConfig(strings.NewReader("pid\nbogus\n"))
+	assert.Equal(t, strings.HasPrefix(err.Error(), `line 2: unknown column "bogus"`), true)
+}
+
+func TestReadColumnsConfig_Missing(t *testing.T) {
+	ids, err := ReadColumnsConfig(filepath.Join(t.TempDir(), "columns"))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, ids == nil, true)
+}
+
+func TestProcessColumns(t *testing.T) {
+	u := Ui{}
+	assert.SlicesEqual(t, columnIds(u.processColumns()), defaultProcessColumnIds)
+
+	// Registry order, and the command column is always there
+	u.columnIds = []string{"ram", "ppid"}
+	assert.SlicesEqual(t, columnIds(u.processColumns()), []string{"ppid", "command", "ram"})
+}
+
+func TestDropLowestPriorityColumn(t *testing.T) {
+	u := Ui{columnIds: []string{"pid", "cpu", "cmdline"}}
+	columns := u.processColumns()
+
+	columns = dropLowestPriorityColumn(columns)
+	assert.SlicesEqual(t, columnIds(columns), []string{"pid", "command", "cpu"})
+
+	columns = dropLowestPriorityColumn(columns)
+	columns = dropLowestPriorityColumn(columns)
+	assert.SlicesEqual(t, columnIds(columns), []string{"command"})
+
+	columns = dropLowestPriorityColumn(columns)
+	assert.SlicesEqual(t, columnIds(columns), []string{"command"})
+}
+
+func TestRenderSingleProcessesPane_DropsColumns(t *testing.T) {
+	screen := twin.NewFakeScreen(40, 10)
+	u := NewUi(screen, themes.NewTheme("auto", nil), "")
+
+	procs := []processes.Process{
+		{Pid: 42, Cmdline: "firefox", Username: "testuser", RssKb: 1000, CpuTime: toDuration(100)},
+	}
+	u.renderSingleProcessesPane(procs, 0, 9)
+
+	assert.Equal(t, screenContainsText(screen, "Command"), true)
+	assert.Equal(t, screenContainsText(screen, "CPU"), true)
+	assert.Equal(t, screenContainsText(screen, "+Children"), false)
+}
+
+func TestColumnsPicker(t *testing.T) {
+	screen := twin.NewFakeScreen(80, 30)
+	u := NewUi(screen, themes.NewTheme("auto", nil), "")
+	configPath := filepath.Join(t.TempDir(), "ftop", "columns")
+	u.SetColumns(nil, configPath)
+
+	u.eventHandler.onRune('o')
+	u.Render(snapshots.Snapshot{
+		Sysload: sysload.Sysload{RamUsedBytes: 1024, RamTotalBytes: 4096, CpuCoresLogical: 2, CpuCoresPhysical: 1},
+	})
+	assert.Equal(t, screenContainsText(screen, "[x] PID"), true)
+	assert.Equal(t, screenContainsText(screen, "[ ] PPID"), true)
+
+	// Hide PID, show PPID
+	u.eventHandler.onRune(' ')
+	u.eventHandler.onKeyCode(twin.KeyDown)
+	u.eventHandler.onKeyCode(twin.KeyEnter)
+
+	// The command column can't be hidden
+	u.eventHandler.onKeyCode(twin.KeyDown)
+	u.eventHandler.onRune(' ')
+
+	u.eventHandler.onKeyCode(twin.KeyEscape)
+	_, isBase := u.eventHandler.(*eventHandlerBase)
+	assert.Equal(t, isBase, true)
+
+	expected := []string{"ppid", "command", "user", "state", "cpu", "time", "children", "ram", "io"}
+	assert.SlicesEqual(t, columnIds(u.processColumns()), expected)
+
+	saved, err := ReadColumnsConfig(configPath)
+	assert.Equal(t, err, nil)
+	assert.SlicesEqual(t, saved, expected)
+}
+
+func TestColumnsPicker_NoChange(t *testing.T) {
+	screen := twin.NewFakeScreen(80, 30)
+	u := NewUi(screen, themes.NewTheme("auto", nil), "")
+	configPath := filepath.Join(t.TempDir(), "ftop", "columns")
+	u.SetColumns(nil, configPath)
+
+	// Toggle PID twice, ending up where we started
+	u.eventHandler.onRune('o')
+	u.eventHandler.onRune(' ')
+	u.eventHandler.onRune(' ')
+	u.eventHandler.onRune('q')
+
+	saved, err := ReadColumnsConfig(configPath)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, saved == nil, true)
+}
+
+func TestThreeProcessPanesColumns(t *testing.T) {
+	procs := []processes.Process{
+		{Pid: 42, Cmdline: "firefox", Username: "testuser", RssKb: 1000, CpuTime: toDuration(100)},
+	}
+	extraColumnIds := append(slices.Clone(defaultProcessColumnIds), "ppid", "nice", "threads", "started")
+
+	// Low priority columns get dropped to make room for three panes
+	u := NewUi(twin.NewFakeScreen(80, 20), themes.NewTheme("auto", nil), "")
+	u.columnIds = extraColumnIds
+	assert.SlicesEqual(t,
+		columnIds(u.threeProcessPanesColumns(u.screen, procs, 0, 19)),
+		[]string{"pid", "command", "user", "state", "started", "cpu", "time", "children", "ram", "io"})
+
+	// But not the important ones
+	u = NewUi(twin.NewFakeScreen(60, 20), themes.NewTheme("auto", nil), "")
+	u.columnIds = extraColumnIds
+	assert.Equal(t, u.threeProcessPanesColumns(u.screen, procs, 0, 19) == nil, true)
+}
@@ -6,23 +6,43 @@ import (
 	"github.com/walles/ftop/internal/processes"
 )
 
-func sortProcessesForDisplay(processesRaw []processes.Process) []processes.Process {
+// If pss is true, sort by PSS rather than by RSS
+func sortProcessesForDisplay(processesRaw []processes.Process, pss bool) []processes.Process {
 	return SortByScore(processesRaw, func(p processes.Process) stats {
 		cpuTimeOrZero := time.Duration(0)
 		if p.CpuTime != nil {
 			cpuTimeOrZero = *p.CpuTime
 		}
+		cpuTimeInclChildrenOrZero := time.Duration(0)
+		if inclChildren := p.CpuTimeInclChildren(); inclChildren != nil {
+			cpuTimeInclChildrenOrZero = *inclChildren
+		}
+		ioBytesPerSecondOrZero := 0.0
+		if p.IoBytesPerSecond != nil {
+			ioBytesPerSecondOrZero = *p.IoBytesPerSecond
+		}
 		return stats{
 			// The name in this case is really a fallback sort key for when the
 			// other sort keys are all equal.
-			name:     p.Command(), // <- Run BenchmarkSortProcessesForDisplay() if you change this!
-			cpuTime:  cpuTimeOrZero,
-			rssKb:    p.RssKb,
-			nativity: p.Nativity,
+			name:                p.Command(), // <- Run BenchmarkSortProcessesForDisplay() if you change this!
+			cpuTime:             cpuTimeOrZero,
+			cpuTimeInclChildren: cpuTimeInclChildrenOrZero,
+			rssKb:               ramKb(p, pss),
+			nativity:            p.SubtreeNativity, // Rank orchestrators above their helper shells
+			ioBytesPerSecond:    ioBytesPerSecondOrZero,
 		}
 	})
 }
 
+// PSS if pss is true and we have it, RSS otherwise
+func ramKb(p processes.Process, pss bool) int {
+	if pss {
+		return p.PssOrRssKb()
+	}
+
+	return p.RssKb
+}
+
 // syncPickedProcess keeps the current pick coherent between picked line and
 // picked process for this frame.
 //
@@ -41,7 +61,7 @@ func (ui *Ui) syncPickedProcess(processesRaw []processes.Process, maxVisibleProc
 		return
 	}
 
-	processesByScore := sortProcessesForDisplay(processesRaw)
+	processesByScore := ui.sortProcesses(processesRaw)
 	if len(processesByScore) == 0 {
 		ui.pickedLine = nil
 		ui.pickedProcess = nil
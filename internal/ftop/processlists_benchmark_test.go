@@ -38,6 +38,6 @@ func BenchmarkSortProcessesForDisplay(b *testing.B) {
 	b.ResetTimer()
 
 	for b.Loop() {
-		benchmarkSortProcessesForDisplaySink = sortProcessesForDisplay(processesRaw)
+		benchmarkSortProcessesForDisplaySink = sortProcessesForDisplay(processesRaw, false)
 	}
 }
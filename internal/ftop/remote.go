@@ -0,0 +1,8 @@
+package ftop
+
+import "github.com/walles/ftop/internal/snapshots"
+
+// nil unless we are showing processes from an agent on some other machine
+func (u *Ui) remote() *snapshots.Remote {
+	return snapshots.Find[*snapshots.Remote](u.source)
+}
@@ -0,0 +1,80 @@
+package ftop
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/walles/moor/v2/twin"
+)
+
+func (u *Ui) renderColumnsUi() {
+	w, h := u.screen.Size()
+
+	picker, ok := u.eventHandler.(*eventHandlerColumns)
+	if !ok {
+		panic(fmt.Sprintf("Not a columns handler: %+v", u.eventHandler))
+	}
+
+	// One line per column, plus borders, but leave some of the screen visible
+	height := min(len(processColumns)+2, h-4)
+	width := w - 6
+
+	// Centered
+	x0 := 3
+	x1 := x0 + width - 1
+	y0 := (h - height) / 2
+	y1 := y0 + height - 1
+
+	// Clear the frame
+	for x := x0; x <= x1; x++ {
+		for y := y0; y <= y1; y++ {
+			u.screen.SetCell(x, y, twin.StyledRune{Rune: ' '})
+		}
+	}
+
+	headerWidth := 0
+	for _, column := range processColumns {
+		headerWidth = max(headerWidth, len(column.header(u)))
+	}
+
+	// Scroll so that the picked column is always visible
+	visibleRows := height - 2
+	firstVisible := max(0, picker.picked-visibleRows+1)
+
+	for i, column := range processColumns[firstVisible:] {
+		if i >= visibleRows {
+			break
+		}
+
+		// "[x] PID      Process ID"
+		checkbox := "[ ] "
+		if picker.isShown(column.id) {
+			checkbox = "[x] "
+		}
+		header := fmt.Sprintf("%-*s  ", headerWidth, column.header(u))
+
+		style := twin.StyleDefault
+		if firstVisible+i == picker.picked {
+			style = style.WithAttr(twin.AttrReverse)
+		}
+
+		y := y0 + 1 + i
+		x := x0 + 1
+		x += drawText(u.screen, x, y, x1, checkbox, style)
+		x += drawText(u.screen, x, y, x1, header, style.WithForeground(u.theme.HighlightedForeground()))
+		x += drawText(u.screen, x, y, x1, column.description, style)
+		if firstVisible+i == picker.picked {
+			// Highlight the whole line
+			drawText(u.screen, x, y, x1, strings.Repeat(" ", max(0, x1-x)), style)
+		}
+	}
+
+	renderFrame(u.screen, u.theme, x0, y0, x1, y1, "Columns")
+
+	// "Space toggle  Quit" in the upper right corner
+	x := x1 - (len("Space toggle  Quit") + 2)
+	x += drawText(u.screen, x, y0, x1, "Space", u.theme.PromptKey())
+	x += drawText(u.screen, x, y0, x1, " toggle  ", u.theme.PromptActive())
+	x += u.screen.SetCell(x, y0, twin.StyledRune{Rune: 'Q', Style: u.theme.PromptKey()})
+	drawText(u.screen, x, y0, x1, "uit", u.theme.PromptActive())
+}
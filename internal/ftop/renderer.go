@@ -1,11 +1,14 @@
 package ftop
 
 import (
+	"slices"
 	"time"
 
 	"github.com/walles/ftop/internal/io"
 	"github.com/walles/ftop/internal/log"
 	"github.com/walles/ftop/internal/processes"
+	"github.com/walles/ftop/internal/snapshots"
+	"github.com/walles/ftop/internal/sysload"
 	"github.com/walles/ftop/internal/themes"
 	"github.com/walles/moor/v2/twin"
 )
@@ -20,7 +23,23 @@ type stats struct {
 	name     string
 	cpuTime  time.Duration
 	nativity int
-	rssKb    int
+
+	// CPU time including exited children, zero if unknown. Scored instead of
+	// cpuTime when we have it, see cpuScoreTime().
+	cpuTimeInclChildren time.Duration
+
+	rssKb            int // RSS, or PSS if that's what we're showing, see aggregate()
+	ioBytesPerSecond float64
+
+	// Showing PSS, but some processes in this group had none. rssKb is the
+	// sum of the ones that had.
+	ramPartial bool
+}
+
+// Orchestrators like make burn their CPU through children, count that CPU as
+// theirs
+func (s stats) cpuScoreTime() time.Duration {
+	return max(s.cpuTime, s.cpuTimeInclChildren)
 }
 
 type userStats struct {
@@ -31,16 +50,26 @@ type commandStats struct {
 	stats
 }
 
-func (u *Ui) Render(processesRaw []processes.Process, ioStats []io.Stat, launches *processes.LaunchNode) {
+// The snapshot's processes should already be filtered
+func (u *Ui) Render(snapshot snapshots.Snapshot) {
 	const overviewHeight = 5 // Including borders
 
+	u.snapshotTime = snapshot.Timestamp
+	processesRaw := u.applyCpuWindow(snapshot.Processes)
+	launches := snapshot.Launches
+
 	width, height := u.screen.Size()
 	if width < minWidth || height < minHeight {
 		renderTooSmallScreen(u.screen, u.theme)
 		return
 	}
 
+	// Before syncing the pick, since that depends on how we sort
+	u.pssAvailable = slices.ContainsFunc(processesRaw, func(p processes.Process) bool { return p.PssKb != nil })
+
 	u.syncPickedProcess(processesRaw, -1)
+	u.stuckProcesses = findStuckProcesses(processesRaw)
+	u.cgroupsAvailable = slices.ContainsFunc(processesRaw, func(p processes.Process) bool { return p.Cgroup != "" })
 
 	ioStatsWidth := 25                    // Including borders
 	overviewWidth := width - ioStatsWidth // Including borders
@@ -86,18 +115,18 @@ func (u *Ui) Render(processesRaw []processes.Process, ioStats []io.Stat, launche
 
 	u.screen.Clear()
 
-	renderOverview(u.screen, u.theme, ioStats, overviewWidth)
+	renderOverview(u.screen, u.theme, snapshot.Sysload, snapshot.IoStats, overviewWidth, u.overviewTitle(snapshot))
 
 	// Draw IO stats to the right of the overview...
 	if ioStatsWidth > 0 {
 		// ... but only when there is room for it.
-		renderIoTopList(u.screen, u.theme, ioStats, overviewWidth, 0, width-1, 4)
+		renderIoTopList(u.screen, u.theme, snapshot.IoStats, overviewWidth, 0, width-1, 4)
 	}
 
 	if width < u.minThreePanesScreenWidth {
 		u.renderSingleProcessesPane(processesRaw, overviewHeight, processesBottomRow)
-	} else if u.canRenderThreeProcessPanes(u.screen, processesRaw, overviewHeight, processesBottomRow) {
-		u.renderThreeProcessPanes(processesRaw, overviewHeight, processesBottomRow)
+	} else if columns := u.threeProcessPanesColumns(u.screen, processesRaw, overviewHeight, processesBottomRow); columns != nil {
+		u.renderThreeProcessPanes(columns, processesRaw, overviewHeight, processesBottomRow)
 	} else {
 		u.renderSingleProcessesPane(processesRaw, overviewHeight, processesBottomRow)
 
@@ -129,15 +158,46 @@ func (u *Ui) Render(processesRaw []processes.Process, ioStats []io.Stat, launche
 		u.renderKillUi(nextToScreenRow)
 	}
 
+	if _, isPickingColumns := u.eventHandler.(*eventHandlerColumns); isPickingColumns {
+		u.renderColumnsUi()
+	}
+
 	u.screen.Show()
 }
 
-func renderOverview(screen twin.Screen, theme themes.Theme, ioStats []io.Stat, overviewWidth int) {
-	renderSysload(screen, theme, overviewWidth)
-	renderMemoryUsage(screen, theme, overviewWidth)
+// Example return values:
+//
+//	Overview
+//	Overview, viewing 00:42 ago
+//	Overview of webserver, viewing 00:42 ago
+//	Overview of webserver, disconnected: connection closed
+//	Replay 2026-10-17 Sat 03:12:45CEST [43/3600] paused
+func (u *Ui) overviewTitle(snapshot snapshots.Snapshot) string {
+	if replay := u.replay(); replay != nil {
+		return replayTitle(replay, snapshot)
+	}
+
+	title := "Overview"
+	if remote := u.remote(); remote != nil {
+		title += " of " + remote.Hostname()
+		if err := remote.Err(); err != nil {
+			title += ", disconnected: " + err.Error()
+		}
+	}
+
+	if rewinder := u.rewinder(); rewinder != nil && rewinder.IsRewound() {
+		title += ", viewing " + formatAgo(time.Since(snapshot.Timestamp)) + " ago"
+	}
+
+	return title
+}
+
+func renderOverview(screen twin.Screen, theme themes.Theme, load sysload.Sysload, ioStats []io.Stat, overviewWidth int, title string) {
+	renderSysload(screen, theme, load, overviewWidth)
+	renderMemoryUsage(screen, theme, load, overviewWidth)
 	renderIOLoad(screen, theme, ioStats, overviewWidth)
 
-	renderFrame(screen, theme, 0, 0, overviewWidth-1, 4, "Overview")
+	renderFrame(screen, theme, 0, 0, overviewWidth-1, 4, title)
 
 	// Draw "Quit" prompt in upper right corner
 	x := overviewWidth - (len("Quit") + 2)
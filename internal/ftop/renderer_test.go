@@ -6,6 +6,8 @@ import (
 
 	"github.com/walles/ftop/internal/assert"
 	"github.com/walles/ftop/internal/processes"
+	"github.com/walles/ftop/internal/snapshots"
+	"github.com/walles/ftop/internal/sysload"
 	"github.com/walles/ftop/internal/themes"
 	"github.com/walles/moor/v2/twin"
 )
@@ -22,7 +24,10 @@ func TestRender_ShowsProcessInfoPaneImmediatelyFromPickedLine(t *testing.T) {
 		{Pid: 42, Cmdline: "picked", Username: "testuser", RssKb: 1000, CpuTime: toDuration(100)},
 	}
 
-	ui.Render(processesRaw, nil, nil)
+	ui.Render(snapshots.Snapshot{
+		Processes: processesRaw,
+		Sysload:   sysload.Sysload{RamUsedBytes: 1024, RamTotalBytes: 4096, CpuCoresLogical: 2, CpuCoresPhysical: 1},
+	})
 
 	if ui.pickedProcess == nil {
 		t.Fatalf("expected pickedProcess to be resolved during render")
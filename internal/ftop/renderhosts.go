@@ -0,0 +1,209 @@
+package ftop
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/walles/ftop/internal/processes"
+	"github.com/walles/ftop/internal/snapshots"
+	"github.com/walles/ftop/internal/ui"
+	"github.com/walles/moor/v2/twin"
+)
+
+// How many of each host's top processes to list
+const hostTopProcessCount = 3
+
+// What we show about one host in the multi-host overview
+type hostRow struct {
+	name string
+
+	// Set if we have nothing to show, the rest of the fields are empty then
+	problem     string
+	problemIsOk bool // True if we're just waiting, false if something failed
+
+	load         string // "1.2 / 8 cores" for example
+	loadFraction float64
+	ram          string // "43%" for example
+	ramFraction  float64
+	io           string // The busiest device, "sda 12kB/s" for example
+	top          string // The top processes, comma separated
+}
+
+func (u *Ui) renderHosts() {
+	width, height := u.screen.Size()
+	if width < minWidth || height < minHeight {
+		renderTooSmallScreen(u.screen, u.theme)
+		return
+	}
+
+	u.screen.Clear()
+
+	rows := []hostRow{}
+	for _, conn := range u.hosts {
+		rows = append(rows, u.toHostRow(conn))
+	}
+
+	nameWidth := len("Host")
+	loadWidth := len("Load")
+	ramWidth := len("RAM")
+	ioWidth := len("IO")
+	for _, row := range rows {
+		nameWidth = max(nameWidth, len([]rune(row.name)))
+		loadWidth = max(loadWidth, len([]rune(row.load)))
+		ramWidth = max(ramWidth, len([]rune(row.ram)))
+		ioWidth = max(ioWidth, len([]rune(row.io)))
+	}
+	ramWidth = max(ramWidth, 6) // Make room for some memory bar
+
+	x0 := 1
+	x1 := width - 1 // Exclusive, that's where the right border is
+	loadX := x0 + nameWidth + 2
+	ramX := loadX + loadWidth + 2
+	ioX := ramX + ramWidth + 2
+	topX := ioX + ioWidth + 2
+
+	headerStyle := twin.StyleDefault.WithForeground(u.theme.Foreground()).WithAttr(twin.AttrBold)
+	drawText(u.screen, x0, 1, x1, "Host", headerStyle)
+	drawText(u.screen, loadX, 1, x1, "Load", headerStyle)
+	drawText(u.screen, ramX, 1, x1, "RAM", headerStyle)
+	drawText(u.screen, ioX, 1, x1, "IO", headerStyle)
+	drawText(u.screen, topX, 1, x1, "Top Processes", headerStyle)
+
+	cpuBar := ui.NewLoadBar(loadX, loadX+loadWidth-1, ui.NewColorRamp(0.0, 1.0, u.theme.LoadBarMin(), u.theme.LoadBarMaxCpu()))
+	ramBar := ui.NewLoadBar(ramX, ramX+ramWidth-1, ui.NewColorRamp(0.0, 1.0, u.theme.LoadBarMin(), u.theme.LoadBarMaxRam()))
+
+	style := twin.StyleDefault.WithForeground(u.theme.Foreground())
+	for i, row := range rows {
+		y := 2 + i
+		if y >= height-1 {
+			// FIXME: Scroll when there are too many hosts to fit on screen
+			break
+		}
+
+		drawText(u.screen, x0, y, x1, row.name, style.WithAttr(twin.AttrBold))
+
+		if row.problem != "" {
+			problemStyle := twin.StyleDefault.WithForeground(u.theme.WarningForeground())
+			if row.problemIsOk {
+				problemStyle = twin.StyleDefault.WithForeground(u.theme.FadedForeground())
+			}
+			drawText(u.screen, loadX, y, x1, row.problem, problemStyle)
+		} else {
+			drawText(u.screen, loadX, y, x1, row.load, style)
+			drawText(u.screen, ramX, y, x1, row.ram, style)
+			drawText(u.screen, ioX, y, x1, row.io, style)
+			drawText(u.screen, topX, y, x1, row.top, style)
+		}
+
+		if i == u.pickedHost {
+			// Picked host, highlight it! No load bars, they would mess up the
+			// highlighting.
+			for x := x0; x < x1; x++ {
+				cell := u.screen.GetCell(x, y)
+				u.screen.SetCell(x, y, twin.StyledRune{Rune: cell.Rune, Style: twin.StyleDefault.WithAttr(twin.AttrReverse)})
+			}
+			continue
+		}
+
+		if row.problem != "" {
+			continue
+		}
+		for x := loadX; x < loadX+loadWidth && x < x1; x++ {
+			cpuBar.SetCellBackground(u.screen, x, y, row.loadFraction)
+		}
+		for x := ramX; x < ramX+ramWidth && x < x1; x++ {
+			ramBar.SetCellBackground(u.screen, x, y, row.ramFraction)
+		}
+	}
+
+	const title = "Hosts"
+	renderFrame(u.screen, u.theme, 0, 0, width-1, height-1, title)
+	u.renderHostsHints(2+len(title)+3, 0, width-1)
+
+	// Draw "Quit" prompt in upper right corner
+	x := width - (len("Quit") + 2)
+	x += u.screen.SetCell(x, 0, twin.StyledRune{Rune: 'Q', Style: u.theme.PromptKey()})
+	drawText(u.screen, x, 0, width-1, "uit", u.theme.PromptActive())
+
+	u.screen.Show()
+}
+
+func (u *Ui) renderHostsHints(x0 int, y int, x1 int) {
+	x := x0
+
+	upStyle := u.theme.PromptKey()
+	if u.pickedHost == 0 {
+		upStyle = u.theme.PromptPassive()
+	}
+	downStyle := u.theme.PromptKey()
+	if u.pickedHost >= len(u.hosts)-1 {
+		downStyle = u.theme.PromptPassive()
+	}
+	x += u.screen.SetCell(x, y, twin.StyledRune{Rune: '↓', Style: downStyle})
+	x += drawText(u.screen, x, y, x1, "Pick", u.theme.PromptActive())
+	x += u.screen.SetCell(x, y, twin.StyledRune{Rune: '↑', Style: upStyle})
+	x += 3
+
+	showStyle := u.theme.PromptActive()
+	enterStyle := u.theme.PromptKey()
+	if u.hosts[u.pickedHost].lastRemote() == nil {
+		// Nothing to show yet
+		showStyle = u.theme.PromptPassive()
+		enterStyle = u.theme.PromptPassive()
+	}
+	x += drawText(u.screen, x, y, x1, "Enter", enterStyle)
+	drawText(u.screen, x, y, x1, " to show", showStyle)
+}
+
+func (u *Ui) toHostRow(conn *hostConnection) hostRow {
+	snapshot := conn.snapshot()
+	snapshot.Processes = processes.Filter(snapshot.Processes, u.filter)
+
+	return newHostRow(conn.host.Name, snapshot, conn.problem())
+}
+
+// An empty snapshot means we haven't heard from the host yet. A non-nil
+// problem means we can't hear from it right now.
+func newHostRow(name string, snapshot snapshots.Snapshot, problem error) hostRow {
+	row := hostRow{name: name}
+
+	if problem != nil {
+		// Agent command errors include whatever it said on stderr, which can
+		// be multiple lines
+		row.problem = strings.Join(strings.Fields(problem.Error()), " ")
+		return row
+	}
+	if snapshot.Timestamp.IsZero() {
+		row.problem = "Connecting..."
+		row.problemIsOk = true
+		return row
+	}
+
+	load := snapshot.Sysload
+	row.load = fmt.Sprintf("%.1f / %d cores", load.LoadAverage1M, load.CpuCoresPhysical)
+	if load.CpuCoresLogical > 0 {
+		row.loadFraction = load.LoadAverage1M / float64(load.CpuCoresLogical)
+	}
+	if load.RamTotalBytes > 0 {
+		row.ramFraction = float64(load.RamUsedBytes) / float64(load.RamTotalBytes)
+	}
+	row.ram = fmt.Sprintf("%.0f%%", row.ramFraction*100.0)
+
+	ioStats := slices.Clone(snapshot.IoStats)
+	sortIoStats(ioStats)
+	if len(ioStats) > 0 {
+		row.io = ioStats[0].DeviceName + " " + ioRateString(ioStats[0])
+	}
+
+	var top []string
+	for _, p := range sortProcessesForDisplay(snapshot.Processes, false) {
+		if len(top) >= hostTopProcessCount {
+			break
+		}
+		top = append(top, p.Command())
+	}
+	row.top = strings.Join(top, ", ")
+
+	return row
+}
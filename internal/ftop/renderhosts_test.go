@@ -0,0 +1,54 @@
+package ftop
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/walles/ftop/internal/assert"
+	"github.com/walles/ftop/internal/io"
+	"github.com/walles/ftop/internal/processes"
+	"github.com/walles/ftop/internal/snapshots"
+	"github.com/walles/ftop/internal/sysload"
+)
+
+func TestNewHostRow(t *testing.T) {
+	row := newHostRow("webserver", snapshots.Snapshot{
+		Timestamp: time.Now(),
+		Processes: []processes.Process{
+			{Pid: 1, Cmdline: "idle", CpuTime: toDuration(1)},
+			{Pid: 2, Cmdline: "busy", CpuTime: toDuration(60)},
+			{Pid: 3, Cmdline: "busier", CpuTime: toDuration(90)},
+			{Pid: 4, Cmdline: "medium", CpuTime: toDuration(30)},
+		},
+		IoStats: []io.Stat{
+			{DeviceName: "quiet", BytesPerSecond: 1, HighWatermark: 1},
+			{DeviceName: "busy", BytesPerSecond: 2048, HighWatermark: 4096},
+		},
+		Sysload: sysload.Sysload{
+			LoadAverage1M:    2.0,
+			CpuCoresPhysical: 2,
+			CpuCoresLogical:  4,
+			RamUsedBytes:     1,
+			RamTotalBytes:    4,
+		},
+	}, nil)
+
+	assert.Equal(t, row.problem, "")
+	assert.Equal(t, row.load, "2.0 / 2 cores")
+	assert.Equal(t, row.loadFraction, 0.5)
+	assert.Equal(t, row.ram, "25%")
+	assert.Equal(t, row.ramFraction, 0.25)
+	assert.Equal(t, row.io, "busy 2.0kB/s")
+	assert.Equal(t, row.top, "busier, busy, medium")
+}
+
+func TestNewHostRow_Problems(t *testing.T) {
+	connecting := newHostRow("webserver", snapshots.Snapshot{}, nil)
+	assert.Equal(t, connecting.problem, "Connecting...")
+	assert.Equal(t, connecting.problemIsOk, true)
+
+	failed := newHostRow("webserver", snapshots.Snapshot{}, errors.New("ssh: Could not resolve\n  hostname webserver"))
+	assert.Equal(t, failed.problem, "ssh: Could not resolve hostname webserver")
+	assert.Equal(t, failed.problemIsOk, false)
+}
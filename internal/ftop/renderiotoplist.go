@@ -13,7 +13,8 @@ import (
 	"github.com/walles/moor/v2/twin"
 )
 
-func renderIoTopList(screen twin.Screen, theme themes.Theme, ioStats []io.Stat, x0, y0, x1, y1 int) {
+// Busiest device first, in place
+func sortIoStats(ioStats []io.Stat) {
 	slices.SortFunc(ioStats, func(s1, s2 io.Stat) int {
 		comparison := cmp.Compare(s1.HighWatermark, s2.HighWatermark)
 		if comparison != 0 {
@@ -23,6 +24,15 @@ func renderIoTopList(screen twin.Screen, theme themes.Theme, ioStats []io.Stat,
 		// For great stability at the bottom of the list
 		return cmp.Compare(s1.DeviceName, s2.DeviceName)
 	})
+}
+
+// Example return value: "12kB/s"
+func ioRateString(stat io.Stat) string {
+	return strings.TrimSuffix(util.FormatMemory(int64(stat.BytesPerSecond)), "B") + "B/s"
+}
+
+func renderIoTopList(screen twin.Screen, theme themes.Theme, ioStats []io.Stat, x0, y0, x1, y1 int) {
+	sortIoStats(ioStats)
 
 	firstIoLine := y0 + 1 // Screen row number
 	lastIoLine := y1 - 1  // Screen row number
@@ -36,8 +46,6 @@ func renderIoTopList(screen twin.Screen, theme themes.Theme, ioStats []io.Stat,
 			break
 		}
 
-		bpsStringWithTrailingB := strings.TrimSuffix(util.FormatMemory(int64(stat.BytesPerSecond)), "B") + "B/s"
-
 		paddedDeviceName := fmt.Sprintf("%-7s ", stat.DeviceName)
 		x := x0 + 1
 		x += drawText(
@@ -52,7 +60,7 @@ func renderIoTopList(screen twin.Screen, theme themes.Theme, ioStats []io.Stat,
 			x,
 			y,
 			x1,
-			fmt.Sprintf("%7s", bpsStringWithTrailingB),
+			fmt.Sprintf("%7s", ioRateString(stat)),
 			twin.StyleDefault.WithForeground(topBottomRamp.AtInt(y)),
 		)
 	}
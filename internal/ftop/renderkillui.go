@@ -2,11 +2,11 @@ package ftop
 
 import (
 	"fmt"
-	"syscall"
 	"time"
 
 	"github.com/walles/ftop/internal/ui"
 	"github.com/walles/moor/v2/twin"
+	"golang.org/x/sys/unix"
 )
 
 // NOTE: The framerate of the progress bar is controlled by deathPollFramerate
@@ -15,9 +15,18 @@ import (
 func (u *Ui) renderKillUi(nextToScreenRow int) {
 	w, h := u.screen.Size()
 
+	killer, ok := u.eventHandler.(*eventHandlerKill)
+	if !ok {
+		panic(fmt.Sprintf("Not a kill handler: %+v", u.eventHandler))
+	}
+
 	// Dialog dimensions
 	width := w - 6
 	height := 5 // 3 content lines + 2 border lines
+	if killer.isAwaitingConfirmation() {
+		// Room for the scope picker
+		height += 2
+	}
 
 	// Horizontal positioning (centered)
 	x0 := 3
@@ -56,11 +65,6 @@ func (u *Ui) renderKillUi(nextToScreenRow int) {
 		drawText(u.screen, x, y, x1, "uit", u.theme.PromptActive())
 	}()
 
-	killer, ok := u.eventHandler.(*eventHandlerKill)
-	if !ok {
-		panic(fmt.Sprintf("Not a kill handler: %+v", u.eventHandler))
-	}
-
 	excuse := killer.getExcuse()
 	if excuse != "" {
 		// We have some excuse, tell the user the kill failed
@@ -70,12 +74,41 @@ func (u *Ui) renderKillUi(nextToScreenRow int) {
 		// "Press any key to continue."
 		x := x0 + 1
 		y := y0 + 1
-		x += drawText(u.screen, x, y, x1, "Failed to kill "+killer.process.String()+": ", twin.StyleDefault)
+		verb := "kill "
+		if killer.picked != nil && !killer.picked.waitsForDeath {
+			verb = "send SIG" + killer.picked.name() + " to "
+		}
+		x += drawText(u.screen, x, y, x1, "Failed to "+verb+killer.describeTargets()+": ", twin.StyleDefault)
 		drawText(u.screen, x, y, x1,
 			excuse,
 			twin.StyleDefault.WithForeground(u.theme.HighlightedForeground()),
 		)
 
+		x = x0 + 1
+		y += 2
+		x += drawText(u.screen, x, y, x1, "Press ", u.theme.PromptActive())
+		if killer.canRetryWithSudo() {
+			// "Press s to retry with sudo, or any other key to continue."
+			x += drawText(u.screen, x, y, x1, string(sudoRetryKey), u.theme.PromptKey())
+			x += drawText(u.screen, x, y, x1, " to retry with sudo, or ", u.theme.PromptActive())
+			x += drawText(u.screen, x, y, x1, "any other key", u.theme.PromptKey())
+		} else {
+			x += drawText(u.screen, x, y, x1, "any key", u.theme.PromptKey())
+		}
+		drawText(u.screen, x, y, x1, " to continue.", u.theme.PromptActive())
+		return
+	}
+
+	if killer.isDone() {
+		// "Sent SIGHUP to nginx(1234)."
+		// ""
+		// "Press any key to continue."
+		x := x0 + 1
+		y := y0 + 1
+		x += drawText(u.screen, x, y, x1, "Sent SIG"+killer.picked.name()+" to ", twin.StyleDefault)
+		x += drawText(u.screen, x, y, x1, killer.describeTargets(), twin.StyleDefault.WithForeground(u.theme.HighlightedForeground()))
+		drawText(u.screen, x, y, x1, ".", twin.StyleDefault)
+
 		x = x0 + 1
 		y += 2
 		x += drawText(u.screen, x, y, x1, "Press ", u.theme.PromptActive())
@@ -91,20 +124,13 @@ func (u *Ui) renderKillUi(nextToScreenRow int) {
 		x := x0 + 1
 		y := y0 + 1
 		x += drawText(u.screen, x, y, x1, "Killing ", twin.StyleDefault)
-		x += drawText(u.screen, x, y, x1, killer.process.String(), twin.StyleDefault.WithForeground(u.theme.HighlightedForeground()))
+		x += drawText(u.screen, x, y, x1, killer.describeTargets(), twin.StyleDefault.WithForeground(u.theme.HighlightedForeground()))
 		drawText(u.screen, x, y, x1, "...", twin.StyleDefault)
 
 		y += 2
 		x = x0 + 1
 		signal := killer.GetLastSignal()
-		name := signal.String()
-		switch *signal {
-		case syscall.SIGKILL:
-			name = "SIGKILL"
-		case syscall.SIGTERM:
-			name = "SIGTERM"
-		}
-		drawText(u.screen, x, y, x1, name, twin.StyleDefault)
+		drawText(u.screen, x, y, x1, unix.SignalName(*signal), twin.StyleDefault)
 
 		loadBarRamp := ui.NewColorRamp(0.0, 1.0, u.theme.Background(), u.theme.HighlightedForeground())
 		loadBar := ui.NewBackwardsLoadBar(x0+1, x1-1, loadBarRamp)
@@ -120,20 +146,71 @@ func (u *Ui) renderKillUi(nextToScreenRow int) {
 		return
 	}
 
+	if killer.signalName != nil {
+		// "Signal name: USR2"
+		// ""
+		// "Press Enter to send it to launchd(1)."
+		x := x0 + 1
+		y := y0 + 1
+		x += drawText(u.screen, x, y, x1, "Signal name: ", u.theme.PromptActive())
+		x += drawText(u.screen, x, y, x1, *killer.signalName, twin.StyleDefault.WithForeground(u.theme.HighlightedForeground()))
+		u.screen.SetCell(x, y, twin.StyledRune{Rune: '_', Style: u.theme.PromptKey()})
+
+		x = x0 + 1
+		y += 2
+		x += drawText(u.screen, x, y, x1, "Press ", u.theme.PromptActive())
+		x += drawText(u.screen, x, y, x1, "Enter", u.theme.PromptKey())
+		x += drawText(u.screen, x, y, x1, " to send it to ", u.theme.PromptActive())
+		x += drawText(u.screen, x, y, x1, killer.describeTargets(), twin.StyleDefault.WithForeground(u.theme.HighlightedForeground()))
+		drawText(u.screen, x, y, x1, ".", u.theme.PromptActive())
+		return
+	}
+
 	// Kill not attempted yet, tell user we are awaiting confirmation
 
 	// "Press k to kill launchd(1)."
-	x := x0 + 3
-	y := y0 + 2
+	// ""
+	// "Or send h HUP  i INT  s STOP  c CONT  u USR1  9 KILL  n by name"
+	x := x0 + 1
+	y := y0 + 1
 	x += drawText(u.screen, x, y, x1, "Press ", u.theme.PromptActive())
-	x += drawText(u.screen, x, y, x1, "k", u.theme.PromptKey())
+	x += drawText(u.screen, x, y, x1, string(killSignals[0].key), u.theme.PromptKey())
 	x += drawText(u.screen, x, y, x1, " to kill ", u.theme.PromptActive())
 	x += drawText(u.screen, x, y, x1,
-		killer.process.String(),
+		killer.describeTargets(),
 		twin.StyleDefault.WithForeground(u.theme.HighlightedForeground()),
 	)
 	u.screen.SetCell(x, y, twin.StyledRune{
 		Rune:  '.',
 		Style: u.theme.PromptActive(),
 	})
+
+	x = x0 + 1
+	y += 2
+	x += drawText(u.screen, x, y, x1, "Or send", u.theme.PromptActive())
+	for _, signal := range killSignals[1:] {
+		x += drawText(u.screen, x, y, x1, "  ", u.theme.PromptActive())
+		x += drawText(u.screen, x, y, x1, string(signal.key), u.theme.PromptKey())
+		x += drawText(u.screen, x, y, x1, " "+signal.name(), u.theme.PromptActive())
+	}
+	x += drawText(u.screen, x, y, x1, "  ", u.theme.PromptActive())
+	x += drawText(u.screen, x, y, x1, string(signalByNameKey), u.theme.PromptKey())
+	drawText(u.screen, x, y, x1, " by name", u.theme.PromptActive())
+
+	// "Signal  p Process  t Tree  g Group  a All", with the current scope
+	// highlighted
+	x = x0 + 1
+	y += 2
+	x += drawText(u.screen, x, y, x1, "Signal", u.theme.PromptActive())
+	for _, scope := range killScopes {
+		x += drawText(u.screen, x, y, x1, "  ", u.theme.PromptActive())
+		x += drawText(u.screen, x, y, x1, string(scope.key()), u.theme.PromptKey())
+
+		nameStyle := u.theme.PromptPassive()
+		if scope == killer.scope {
+			nameStyle = u.theme.PromptActive().WithAttr(twin.AttrReverse)
+		}
+		x += drawText(u.screen, x, y, x1, " ", u.theme.PromptActive())
+		x += drawText(u.screen, x, y, x1, scope.name(), nameStyle)
+	}
 }
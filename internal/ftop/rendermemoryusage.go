@@ -11,13 +11,7 @@ import (
 	"github.com/walles/moor/v2/twin"
 )
 
-func renderMemoryUsage(screen twin.Screen, theme themes.Theme, width int) {
-	sysload, err := sysload.GetSysload()
-	if err != nil {
-		// FIXME: Handle this better. What would the user want here?
-		panic(err)
-	}
-
+func renderMemoryUsage(screen twin.Screen, theme themes.Theme, sysload sysload.Sysload, width int) {
 	ramUsePercent := float64(sysload.RamUsedBytes) / float64(sysload.RamTotalBytes) * 100.0
 	description := fmt.Sprintf("RAM Use: %.0f%%  [%sB / %sB]",
 		ramUsePercent,
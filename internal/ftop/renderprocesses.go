@@ -2,6 +2,8 @@ package ftop
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/walles/ftop/internal/processes"
 	"github.com/walles/ftop/internal/themes"
@@ -10,23 +12,43 @@ import (
 	"github.com/walles/moor/v2/twin"
 )
 
-func (u *Ui) canRenderThreeProcessPanes(screen twin.Screen, processesRaw []processes.Process, y0 int, y1 int) bool {
+// Columns below this priority are worth less than the per-user and per-command
+// panes, so we'd rather drop them than fall back to a single pane
+const threePanesMinColumnPriority = 50
+
+// The per-process columns to show next to the per-user and per-command panes,
+// or nil if the screen is too narrow for three panes
+func (u *Ui) threeProcessPanesColumns(screen twin.Screen, processesRaw []processes.Process, y0 int, y1 int) []processColumn {
 	// Including borders. If they are the same, the height is still 1.
 	renderHeight := y1 - y0 + 1
 
-	// -2 for borders, they won't be part of the table
-	table, _, _, _, _ := u.createProcessesTable(processesRaw, renderHeight-2)
-
 	width, _ := screen.Size()
 
-	// -2 for borders, -5 for column dividers, -2 for the two borders between
-	// sections and -2 for column dividers in the right section
-	availableToColumns := width - 2 - 5 - 2 - 2
+	columns := u.processColumns()
+	for {
+		// -2 for borders, they won't be part of the table
+		table, _, _, _, _ := u.createProcessesTable(columns, processesRaw, renderHeight-2)
 
-	// Don't grow the PID column, that looks weird
-	widths := ui.ColumnWidths(table, availableToColumns, false)
+		// -2 for borders, column dividers between the per-process columns, -2
+		// for the two borders between sections and -2 for column dividers in
+		// the right section
+		availableToColumns := width - 2 - (len(columns) - 1) - 2 - 2
+
+		// Don't grow the PID column, that looks weird
+		widths := ui.ColumnWidths(table, availableToColumns, false)
 
-	return isWideEnough(table, widths)
+		if isWideEnough(columns, table, widths) {
+			return columns
+		}
+
+		lowest := lowestPriorityColumn(columns)
+		if lowest < 0 || columns[lowest].priority >= threePanesMinColumnPriority {
+			// Rather one pane than three without this column
+			return nil
+		}
+
+		columns = dropLowestPriorityColumn(columns)
+	}
 }
 
 // Render the three sections: per-process (on the left), per-user (top right),
@@ -34,38 +56,46 @@ func (u *Ui) canRenderThreeProcessPanes(screen twin.Screen, processesRaw []proce
 //
 // y0 and y1 are screen rows and are both inclusive. Borders will be drawn on
 // those rows.
-func (u *Ui) renderThreeProcessPanes(processesRaw []processes.Process, y0 int, y1 int) {
+func (u *Ui) renderThreeProcessPanes(columns []processColumn, processesRaw []processes.Process, y0 int, y1 int) {
 	// Including borders. If they are the same, the height is still 1.
 	renderHeight := y1 - y0 + 1
 
 	// -2 for borders, they won't be part of the table
-	table, usersHeight, processes, users, commands := u.createProcessesTable(processesRaw, renderHeight-2)
+	table, usersHeight, processes, users, commands := u.createProcessesTable(columns, processesRaw, renderHeight-2)
 
 	width, _ := u.screen.Size()
 
-	// -2 for borders, -5 for column dividers, -2 for the two borders between
-	// sections and -2 for column dividers in the right section
-	availableToColumns := width - 2 - 5 - 2 - 2
+	// -2 for borders, column dividers between the per-process columns, -2 for
+	// the two borders between sections and -2 for column dividers in the right
+	// section
+	availableToColumns := width - 2 - (len(columns) - 1) - 2 - 2
 
 	// Don't grow the PID column, that looks weird
 	widths := ui.ColumnWidths(table, availableToColumns, false)
 
-	perProcessTableWidth := widths[0] + 1 + widths[1] + 1 + widths[2] + 1 + widths[3] + 1 + widths[4] + 1 + widths[5]
+	perProcessTableWidth := len(columns) - 1 // Column dividers
+	for _, width := range widths[:len(columns)] {
+		perProcessTableWidth += width
+	}
 	rightPerProcessBorderColumn := perProcessTableWidth + 1    // Screen column. +1 for the left frame line.
 	leftPerUserBorderColumn := rightPerProcessBorderColumn + 1 // Screen column
 
 	usersBottomBorder := y0 + 1 + usersHeight
 	commandsTopRow := usersBottomBorder + 1
 
-	pickedUsername := ""
+	pickedGroup := ""
 	pickedCommand := ""
 	if u.pickedProcess != nil {
-		pickedUsername = u.pickedProcess.Username
+		pickedGroup = u.perUserPaneGroup(*u.pickedProcess)
 		pickedCommand = u.pickedProcess.Command()
 	}
 
-	u.renderProcesses(0, y0, rightPerProcessBorderColumn, y1, table, widths, processes)
-	renderPerUser(u.screen, u.theme, leftPerUserBorderColumn, y0, width-1, usersBottomBorder, table, widths, users, pickedUsername)
+	u.renderProcesses(0, y0, rightPerProcessBorderColumn, y1, columns, table, widths, processes)
+	if u.pickedProcess != nil {
+		u.renderProcessHistory(leftPerUserBorderColumn, y0, width-1, usersBottomBorder)
+	} else {
+		u.renderPerUser(leftPerUserBorderColumn, y0, width-1, usersBottomBorder, table, widths, users, pickedGroup)
+	}
 
 	// Skip the per-user rows. If usersHeight is 0:
 	// 0: post-users separator line
@@ -77,18 +107,25 @@ func (u *Ui) renderThreeProcessPanes(processesRaw []processes.Process, y0 int, y
 	renderPerCommand(u.screen, u.theme, leftPerUserBorderColumn, commandsTopRow, width-1, y1, table, widths, commands, pickedCommand)
 }
 
-func isWideEnough(table [][]string, widths []int) bool {
-	columnsThatMustFit := []int{
-		3, // CPU
-		4, // Time
-		5, // RAM
-		7, // User / Command Time
-		8, // User / Command RAM
+// The table can be either just the per-process columns, or the per-process
+// columns followed by the three per-user / per-command columns.
+func isWideEnough(columns []processColumn, table [][]string, widths []int) bool {
+	columnsThatMustFit := []int{}
+	for i, column := range columns {
+		if column.mustFit {
+			columnsThatMustFit = append(columnsThatMustFit, i)
+		}
+	}
+	if len(widths) > len(columns) {
+		columnsThatMustFit = append(columnsThatMustFit,
+			len(columns)+1, // User / Command Time
+			len(columns)+2, // User / Command RAM
+		)
 	}
 
 	for rowIndex, row := range table {
 		for _, colIndex := range columnsThatMustFit {
-			if rowIndex == 0 && colIndex < 6 {
+			if rowIndex == 0 && colIndex < len(columns) {
 				// Header row, doesn't need to fit
 				continue
 			}
@@ -107,24 +144,59 @@ func (u *Ui) renderSingleProcessesPane(processesRaw []processes.Process, y0 int,
 	// Including borders. If they are the same, the height is still 1.
 	renderHeight := y1 - y0 + 1
 
-	// -2 for borders, they won't be part of the table
-	table, _, processes, _, _ := u.createProcessesTable(processesRaw, renderHeight-2)
+	width, _ := u.screen.Size()
 
-	// Drop the three rightmost columns (per-user and per-command) from the
-	// table
-	for rowIndex, row := range table {
-		table[rowIndex] = row[:6]
+	columns := u.processColumns()
+	for {
+		// -2 for borders, they won't be part of the table
+		table, _, processes, _, _ := u.createProcessesTable(columns, processesRaw, renderHeight-2)
+
+		// Drop the three rightmost columns (per-user and per-command) from the
+		// table
+		for rowIndex, row := range table {
+			table[rowIndex] = row[:len(columns)]
+		}
+
+		// -2 for borders, then column dividers
+		availableToColumns := width - 2 - (len(columns) - 1)
+
+		// Don't grow the PID column, that looks weird
+		widths := ui.ColumnWidths(table, availableToColumns, false)
+
+		narrower := dropLowestPriorityColumn(columns)
+		if len(narrower) == len(columns) || isWideEnough(columns, table, widths) {
+			u.renderProcesses(0, y0, width-1, y1, columns, table, widths, processes)
+			return
+		}
+
+		columns = narrower
 	}
+}
 
-	width, _ := u.screen.Size()
+// Returns columns unchanged if only the command column is left
+func dropLowestPriorityColumn(columns []processColumn) []processColumn {
+	lowest := lowestPriorityColumn(columns)
+	if lowest < 0 {
+		return columns
+	}
 
-	// -2 for borders, -5 for column dividers
-	availableToColumns := width - 2 - 5
+	return slices.Delete(slices.Clone(columns), lowest, lowest+1)
+}
 
-	// Don't grow the PID column, that looks weird
-	widths := ui.ColumnWidths(table, availableToColumns, false)
+// Index of the column to drop first, or -1 if only the command column is left
+func lowestPriorityColumn(columns []processColumn) int {
+	lowest := -1
+	for i, column := range columns {
+		if column.id == columnIdCommand {
+			// Never drop this one
+			continue
+		}
+		if lowest < 0 || column.priority < columns[lowest].priority {
+			lowest = i
+		}
+	}
 
-	u.renderProcesses(0, y0, width-1, y1, table, widths, processes)
+	return lowest
 }
 
 // Render three tables and combine them: per-process (on the left), per-user
@@ -134,7 +206,7 @@ func (u *Ui) renderSingleProcessesPane(processesRaw []processes.Process, y0 int,
 // the per-user section.
 //
 // processesHeight is the height of the table, without borders
-func (u *Ui) createProcessesTable(processesRaw []processes.Process, processesHeight int) (
+func (u *Ui) createProcessesTable(columns []processColumn, processesRaw []processes.Process, processesHeight int) (
 	[][]string,
 	int,
 	[]processes.Process,
@@ -144,14 +216,12 @@ func (u *Ui) createProcessesTable(processesRaw []processes.Process, processesHei
 	usersHeight := processesHeight/2 - 1
 	commandsHeight := processesHeight - usersHeight
 
-	procsHeaders := []string{
-		"PID", "Command", "Username", "CPU", "Time", "RAM",
-	}
+	procsHeaders := u.processesHeaders(columns)
 
 	procsTable := [][]string{
 		procsHeaders,
 	}
-	processesByScore := sortProcessesForDisplay(processesRaw)
+	processesByScore := u.sortProcesses(processesRaw)
 
 	processesByScore = u.fixPickedProcess(processesByScore)
 
@@ -160,27 +230,13 @@ func (u *Ui) createProcessesTable(processesRaw []processes.Process, processesHei
 			break
 		}
 
-		row := []string{
-			fmt.Sprintf("%d", p.Pid),
-			p.Command() + p.DeduplicationSuffix,
-			p.Username,
-			p.CpuPercentString(),
-			p.CpuTimeString(),
-			util.FormatMemory(int64(p.RssKb) * 1024),
-		}
-
-		procsTable = append(procsTable, row)
+		procsTable = append(procsTable, u.processRow(columns, p))
 	}
 	for len(procsTable) < processesHeight {
 		procsTable = append(procsTable, make([]string, len(procsHeaders)))
 	}
 
-	users := aggregate(processesRaw, func(p processes.Process) string { return p.Username }, func(stat stats) userStats {
-		return userStats{stats: stat}
-	})
-	users = SortByScore(users, func(u userStats) stats {
-		return u.stats
-	})
+	users := u.aggregateUsers(processesRaw)
 
 	usersTable := [][]string{}
 	for _, u := range users {
@@ -191,7 +247,7 @@ func (u *Ui) createProcessesTable(processesRaw []processes.Process, processesHei
 		row := []string{
 			u.name,
 			util.FormatDuration(u.cpuTime),
-			util.FormatMemory(1024 * int64(u.rssKb)),
+			statsRamString(u.stats),
 		}
 
 		usersTable = append(usersTable, row)
@@ -200,12 +256,7 @@ func (u *Ui) createProcessesTable(processesRaw []processes.Process, processesHei
 		usersTable = append(usersTable, make([]string, 3))
 	}
 
-	commands := aggregate(processesRaw, func(p processes.Process) string { return p.Command() }, func(stat stats) commandStats {
-		return commandStats{stats: stat}
-	})
-	commands = SortByScore(commands, func(b commandStats) stats {
-		return b.stats
-	})
+	commands := aggregateCommands(processesRaw, u.sortMode, u.isShowingPss())
 
 	commandsTable := [][]string{}
 	for _, b := range commands {
@@ -216,7 +267,7 @@ func (u *Ui) createProcessesTable(processesRaw []processes.Process, processesHei
 		row := []string{
 			b.name,
 			util.FormatDuration(b.cpuTime),
-			util.FormatMemory(1024 * int64(b.rssKb)),
+			statsRamString(b.stats),
 		}
 
 		commandsTable = append(commandsTable, row)
@@ -256,6 +307,77 @@ func (u *Ui) createProcessesTable(processesRaw []processes.Process, processesHei
 	return combinedTable, len(usersTable), processesByScore, users, commands
 }
 
+func (u *Ui) processesHeaders(columns []processColumn) []string {
+	headers := make([]string, 0, len(columns))
+	for _, column := range columns {
+		headers = append(headers, column.header(u))
+	}
+	return headers
+}
+
+// One row of the per-process table, matching processesHeaders()
+func (u *Ui) processRow(columns []processColumn, p processes.Process) []string {
+	row := make([]string, 0, len(columns))
+	for _, column := range columns {
+		row = append(row, column.cell(u, p))
+	}
+	return row
+}
+
+// Per-user (or per-cgroup) stats, top list first
+func (u *Ui) aggregateUsers(processesRaw []processes.Process) []userStats {
+	users := aggregate(processesRaw, u.isShowingPss(), u.perUserPaneGroup, func(stat stats) userStats {
+		return userStats{stats: stat}
+	})
+	return sortStats(users, u.sortMode, func(u userStats) stats {
+		return u.stats
+	})
+}
+
+// Per-command stats, top list first
+func aggregateCommands(processesRaw []processes.Process, sortMode SortMode, pss bool) []commandStats {
+	commands := aggregate(processesRaw, pss, func(p processes.Process) string { return p.Command() }, func(stat stats) commandStats {
+		return commandStats{stats: stat}
+	})
+	return sortStats(commands, sortMode, func(b commandStats) stats {
+		return b.stats
+	})
+}
+
+// Showing PSS only makes sense if we have PSS info
+func (u *Ui) isShowingPss() bool {
+	return u.showPss && u.pssAvailable
+}
+
+// RSS or PSS, with an arrow after it if the process looks like it's leaking
+// memory
+func ramString(p processes.Process, pss bool) string {
+	ram := util.FormatMemory(int64(p.RssKb) * 1024)
+	if pss {
+		ram = "--"
+		if p.PssKb != nil {
+			ram = util.FormatMemory(int64(*p.PssKb) * 1024)
+		}
+	}
+	if p.MemoryTrend().IsLeakSuspect {
+		ram += "↑"
+	}
+	return ram
+}
+
+// A "+" after the number means some processes had no PSS, so the real total is
+// higher
+func statsRamString(stat stats) string {
+	if !stat.ramPartial {
+		return util.FormatMemory(1024 * int64(stat.rssKb))
+	}
+	if stat.rssKb == 0 {
+		// No PSS for any of these
+		return "--"
+	}
+	return util.FormatMemory(1024*int64(stat.rssKb)) + "+"
+}
+
 // Will provide cells covering at least width screen columns
 func renderCommand(command string, deduplicationSuffix string, width int, textColor twin.Color) []twin.StyledRune {
 	result := make([]twin.StyledRune, 0, width)
@@ -287,17 +409,18 @@ func renderCommand(command string, deduplicationSuffix string, width int, textCo
 	return result
 }
 
-func (u *Ui) renderProcesses(x0, y0, x1, y1 int, table [][]string, widths []int, procs []processes.Process) {
+func (u *Ui) renderProcesses(x0, y0, x1, y1 int, columns []processColumn, table [][]string, widths []int, procs []processes.Process) {
 	// Formats are "%5.5s" or "%-5.5s", where "5.5" means "pad and truncate to
 	// 5", and the "-" means left-align.
-	formatString := fmt.Sprintf("%%%d.%ds %%-%d.%ds %%-%d.%ds %%%d.%ds %%%d.%ds %%%d.%ds",
-		widths[0], widths[0],
-		widths[1], widths[1],
-		widths[2], widths[2],
-		widths[3], widths[3],
-		widths[4], widths[4],
-		widths[5], widths[5],
-	)
+	formats := make([]string, 0, len(columns))
+	for i, column := range columns {
+		if column.leftAligned {
+			formats = append(formats, fmt.Sprintf("%%-%d.%ds", widths[i], widths[i]))
+		} else {
+			formats = append(formats, fmt.Sprintf("%%%d.%ds", widths[i], widths[i]))
+		}
+	}
+	formatString := strings.Join(formats, " ")
 
 	memoryRamp := ui.NewColorRamp(0.0, 1.0, u.theme.LoadBarMin(), u.theme.LoadBarMaxRam())
 	cpuRamp := ui.NewColorRamp(0.0, 1.0, u.theme.LoadBarMin(), u.theme.LoadBarMaxCpu())
@@ -305,12 +428,18 @@ func (u *Ui) renderProcesses(x0, y0, x1, y1 int, table [][]string, widths []int,
 	// +2 = ignore top border and the header line
 	topBottomRamp := ui.NewColorRamp(float64(y0+2), float64(y1-1), u.theme.Foreground(), u.theme.FadedForeground())
 
-	userColumn0 := x0 + 1 + widths[0] + 1 + widths[1] // Screen column
-	userColumnN := userColumn0 + widths[2]            // Screen column
+	commandIndex := columnIndex(columns, columnIdCommand)
+	commandColumn0, commandColumnN := columnScreenRange(x0, widths, commandIndex)
+
+	userIndex := columnIndex(columns, columnIdUser)
+	userColumn0, userColumnN := columnScreenRange(x0, widths, userIndex)
+	if userIndex > 0 {
+		// Style the divider before the user column as well
+		userColumn0--
+	}
 	currentUsername := util.GetCurrentUsername()
 
-	commandColumn0 := x0 + 1 + widths[0] + 1         // Screen column. x0 + 1 for left border, then PID column, then a space separator
-	commandColumnN := commandColumn0 + widths[1] - 1 // Screen column
+	stateColumn0, stateColumnN := columnScreenRange(x0, widths, columnIndex(columns, columnIdState))
 
 	// +2 = ignore top border and the header line
 	userRamp := ui.NewColorRamp(float64(y0+2), float64(y1-1), u.theme.HighlightedForeground(), u.theme.FadedForeground())
@@ -321,8 +450,8 @@ func (u *Ui) renderProcesses(x0, y0, x1, y1 int, table [][]string, widths []int,
 		if p.CpuTime != nil && p.CpuTime.Seconds() > maxCpuSecondsPerProcess {
 			maxCpuSecondsPerProcess = p.CpuTime.Seconds()
 		}
-		if p.RssKb > maxRssKbPerProcess {
-			maxRssKbPerProcess = p.RssKb
+		if ramKb(p, u.isShowingPss()) > maxRssKbPerProcess {
+			maxRssKbPerProcess = ramKb(p, u.isShowingPss())
 		}
 	}
 
@@ -339,9 +468,11 @@ func (u *Ui) renderProcesses(x0, y0, x1, y1 int, table [][]string, widths []int,
 	//
 
 	for rowIndex, row := range table {
-		line := fmt.Sprintf(formatString,
-			row[0], row[1], row[2], row[3], row[4], row[5],
-		)
+		cells := make([]any, 0, len(columns))
+		for _, cell := range row[:len(columns)] {
+			cells = append(cells, cell)
+		}
+		line := fmt.Sprintf(formatString, cells...)
 
 		var process *processes.Process
 		if rowIndex > 0 && rowIndex-1 < len(procs) {
@@ -354,7 +485,11 @@ func (u *Ui) renderProcesses(x0, y0, x1, y1 int, table [][]string, widths []int,
 		shouldHighlightCommand := false
 		shouldHighlightUser := false
 		if process != nil {
-			commandCells = renderCommand(process.Command(), process.DeduplicationSuffix, widths[1], userRamp.AtInt(y))
+			commandColor := userRamp.AtInt(y)
+			if process.IsStuck() {
+				commandColor = u.theme.WarningForeground()
+			}
+			commandCells = renderCommand(process.Command(), process.DeduplicationSuffix, widths[commandIndex], commandColor)
 
 			thisIsThePickedProcess := u.pickedLine != nil && *u.pickedLine == rowIndex-1
 			commandIsSameAsPicked := u.pickedProcess != nil && process.Command() == u.pickedProcess.Command()
@@ -368,9 +503,9 @@ func (u *Ui) renderProcesses(x0, y0, x1, y1 int, table [][]string, widths []int,
 			}
 		} else {
 			// No process on this line, cover the command column with empty cells
-			commandCells = make([]twin.StyledRune, 0, widths[1])
+			commandCells = make([]twin.StyledRune, 0, widths[commandIndex])
 			space := twin.StyledRune{Rune: ' ', Style: twin.StyleDefault.WithForeground(userRamp.AtInt(y))}
-			for len(commandCells) < widths[1] {
+			for len(commandCells) < widths[commandIndex] {
 				commandCells = append(commandCells, space)
 			}
 		}
@@ -395,7 +530,7 @@ func (u *Ui) renderProcesses(x0, y0, x1, y1 int, table [][]string, widths []int,
 				char = commandCells[x-commandColumn0]
 			} else if rowIndex > 0 && x >= userColumn0 && x <= userColumnN {
 				// User column
-				username := row[2]
+				username := row[userIndex]
 				if shouldHighlightUser {
 					char.Style = twin.StyleDefault.WithAttr(twin.AttrReverse)
 				} else if username == "root" && currentUsername != "root" {
@@ -403,6 +538,11 @@ func (u *Ui) renderProcesses(x0, y0, x1, y1 int, table [][]string, widths []int,
 				} else if username != currentUsername {
 					char.Style = char.Style.WithAttr(twin.AttrBold)
 				}
+			} else if rowIndex > 0 && x >= stateColumn0 && x <= stateColumnN {
+				// State column
+				if process != nil && process.IsStuck() {
+					char.Style = twin.StyleDefault.WithForeground(u.theme.WarningForeground()).WithAttr(twin.AttrBold)
+				}
 			}
 
 			if u.pickedLine != nil && *u.pickedLine == rowIndex-1 {
@@ -444,7 +584,7 @@ func (u *Ui) renderProcesses(x0, y0, x1, y1 int, table [][]string, widths []int,
 				}
 				memFraction := 0.0
 				if maxRssKbPerProcess > 0 {
-					memFraction = float64(process.RssKb) / float64(maxRssKbPerProcess)
+					memFraction = float64(ramKb(*process, u.isShowingPss())) / float64(maxRssKbPerProcess)
 				}
 				perProcessCpuAndMemBar.SetCellBackground(u.screen, x, y, cpuFraction, memFraction)
 			}
@@ -453,7 +593,13 @@ func (u *Ui) renderProcesses(x0, y0, x1, y1 int, table [][]string, widths []int,
 		}
 	}
 
-	const byProcess = "By Process"
+	byProcess := "By Process"
+	if description := u.sortMode.description(); description != "" {
+		byProcess += ", " + description
+	}
+	if description := u.cpuWindowDescription(); description != "" {
+		byProcess += ", " + description
+	}
 	renderFrame(u.screen, u.theme, x0, y0, x1, y1, byProcess)
 
 	pickUpArrow := u.pickedLine != nil
@@ -466,7 +612,40 @@ func (u *Ui) renderProcesses(x0, y0, x1, y1 int, table [][]string, widths []int,
 
 	u.renderHeaderHints(x0+2+len(byProcess)+3, y0, x1-2, pickDownArrow, pickUpArrow)
 
-	renderLegend(u.screen, u.theme, y1, x1)
+	legendX := renderLegend(u.screen, u.theme, y1, x1)
+	u.renderStuckHint(x0+2, y1, legendX-2)
+}
+
+// First and last screen columns of the column at index, given that the table
+// has its left border at x0. Returns an empty range if index is negative.
+func columnScreenRange(x0 int, widths []int, index int) (int, int) {
+	if index < 0 {
+		return -1, -2
+	}
+
+	column0 := x0 + 1 // Skip the left border
+	for _, width := range widths[:index] {
+		column0 += width + 1 // +1 for the divider
+	}
+	return column0, column0 + widths[index] - 1
+}
+
+// Tell the user about stuck processes, and how to learn more about them.
+// Renders nothing if there are no stuck processes or if there is no room.
+func (u *Ui) renderStuckHint(x0 int, y int, x1 int) {
+	if len(u.stuckProcesses) == 0 {
+		return
+	}
+
+	count := fmt.Sprintf(" %d stuck, press ", len(u.stuckProcesses))
+	if x0+len(count)+len("z ")-1 > x1 {
+		return
+	}
+
+	x := x0
+	x += drawText(u.screen, x, y, x1, count, twin.StyleDefault.WithForeground(u.theme.WarningForeground()))
+	x += u.screen.SetCell(x, y, twin.StyledRune{Rune: 'z', Style: u.theme.PromptKey()})
+	u.screen.SetCell(x, y, twin.StyledRune{Rune: ' ', Style: twin.StyleDefault})
 }
 
 // If some user name is very common (over half of the processes), return it.
@@ -489,7 +668,9 @@ func getOverHalfUsername(procs []processes.Process) string {
 
 // Towards the right, draw "CPU" with a CPU load bar behind it, and "RAM" with a
 // RAM load bar behind it.
-func renderLegend(screen twin.Screen, theme themes.Theme, y int, rightFrameBorder int) {
+//
+// Returns the leftmost screen column of the legend.
+func renderLegend(screen twin.Screen, theme themes.Theme, y int, rightFrameBorder int) int {
 	// Turn up the bottom color this much so it's visible in the small legend
 	const adjustUp = 0.5
 
@@ -533,4 +714,6 @@ func renderLegend(screen twin.Screen, theme themes.Theme, y int, rightFrameBorde
 	memLoadBar.SetCellBackground(screen, legendX+barsOffset+4, y, 1.0)
 	memLoadBar.SetCellBackground(screen, legendX+barsOffset+5, y, 1.0)
 	memLoadBar.SetCellBackground(screen, legendX+barsOffset+6, y, 1.0)
+
+	return legendX
 }
@@ -11,8 +11,8 @@ import (
 
 func TestCreateProcessTable(t *testing.T) {
 	sortedProcs := []processes.Process{
-		{Pid: 6, Cmdline: "six", Username: "six", RssKb: 60, CpuTime: toDuration(60)},
-		{Pid: 5, Cmdline: "five", Username: "five", RssKb: 50, CpuTime: toDuration(50)},
+		{Pid: 6, Cmdline: "six", Username: "six", State: "R", RssKb: 60, CpuTime: toDuration(60), ChildrenCpuTime: toDuration(30)},
+		{Pid: 5, Cmdline: "five", Username: "five", State: "D", RssKb: 50, CpuTime: toDuration(50)},
 		{Pid: 4, Cmdline: "four", Username: "four", RssKb: 40, CpuTime: toDuration(40)},
 		{Pid: 3, Cmdline: "three", Username: "three", RssKb: 30, CpuTime: toDuration(30)},
 		{Pid: 2, Cmdline: "two", Username: "two", RssKb: 20, CpuTime: toDuration(20)},
@@ -20,12 +20,12 @@ func TestCreateProcessTable(t *testing.T) {
 	}
 
 	var u Ui
-	table, usersHeight, returnedSortedProcs, users, commands := u.createProcessesTable(sortedProcs, 6)
+	table, usersHeight, returnedSortedProcs, users, commands := u.createProcessesTable(u.processColumns(), sortedProcs, 6)
 
 	assert.Equal(t, usersHeight, 2) // Header line + 1 user line
 	assert.Equal(t, reflect.DeepEqual(returnedSortedProcs, sortedProcs), true)
 	assert.SlicesEqual(t, commands, []commandStats{
-		{stats{name: "six", cpuTime: 60000000000, rssKb: 60}},
+		{stats{name: "six", cpuTime: 60000000000, cpuTimeInclChildren: 90000000000, rssKb: 60}},
 		{stats{name: "five", cpuTime: 50000000000, rssKb: 50}},
 		{stats{name: "four", cpuTime: 40000000000, rssKb: 40}},
 		{stats{name: "three", cpuTime: 30000000000, rssKb: 30}},
@@ -33,7 +33,7 @@ func TestCreateProcessTable(t *testing.T) {
 		{stats{name: "one", cpuTime: 10000000000, rssKb: 10}},
 	})
 	assert.SlicesEqual(t, users, []userStats{
-		{stats{name: "six", cpuTime: 60000000000, rssKb: 60}},
+		{stats{name: "six", cpuTime: 60000000000, cpuTimeInclChildren: 90000000000, rssKb: 60}},
 		{stats{name: "five", cpuTime: 50000000000, rssKb: 50}},
 		{stats{name: "four", cpuTime: 40000000000, rssKb: 40}},
 		{stats{name: "three", cpuTime: 30000000000, rssKb: 30}},
@@ -42,15 +42,79 @@ func TestCreateProcessTable(t *testing.T) {
 	})
 
 	assert.Equal(t, reflect.DeepEqual(table, [][]string{
-		{"PID", "Command", "Username", "CPU", "Time", "RAM", "six", "1m00s", "60k"},
-		{"6", "six", "six", "--", "1m00s", "60k", "five", "50.0s", "50k"},
-		{"5", "five", "five", "--", "50.0s", "50k", "", "", ""},
-		{"4", "four", "four", "--", "40.0s", "40k", "", "", ""},
-		{"3", "three", "three", "--", "30.0s", "30k", "six", "1m00s", "60k"},
-		{"2", "two", "two", "--", "20.0s", "20k", "five", "50.0s", "50k"},
+		{"PID", "Command", "Username", "S", "CPU", "Time", "+Children", "RAM", "IO", "six", "1m00s", "60k"},
+		{"6", "six", "six", "R", "--", "1m00s", "1m30s", "60k", "--", "five", "50.0s", "50k"},
+		{"5", "five", "five", "D", "--", "50.0s", "--", "50k", "--", "", "", ""},
+		{"4", "four", "four", "", "--", "40.0s", "--", "40k", "--", "", "", ""},
+		{"3", "three", "three", "", "--", "30.0s", "--", "30k", "--", "six", "1m00s", "60k"},
+		{"2", "two", "two", "", "--", "20.0s", "--", "20k", "--", "five", "50.0s", "50k"},
 	}), true)
 }
 
+func TestCreateProcessTable_GroupByCgroup(t *testing.T) {
+	procs := []processes.Process{
+		{Pid: 3, Cmdline: "nginx", Username: "www", Cgroup: "nginx.service", RssKb: 30, CpuTime: toDuration(30)},
+		{Pid: 2, Cmdline: "nginx", Username: "root", Cgroup: "nginx.service", RssKb: 20, CpuTime: toDuration(20)},
+		{Pid: 1, Cmdline: "init", Username: "root", RssKb: 10, CpuTime: toDuration(10)},
+	}
+
+	u := Ui{groupByCgroup: true, cgroupsAvailable: true}
+	_, _, _, users, _ := u.createProcessesTable(u.processColumns(), procs, 6)
+
+	assert.SlicesEqual(t, users, []userStats{
+		{stats{name: "nginx.service", cpuTime: 50000000000, rssKb: 50}},
+		{stats{name: "-", cpuTime: 10000000000, rssKb: 10}},
+	})
+
+	// Without any cgroup info, we should fall back to grouping by user
+	u.cgroupsAvailable = false
+	_, _, _, users, _ = u.createProcessesTable(u.processColumns(), procs, 6)
+
+	assert.SlicesEqual(t, users, []userStats{
+		{stats{name: "root", cpuTime: 30000000000, rssKb: 30}},
+		{stats{name: "www", cpuTime: 30000000000, rssKb: 30}},
+	})
+}
+
+func TestCreateProcessTable_Pss(t *testing.T) {
+	pss := 5
+	procs := []processes.Process{
+		// Lots of shared memory, so low PSS
+		{Pid: 2, Cmdline: "worker", Username: "www", RssKb: 50, PssKb: &pss, CpuTime: toDuration(10)},
+
+		// Another user's process, PSS unknown
+		{Pid: 1, Cmdline: "init", Username: "root", RssKb: 30, CpuTime: toDuration(10)},
+	}
+
+	// Aggregates should count the same thing as the RAM column, never a mix
+	u := Ui{pssAvailable: true}
+	table, _, sorted, users, _ := u.createProcessesTable(u.processColumns(), procs, 4)
+	assert.SlicesEqual(t, users, []userStats{
+		{stats{name: "www", cpuTime: 10000000000, rssKb: 50}},
+		{stats{name: "root", cpuTime: 10000000000, rssKb: 30}},
+	})
+	assert.Equal(t, table[0][7], "RAM")
+	assert.Equal(t, sorted[0].Pid, 2)
+	assert.Equal(t, table[1][7], "50k")
+
+	u.showPss = true
+	table, _, sorted, users, _ = u.createProcessesTable(u.processColumns(), procs, 4)
+	assert.SlicesEqual(t, users, []userStats{
+		{stats{name: "www", cpuTime: 10000000000, rssKb: 5}},
+		{stats{name: "root", cpuTime: 10000000000, ramPartial: true}},
+	})
+	assert.Equal(t, table[0][7], "PSS")
+	assert.Equal(t, sorted[0].Pid, 1)
+	assert.Equal(t, table[1][7], "--")
+	assert.Equal(t, table[2][7], "5.0k")
+}
+
+func TestStatsRamString(t *testing.T) {
+	assert.Equal(t, statsRamString(stats{rssKb: 5}), "5.0k")
+	assert.Equal(t, statsRamString(stats{rssKb: 5, ramPartial: true}), "5.0k+")
+	assert.Equal(t, statsRamString(stats{ramPartial: true}), "--")
+}
+
 func toDuration(seconds int) *time.Duration {
 	d := time.Duration(seconds) * time.Second
 	return &d
@@ -9,7 +9,7 @@ import (
 )
 
 func renderPerCommand(screen twin.Screen, theme themes.Theme, x0, y0, x1, y1 int, table [][]string, widths []int, commands []commandStats, pickedCommand string) {
-	widths = widths[6:] // Skip the per-process columns
+	widths = widths[len(widths)-3:] // Skip the per-process columns
 
 	// Formats are "%5.5s" or "%-5.5s", where "5.5" means "pad and truncate to
 	// 5", and the "-" means left-align.
@@ -54,7 +54,7 @@ func renderPerCommand(screen twin.Screen, theme themes.Theme, x0, y0, x1, y1 int
 			break
 		}
 
-		row = row[6:] // Skip the per-process columns
+		row = row[len(row)-3:] // Skip the per-process columns
 		line := fmt.Sprintf(formatString,
 			row[0], row[1], row[2],
 		)
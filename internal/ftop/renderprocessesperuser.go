@@ -3,14 +3,36 @@ package ftop
 import (
 	"fmt"
 
-	"github.com/walles/ftop/internal/themes"
+	"github.com/walles/ftop/internal/processes"
 	"github.com/walles/ftop/internal/ui"
 	"github.com/walles/ftop/internal/util"
 	"github.com/walles/moor/v2/twin"
 )
 
-func renderPerUser(screen twin.Screen, theme themes.Theme, x0, y0, x1, y1 int, table [][]string, widths []int, users []userStats, pickedUsername string) {
-	widths = widths[6:] // Skip the per-process columns
+// Which group in the per-user pane does this process belong to?
+func (u *Ui) perUserPaneGroup(p processes.Process) string {
+	if !u.isGroupingByCgroup() {
+		return p.Username
+	}
+
+	if p.Cgroup == "" {
+		return "-"
+	}
+	return p.Cgroup
+}
+
+// Grouping by cgroup only makes sense if we have cgroup info
+func (u *Ui) isGroupingByCgroup() bool {
+	return u.groupByCgroup && u.cgroupsAvailable
+}
+
+// Renders processes grouped by user, or by cgroup if the user has asked for
+// that.
+func (u *Ui) renderPerUser(x0, y0, x1, y1 int, table [][]string, widths []int, users []userStats, pickedGroup string) {
+	screen := u.screen
+	theme := u.theme
+
+	widths = widths[len(widths)-3:] // Skip the per-process columns
 
 	// Formats are "%5.5s" or "%-5.5s", where "5.5" means "pad and truncate to
 	// 5", and the "-" means left-align.
@@ -29,6 +51,10 @@ func renderPerUser(screen twin.Screen, theme themes.Theme, x0, y0, x1, y1 int, t
 	usernameColumn0 := x0 + 1                          // Screen column
 	usernameColumnN := usernameColumn0 + widths[0] - 1 // Screen column
 	currentUsername := util.GetCurrentUsername()
+	if u.isGroupingByCgroup() {
+		// No usernames in this pane, nothing to bold
+		currentUsername = ""
+	}
 
 	// If y0 = 0 and y1 = 1, then there would be 0 content rows between the
 	// borders
@@ -59,7 +85,7 @@ func renderPerUser(screen twin.Screen, theme themes.Theme, x0, y0, x1, y1 int, t
 			break
 		}
 
-		row = row[6:] // Skip the per-process columns
+		row = row[len(row)-3:] // Skip the per-process columns
 		line := fmt.Sprintf(formatString,
 			row[0], row[1], row[2],
 		)
@@ -69,7 +95,7 @@ func renderPerUser(screen twin.Screen, theme themes.Theme, x0, y0, x1, y1 int, t
 		rowStyle := twin.StyleDefault.WithForeground(topBottomRamp.AtInt(y))
 
 		username := row[0]
-		isPicked := pickedUsername != "" && username == pickedUsername
+		isPicked := pickedGroup != "" && username == pickedGroup
 
 		x := x0 + 1 // screen column
 		for _, char := range line {
@@ -101,5 +127,29 @@ func renderPerUser(screen twin.Screen, theme themes.Theme, x0, y0, x1, y1 int, t
 		}
 	}
 
-	renderFrame(screen, theme, x0, y0, x1, y1, "By User")
+	title := "By User"
+	if u.isGroupingByCgroup() {
+		title = "By Cgroup"
+	}
+	renderFrame(screen, theme, x0, y0, x1, y1, title)
+
+	if u.cgroupsAvailable {
+		u.renderGroupPrompt(x0+2+len(title)+3, y0, x1-2)
+	}
+}
+
+// Tell the user about the 'g' key for switching between grouping by user and
+// by cgroup
+func (u *Ui) renderGroupPrompt(x0 int, y int, x1 int) {
+	if x0+len("group")-1 > x1 {
+		// No room
+		return
+	}
+
+	x := x0
+	x += u.screen.SetCell(x, y, twin.StyledRune{
+		Style: u.theme.PromptKey(),
+		Rune:  'g',
+	})
+	drawText(u.screen, x, y, x1, "roup", u.theme.PromptActive())
 }
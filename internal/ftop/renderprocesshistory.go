@@ -0,0 +1,124 @@
+package ftop
+
+import (
+	"math"
+	"slices"
+
+	"github.com/walles/ftop/internal/processes"
+	"github.com/walles/ftop/internal/ui"
+	"github.com/walles/ftop/internal/util"
+	"github.com/walles/moor/v2/twin"
+)
+
+// Render CPU and RAM charts for the picked process. Shown in place of the
+// per-user pane while a process is picked.
+//
+// Coordinates are screen coordinates and are all inclusive. Borders will be
+// drawn on them.
+func (u *Ui) renderProcessHistory(x0, y0, x1, y1 int) {
+	if u.pickedProcess == nil {
+		panic("no process picked")
+	}
+
+	defer renderFrame(u.screen, u.theme, x0, y0, x1, y1, "History")
+
+	// Inside the borders
+	height := y1 - y0 - 1
+	if height < 1 || x1-x0-1 < 1 {
+		return
+	}
+
+	var samples []processes.HistorySample
+	if history := u.pickedProcess.History(); history != nil {
+		samples = history.Samples()
+	}
+
+	cpuPercents := historyToCpuPercents(samples)
+	cpuLabel := "CPU"
+	if len(cpuPercents) > 0 {
+		peak := slices.Max(cpuPercents)
+		cpuLabel = "CPU now " + util.FormatPercent(cpuPercents[len(cpuPercents)-1]) + ", peak " + util.FormatPercent(peak)
+	}
+
+	rssKbs := make([]float64, 0, len(samples))
+	for _, sample := range samples {
+		rssKbs = append(rssKbs, float64(sample.RssKb))
+	}
+	ramLabel := "RAM"
+	if len(rssKbs) > 0 {
+		peak := slices.Max(rssKbs)
+		ramLabel = "RAM now " + util.FormatMemory(1024*int64(rssKbs[len(rssKbs)-1])) + ", peak " + util.FormatMemory(1024*int64(peak))
+	}
+
+	// Scale CPU to at least 100%, so that idle processes look idle
+	cpuHeight := height / 2
+	if cpuHeight > 0 {
+		u.renderHistoryChart(x0+1, y0+1, x1-1, y0+cpuHeight, cpuLabel, cpuPercents, 100.0)
+	}
+	u.renderHistoryChart(x0+1, y0+1+cpuHeight, x1-1, y1-1, ramLabel, rssKbs, 0.0)
+}
+
+// Draw a label on the top row and a braille chart of the most recent values
+// below it, with the newest value to the right. If there is only one row, skip
+// the label.
+//
+// The chart is scaled to fit the largest value, or minScale if that's larger.
+func (u *Ui) renderHistoryChart(x0, y0, x1, y1 int, label string, values []float64, minScale float64) {
+	style := twin.StyleDefault.WithForeground(u.theme.Foreground())
+
+	if y1 > y0 {
+		drawText(u.screen, x0, y0, x1+1, label, style)
+		y0++
+	}
+
+	chartRows := y1 - y0 + 1
+	chartColumns := x1 - x0 + 1
+
+	// Two values per braille character
+	values = values[max(0, len(values)-2*chartColumns):]
+
+	scale := minScale
+	for _, value := range values {
+		scale = max(scale, value)
+	}
+	if scale <= 0 {
+		// Avoid division by zero
+		scale = 1
+	}
+
+	maxDots := chartRows * ui.BrailleDotsPerRow
+	dots := make([]int, len(values))
+	for i, value := range values {
+		dots[i] = int(math.Round(float64(maxDots) * value / scale))
+		if value > 0 && dots[i] == 0 {
+			// Show that there's something there
+			dots[i] = 1
+		}
+	}
+
+	chart := ui.BrailleBars(dots, chartRows)
+	chartStyle := style.WithForeground(u.theme.HighlightedForeground())
+	for row, line := range chart {
+		// Right align the chart, so that the newest values are always in the
+		// same place
+		lineLength := len([]rune(line))
+		drawText(u.screen, x1-lineLength+1, y0+row, x1+1, line, chartStyle)
+	}
+}
+
+// Convert cumulative CPU times into CPU percentages between consecutive
+// samples. The result has one entry less than the input.
+func historyToCpuPercents(samples []processes.HistorySample) []float64 {
+	percents := make([]float64, 0, len(samples))
+	for i := 1; i < len(samples); i++ {
+		elapsed := samples[i].Timestamp.Sub(samples[i-1].Timestamp)
+		if elapsed <= 0 {
+			continue
+		}
+
+		cpuTime := samples[i].CpuTime - samples[i-1].CpuTime
+		percents = append(percents, max(0.0, 100.0*cpuTime.Seconds()/elapsed.Seconds()))
+	}
+
+	return percents
+}
@@ -0,0 +1,24 @@
+package ftop
+
+import (
+	"testing"
+	"time"
+
+	"github.com/walles/ftop/internal/assert"
+	"github.com/walles/ftop/internal/processes"
+)
+
+func TestHistoryToCpuPercents(t *testing.T) {
+	t0 := time.Date(2026, 2, 18, 10, 0, 0, 0, time.UTC)
+
+	percents := historyToCpuPercents([]processes.HistorySample{
+		{Timestamp: t0, CpuTime: 1 * time.Second},
+		{Timestamp: t0.Add(2 * time.Second), CpuTime: 2 * time.Second},
+		{Timestamp: t0.Add(3 * time.Second), CpuTime: 2 * time.Second},
+
+		// Same timestamp as the previous one, should be skipped
+		{Timestamp: t0.Add(3 * time.Second), CpuTime: 3 * time.Second},
+	})
+
+	assert.SlicesEqual(t, percents, []float64{50.0, 0.0})
+}
@@ -70,7 +70,7 @@ func (u *Ui) renderProcessInfoPane(y0, y1 int) {
 
 	y += 2
 	x := 1
-	sinceStart := time.Since(u.pickedProcess.StartTime())
+	sinceStart := u.now().Sub(u.pickedProcess.StartTime())
 	if sinceStart < 0 {
 		x += drawText(u.screen, x, y, x1, "Start time uncertain, reported as ", plain)
 		x += drawText(u.screen, x, y, x1, util.FormatDuration(sinceStart.Abs()), highlighted)
@@ -99,6 +99,16 @@ func (u *Ui) renderProcessInfoPane(y0, y1 int) {
 		drawText(u.screen, x, y, x1, ".", plain)
 	}
 
+	y += 2
+	x = 1
+	if u.pickedProcess.IsStuck() {
+		// Being stuck is more interesting than the nativity
+		warning := twin.StyleDefault.WithForeground(u.theme.WarningForeground())
+		description := describeStuckProcess(u.pickedProcess, u.now(), func(s string) string { return s })
+		drawText(u.screen, x, y, x1, description, warning)
+		return
+	}
+
 	// Render nativity info
 
 	style := plain
@@ -111,9 +121,10 @@ func (u *Ui) renderProcessInfoPane(y0, y1 int) {
 	} else if u.pickedProcess.Nativity == 1 {
 		description = "spawned 1 child process"
 	}
+	if u.pickedProcess.SubtreeNativity > u.pickedProcess.Nativity {
+		description += fmt.Sprintf(" (launch score %d counting descendants)", u.pickedProcess.SubtreeNativity)
+	}
 
-	y += 2
-	x = 1
 	x += drawText(u.screen, x, y, x1, u.pickedProcess.String(), plain)
 	x += drawText(u.screen, x, y, x1, " ", plain)
 	x += drawText(u.screen, x, y, x1, description, style)
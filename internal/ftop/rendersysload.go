@@ -10,13 +10,7 @@ import (
 	"github.com/walles/moor/v2/twin"
 )
 
-func renderSysload(screen twin.Screen, theme themes.Theme, width int) {
-	sysload, err := sysload.GetSysload()
-	if err != nil {
-		// FIXME: Handle this better. What would the user want here?
-		panic(err)
-	}
-
+func renderSysload(screen twin.Screen, theme themes.Theme, sysload sysload.Sysload, width int) {
 	style := twin.StyleDefault.WithForeground(theme.Foreground())
 
 	x1 := width - 1
@@ -104,18 +98,11 @@ func averagesToGraphString(level1M, level5M, level15M float64) string {
 	}
 	levels = append(levels, l1)
 
-	// https://en.wikipedia.org/wiki/Braille_Patterns#Identifying.2C_naming_and_ordering
-	leftLevels := []rune{0x00, 0x40, 0x44, 0x46, 0x47}
-	rightLevels := []rune{0x00, 0x80, 0xA0, 0xB0, 0xB8}
-
-	graph := ""
-	for i := 0; i < len(levels); i += 2 {
-		leftLevel := levels[i] + 1
-		rightLevel := levels[i+1] + 1
-
-		r := rune(0x2800) + leftLevels[leftLevel] + rightLevels[rightLevel]
-		graph += string(r)
+	// Level 0 is one dot, and the padding level is no dots
+	dots := make([]int, len(levels))
+	for i, level := range levels {
+		dots[i] = level + 1
 	}
 
-	return graph
+	return ui.BrailleBars(dots, 1)[0]
 }
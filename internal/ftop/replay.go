@@ -0,0 +1,66 @@
+package ftop
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/walles/ftop/internal/snapshots"
+	"github.com/walles/moor/v2/twin"
+)
+
+// How far PgUp and PgDown move when replaying a recording
+const replaySeekDistance = 1 * time.Minute
+
+// nil unless we are replaying a recording
+func (u *Ui) replay() *snapshots.Replay {
+	replay, _ := u.source.(*snapshots.Replay)
+	return replay
+}
+
+// Example return values:
+//
+//	Replay 2026-10-17 Sat 03:12:45CEST [43/3600]
+//	Replay 2026-10-17 Sat 03:12:45CEST [43/3600] paused
+func replayTitle(replay *snapshots.Replay, snapshot snapshots.Snapshot) string {
+	index, count, paused := replay.Position()
+	title := fmt.Sprintf("Replay %s [%d/%d]", snapshot.Timestamp.Format(DISPLAY_TIME_FORMAT), index+1, count)
+	if paused {
+		title += " paused"
+	}
+
+	return title
+}
+
+// Returns true if the key was handled
+func (u *Ui) onReplayRune(r rune) bool {
+	replay := u.replay()
+	if replay == nil || r != ' ' {
+		return false
+	}
+
+	replay.TogglePause()
+	return true
+}
+
+// Returns true if the key was handled
+func (u *Ui) onReplayKeyCode(keyCode twin.KeyCode) bool {
+	replay := u.replay()
+	if replay == nil {
+		return false
+	}
+
+	switch keyCode {
+	case twin.KeyLeft:
+		replay.Step(-1)
+	case twin.KeyRight:
+		replay.Step(1)
+	case twin.KeyPgUp:
+		replay.Seek(-replaySeekDistance)
+	case twin.KeyPgDown:
+		replay.Seek(replaySeekDistance)
+	default:
+		return false
+	}
+
+	return true
+}
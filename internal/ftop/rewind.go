@@ -0,0 +1,40 @@
+package ftop
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/walles/ftop/internal/snapshots"
+	"github.com/walles/moor/v2/twin"
+)
+
+// nil unless we are keeping recent snapshots around for rewinding
+func (u *Ui) rewinder() *snapshots.Rewinder {
+	rewinder, _ := u.source.(*snapshots.Rewinder)
+	return rewinder
+}
+
+// Returns true if the key was handled
+func (u *Ui) onRewindKeyCode(keyCode twin.KeyCode) bool {
+	rewinder := u.rewinder()
+	if rewinder == nil {
+		return false
+	}
+
+	switch keyCode {
+	case twin.KeyLeft:
+		rewinder.Step(-1)
+	case twin.KeyRight:
+		rewinder.Step(1)
+	default:
+		return false
+	}
+
+	return true
+}
+
+// Minutes and seconds, "00:42" or "10:00" for example
+func formatAgo(ago time.Duration) string {
+	seconds := int(ago.Round(time.Second).Seconds())
+	return fmt.Sprintf("%02d:%02d", seconds/60, seconds%60)
+}
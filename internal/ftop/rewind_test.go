@@ -0,0 +1,14 @@
+package ftop
+
+import (
+	"testing"
+	"time"
+
+	"github.com/walles/ftop/internal/assert"
+)
+
+func TestFormatAgo(t *testing.T) {
+	assert.Equal(t, formatAgo(42*time.Second), "00:42")
+	assert.Equal(t, formatAgo(1500*time.Millisecond), "00:02")
+	assert.Equal(t, formatAgo(10*time.Minute), "10:00")
+}
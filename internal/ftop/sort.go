@@ -17,45 +17,61 @@ func SortByScore[T any](unordered []T, asStats func(t T) stats) []T {
 	copy(sorted, unordered)
 
 	maxCpuTime := time.Duration(0)
+	maxCpuScoreTime := time.Duration(0)
 	maxRssKb := 0
 	maxNativity := 0
+	maxIoBytesPerSecond := 0.0
 	for _, u := range unordered {
 		stat := asStats(u)
 		if stat.cpuTime > maxCpuTime {
 			maxCpuTime = stat.cpuTime
 		}
+		if stat.cpuScoreTime() > maxCpuScoreTime {
+			maxCpuScoreTime = stat.cpuScoreTime()
+		}
 		if stat.rssKb > maxRssKb {
 			maxRssKb = stat.rssKb
 		}
 		if stat.nativity > maxNativity {
 			maxNativity = stat.nativity
 		}
+		if stat.ioBytesPerSecond > maxIoBytesPerSecond {
+			maxIoBytesPerSecond = stat.ioBytesPerSecond
+		}
 	}
 
 	// Avoid division by zero later
 	if maxCpuTime == 0 {
 		maxCpuTime = 1
 	}
+	if maxCpuScoreTime == 0 {
+		maxCpuScoreTime = 1
+	}
 	if maxRssKb == 0 {
 		maxRssKb = 1
 	}
 	if maxNativity == 0 {
 		maxNativity = 1
 	}
+	if maxIoBytesPerSecond == 0 {
+		maxIoBytesPerSecond = 1
+	}
 
-	scoresI := make([]float64, 3)
-	scoresJ := make([]float64, 3)
+	scoresI := make([]float64, 4)
+	scoresJ := make([]float64, 4)
 	slices.SortFunc(sorted, func(ui T, uj T) int {
 		statsI := asStats(ui)
 		statsJ := asStats(uj)
 
-		scoresI[0] = float64(statsI.cpuTime) / float64(maxCpuTime)
+		scoresI[0] = float64(statsI.cpuScoreTime()) / float64(maxCpuScoreTime)
 		scoresI[1] = float64(statsI.rssKb) / float64(maxRssKb)
 		scoresI[2] = float64(statsI.nativity) / float64(maxNativity)
+		scoresI[3] = statsI.ioBytesPerSecond / maxIoBytesPerSecond
 
-		scoresJ[0] = float64(statsJ.cpuTime) / float64(maxCpuTime)
+		scoresJ[0] = float64(statsJ.cpuScoreTime()) / float64(maxCpuScoreTime)
 		scoresJ[1] = float64(statsJ.rssKb) / float64(maxRssKb)
 		scoresJ[2] = float64(statsJ.nativity) / float64(maxNativity)
+		scoresJ[3] = statsJ.ioBytesPerSecond / maxIoBytesPerSecond
 
 		slices.SortFunc(scoresI, func(si, sj float64) int {
 			// Negate to put highest scores first
@@ -66,7 +82,7 @@ func SortByScore[T any](unordered []T, asStats func(t T) stats) []T {
 			return -cmp.Compare(si, sj)
 		})
 
-		for k := range 3 {
+		for k := range scoresI {
 			if scoresI[k] != scoresJ[k] {
 				// Negate to put highest scores first
 				return -cmp.Compare(scoresI[k], scoresJ[k])
@@ -97,7 +113,10 @@ func SortByScore[T any](unordered []T, asStats func(t T) stats) []T {
 	return sorted
 }
 
-func aggregate[T any](processes []processes.Process, getGroup func(p processes.Process) string, cast func(stat stats) T) []T {
+// If pss is true, sum up PSS rather than RSS. RSS counts shared pages once per
+// process sharing them, so summing RSS over 40 forked workers gives a number
+// way larger than the RAM they actually use. PSS doesn't have that problem.
+func aggregate[T any](processes []processes.Process, pss bool, getGroup func(p processes.Process) string, cast func(stat stats) T) []T {
 	statsMap := make(map[string]stats)
 	for _, p := range processes {
 		stat, exists := statsMap[getGroup(p)]
@@ -108,8 +127,21 @@ func aggregate[T any](processes []processes.Process, getGroup func(p processes.P
 		if p.CpuTime != nil {
 			stat.cpuTime += *p.CpuTime
 		}
-		stat.rssKb += p.RssKb
+		if inclChildren := p.CpuTimeInclChildren(); inclChildren != nil {
+			stat.cpuTimeInclChildren += *inclChildren
+		}
+		if !pss {
+			stat.rssKb += p.RssKb
+		} else if p.PssKb != nil {
+			stat.rssKb += *p.PssKb
+		} else {
+			// Don't mix in RSS, that would make the sum meaningless
+			stat.ramPartial = true
+		}
 		stat.nativity += p.Nativity
+		if p.IoBytesPerSecond != nil {
+			stat.ioBytesPerSecond += *p.IoBytesPerSecond
+		}
 
 		statsMap[getGroup(p)] = stat
 	}
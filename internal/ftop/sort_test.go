@@ -100,3 +100,51 @@ func TestProcessesByScore_TieBreak_CPUOverMem(t *testing.T) {
 func toDurationPointer(d time.Duration) *time.Duration {
 	return &d
 }
+
+func TestProcessesByScore_io(t *testing.T) {
+	idle := processes.Process{Pid: 101, Cmdline: "idle", RssKb: 100}
+	diskHammer := processes.Process{Pid: 102, Cmdline: "diskHammer", RssKb: 50}
+
+	sorted := SortByScore([]processes.Process{idle, diskHammer}, func(p processes.Process) stats {
+		ioBytesPerSecond := 0.0
+		if p.Command() == "diskHammer" {
+			ioBytesPerSecond = 1_000_000.0
+		}
+		return stats{
+			name:             p.Command(),
+			rssKb:            p.RssKb,
+			ioBytesPerSecond: ioBytesPerSecond,
+		}
+	})
+
+	// Both max out one score each, but the disk hammer also uses some RAM
+	assert.Equal(t, sorted[0].Command(), "diskHammer")
+	assert.Equal(t, sorted[1].Command(), "idle")
+}
+
+// Build tools burn their CPU through short-lived children. That CPU should count
+// as theirs.
+func TestSortProcessesForDisplay_ChildrenCpu(t *testing.T) {
+	busy := processes.Process{Pid: 101, Cmdline: "busy", RssKb: 100, CpuTime: toDurationPointer(100 * time.Second)}
+	makeProcess := processes.Process{Pid: 102, Cmdline: "make", RssKb: 10, CpuTime: toDurationPointer(1 * time.Second), ChildrenCpuTime: toDurationPointer(500 * time.Second)}
+	bigRam := processes.Process{Pid: 103, Cmdline: "bigRam", RssKb: 1000, CpuTime: toDurationPointer(0)}
+
+	sorted := sortProcessesForDisplay([]processes.Process{bigRam, makeProcess, busy}, false)
+
+	// The top CPU user still goes first, since that's what I expect from top
+	assert.Equal(t, sorted[0].Command(), "busy")
+	assert.Equal(t, sorted[1].Command(), "make")
+	assert.Equal(t, sorted[2].Command(), "bigRam")
+}
+
+// Orchestrators should rank above the intermediate shells they launch things
+// through
+func TestSortProcessesForDisplay_SubtreeNativity(t *testing.T) {
+	sh := processes.Process{Pid: 101, Cmdline: "sh", Nativity: 20, SubtreeNativity: 20}
+	brew := processes.Process{Pid: 102, Cmdline: "brew", Nativity: 1, SubtreeNativity: 1 + 2*20}
+
+	sorted := sortProcessesForDisplay([]processes.Process{sh, brew}, false)
+
+	assert.Equal(t, sorted[0].Command(), "brew")
+	assert.Equal(t, sorted[1].Command(), "sh")
+}
@@ -0,0 +1,161 @@
+package ftop
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/walles/ftop/internal/processes"
+)
+
+// How to order the processes, per-user and per-command tables. The user cycles
+// through these using 's'.
+type SortMode string
+
+const (
+	// Blend CPU, RAM, launches and IO, with the top CPU user first. See
+	// SortByScore().
+	SortModeScore SortMode = "score"
+
+	SortModeCpu SortMode = "cpu"
+	SortModeRam SortMode = "ram"
+
+	// Lowest PID first. The per-user and per-command tables have no PIDs and
+	// are sorted by score.
+	SortModePid SortMode = "pid"
+
+	// Most recently started first. The per-user and per-command tables have no
+	// start times and are sorted by score.
+	SortModeNewest SortMode = "newest"
+
+	// Most recently launched child processes first
+	SortModeLaunches SortMode = "launches"
+
+	SortModeIo SortMode = "io"
+)
+
+// In the order 's' cycles through them
+func SortModes() []SortMode {
+	return []SortMode{
+		SortModeScore,
+		SortModeCpu,
+		SortModeRam,
+		SortModePid,
+		SortModeNewest,
+		SortModeLaunches,
+		SortModeIo,
+	}
+}
+
+func (m SortMode) next() SortMode {
+	modes := SortModes()
+	index := slices.Index(modes, m)
+	return modes[(index+1)%len(modes)]
+}
+
+// Empty for SortModeScore, since that's what we do unless asked otherwise.
+// Example return values:
+//
+//	sorted by RAM
+//	sorted by newest
+func (m SortMode) description() string {
+	switch m {
+	case SortModeCpu:
+		return "sorted by CPU"
+	case SortModeRam:
+		return "sorted by RAM"
+	case SortModePid:
+		return "sorted by PID"
+	case SortModeNewest:
+		return "sorted by newest"
+	case SortModeLaunches:
+		return "sorted by launches"
+	case SortModeIo:
+		return "sorted by IO"
+	}
+	return ""
+}
+
+// Like sortProcessesForDisplay(), but in the user's sort mode
+func (u *Ui) sortProcesses(processesRaw []processes.Process) []processes.Process {
+	return sortProcessesBy(processesRaw, u.sortMode, u.isShowingPss())
+}
+
+// If pss is true, SortModeRam sorts by PSS rather than by RSS
+func sortProcessesBy(processesRaw []processes.Process, mode SortMode, pss bool) []processes.Process {
+	var compare func(a, b processes.Process) int
+	switch mode {
+	case SortModeCpu:
+		compare = func(a, b processes.Process) int { return cmp.Compare(cpuTimeOrZero(b), cpuTimeOrZero(a)) }
+	case SortModeRam:
+		compare = func(a, b processes.Process) int { return cmp.Compare(ramKb(b, pss), ramKb(a, pss)) }
+	case SortModePid:
+		compare = func(a, b processes.Process) int { return cmp.Compare(a.Pid, b.Pid) }
+	case SortModeNewest:
+		compare = func(a, b processes.Process) int { return b.StartTime().Compare(a.StartTime()) }
+	case SortModeLaunches:
+		compare = func(a, b processes.Process) int { return cmp.Compare(b.SubtreeNativity, a.SubtreeNativity) }
+	case SortModeIo:
+		compare = func(a, b processes.Process) int { return cmp.Compare(ioRateOrZero(b), ioRateOrZero(a)) }
+	default:
+		return sortProcessesForDisplay(processesRaw, pss)
+	}
+
+	sorted := slices.Clone(processesRaw)
+	slices.SortFunc(sorted, func(a, b processes.Process) int {
+		return cmp.Or(
+			compare(a, b),
+
+			// For stability when the sort key is equal
+			cmp.Compare(a.Command(), b.Command()),
+			cmp.Compare(a.Pid, b.Pid),
+		)
+	})
+	return sorted
+}
+
+// Per-user and per-command stats, top list first according to mode
+func sortStats[T any](unordered []T, mode SortMode, asStats func(t T) stats) []T {
+	var key func(s stats) float64
+	switch mode {
+	case SortModeCpu:
+		// Same as for processes, the CPU time we show. Only the score
+		// counts children's CPU time as well.
+		key = func(s stats) float64 { return float64(s.cpuTime) }
+	case SortModeRam:
+		key = func(s stats) float64 { return float64(s.rssKb) }
+	case SortModeLaunches:
+		key = func(s stats) float64 { return float64(s.nativity) }
+	case SortModeIo:
+		key = func(s stats) float64 { return s.ioBytesPerSecond }
+	default:
+		return SortByScore(unordered, asStats)
+	}
+
+	sorted := slices.Clone(unordered)
+	slices.SortFunc(sorted, func(a, b T) int {
+		statsA := asStats(a)
+		statsB := asStats(b)
+		return cmp.Or(
+			// Negate to put highest values first
+			-cmp.Compare(key(statsA), key(statsB)),
+
+			cmp.Compare(statsA.name, statsB.name),
+		)
+	})
+	return sorted
+}
+
+func cpuTimeOrZero(p processes.Process) time.Duration {
+	if p.CpuTime == nil {
+		return 0
+	}
+	return *p.CpuTime
+}
+
+func ioRateOrZero(p processes.Process) float64 {
+	if p.IoBytesPerSecond == nil {
+		return 0
+	}
+	return *p.IoBytesPerSecond
+}
@@ -0,0 +1,68 @@
+package ftop
+
+import (
+	"testing"
+	"time"
+
+	"github.com/walles/ftop/internal/assert"
+	"github.com/walles/ftop/internal/processes"
+)
+
+func TestSortModeCycle(t *testing.T) {
+	mode := SortModeScore
+	for range SortModes() {
+		mode = mode.next()
+	}
+	assert.Equal(t, mode, SortModeScore)
+	assert.Equal(t, SortModeScore.next(), SortModeCpu)
+}
+
+func TestSortProcesses(t *testing.T) {
+	procs := []processes.Process{
+		{Pid: 3, Cmdline: "busy", RssKb: 10, CpuTime: toDuration(60)},
+		{Pid: 1, Cmdline: "big", RssKb: 1000, CpuTime: toDuration(1)},
+		{Pid: 2, Cmdline: "launcher", RssKb: 20, SubtreeNativity: 5},
+	}
+
+	commands := func(sorted []processes.Process) []string {
+		var names []string
+		for _, p := range sorted {
+			names = append(names, p.Command())
+		}
+		return names
+	}
+
+	u := Ui{sortMode: SortModeRam}
+	assert.SlicesEqual(t, commands(u.sortProcesses(procs)), []string{"big", "launcher", "busy"})
+
+	u.sortMode = SortModePid
+	assert.SlicesEqual(t, commands(u.sortProcesses(procs)), []string{"big", "launcher", "busy"})
+
+	u.sortMode = SortModeCpu
+	assert.SlicesEqual(t, commands(u.sortProcesses(procs)), []string{"busy", "big", "launcher"})
+
+	u.sortMode = SortModeLaunches
+	assert.Equal(t, u.sortProcesses(procs)[0].Command(), "launcher")
+}
+
+func TestSortStats(t *testing.T) {
+	users := []userStats{
+		{stats{name: "root", cpuTime: 60 * time.Second, rssKb: 10}},
+		{stats{name: "www", cpuTime: 1 * time.Second, rssKb: 1000}},
+	}
+	asStats := func(u userStats) stats { return u.stats }
+
+	assert.Equal(t, sortStats(users, SortModeRam, asStats)[0].name, "www")
+	assert.Equal(t, sortStats(users, SortModeCpu, asStats)[0].name, "root")
+
+	// Sorted by the CPU time we show, just like processes are
+	commands := []commandStats{
+		{stats{name: "make", cpuTime: 1 * time.Second, cpuTimeInclChildren: 90 * time.Second}},
+		{stats{name: "cc", cpuTime: 30 * time.Second}},
+	}
+	asCommandStats := func(c commandStats) stats { return c.stats }
+	assert.Equal(t, sortStats(commands, SortModeCpu, asCommandStats)[0].name, "cc")
+
+	// No PIDs in aggregates, so this falls back to the score
+	assert.Equal(t, sortStats(users, SortModePid, asStats)[0].name, "root")
+}
@@ -1,7 +1,10 @@
 package ftop
 
 import (
+	"time"
+
 	"github.com/walles/ftop/internal/processes"
+	"github.com/walles/ftop/internal/snapshots"
 	"github.com/walles/ftop/internal/themes"
 	"github.com/walles/moor/v2/twin"
 )
@@ -18,6 +21,23 @@ type Ui struct {
 	eventHandler eventHandler
 	events       chan any
 
+	// Where we get our snapshots from, set by MainLoop(). nil while showing
+	// the multi-host overview.
+	source snapshots.Source
+
+	// Set by MultiHostMainLoop(), nil otherwise
+	hosts      []*hostConnection
+	pickedHost int             // Index into hosts
+	host       *hostConnection // The one we're showing, nil for the overview
+
+	// How far back the user can step inside of a host, set by
+	// MultiHostMainLoop()
+	rewindLength time.Duration
+
+	// When the snapshot we rendered last was taken. When replaying a
+	// recording, this is way before time.Now().
+	snapshotTime time.Time
+
 	filter string // Empty means no filter
 
 	done bool
@@ -29,6 +49,42 @@ type Ui struct {
 	// This will be updated during rendering
 	pickedProcess *processes.Process
 
+	// Stuck processes among the ones we rendered last, updated during
+	// rendering
+	stuckProcesses []processes.Process
+
+	// If true, the per-user pane groups processes by cgroup instead
+	groupByCgroup bool
+
+	// True if any process we rendered last had cgroup info. Updated during
+	// rendering.
+	cgroupsAvailable bool
+
+	// If true, the per-process RAM column shows PSS instead of RSS
+	showPss bool
+
+	// True if any process we rendered last had PSS info. Updated during
+	// rendering.
+	pssAvailable bool
+
+	// How to order our tables, 's' cycles through the modes
+	sortMode SortMode
+
+	// Which CPU time to show and sort by, 'c' cycles through them
+	cpuWindow cpuWindow
+
+	// Set when the user resets the CPU baseline using 'r', nil before that.
+	// Keyed by PID.
+	cpuBaseline     map[int]*processes.Process
+	cpuBaselineTime time.Time
+
+	// IDs of the per-process columns to show, nil means the defaults. 'o'
+	// opens a picker for changing these.
+	columnIds []string
+
+	// Where the column picker saves its changes, empty means nowhere
+	columnsConfigPath string
+
 	// At this width or wider, we have always managed to render all three panes.
 	// Below this, we shouldn't even try.
 	//
@@ -43,6 +99,8 @@ func NewUi(screen twin.Screen, theme themes.Theme, initialFilter string) *Ui {
 		screen: screen,
 		filter: initialFilter,
 
+		sortMode: SortModeScore,
+
 		// With race detection enabled (makes everything slow) and holding the down
 		// arrow key, I saw event queues of at most 3. 10 will give us some headroom
 		// on top of that.
@@ -55,3 +113,17 @@ func NewUi(screen twin.Screen, theme themes.Theme, initialFilter string) *Ui {
 
 	return ui
 }
+
+// Start out sorting our tables this way
+func (u *Ui) SetSortMode(sortMode SortMode) {
+	u.sortMode = sortMode
+}
+
+// Use this rather than time.Now() for anything describing what we're showing
+func (u *Ui) now() time.Time {
+	if u.snapshotTime.IsZero() {
+		return time.Now()
+	}
+
+	return u.snapshotTime
+}
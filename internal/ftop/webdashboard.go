@@ -0,0 +1,184 @@
+package ftop
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"slices"
+	"sync"
+
+	"github.com/walles/ftop/internal/log"
+	"github.com/walles/ftop/internal/processes"
+	"github.com/walles/ftop/internal/snapshots"
+	"github.com/walles/ftop/internal/themes"
+)
+
+// Everything in one file, so that the dashboard works without network access
+//
+//go:embed webdashboard.html
+var webDashboardHtml []byte
+
+// What the dashboard gets pushed to it on every update. All numbers are
+// formatted by the same code as in the terminal UI, so that they match.
+type webSample struct {
+	Title     string       `json:"title"`
+	Overview  []string     `json:"overview"`
+	Io        [][]string   `json:"io"` // Device name and rate, busiest first
+	Processes webTextTable `json:"processes"`
+	Users     webTextTable `json:"users"`
+	Commands  webTextTable `json:"commands"`
+	Launches  []string     `json:"launches"` // The launched commands tree, one line per row
+}
+
+type webTextTable struct {
+	Headers     []string   `json:"headers"`
+	Rows        [][]string `json:"rows"`
+	LeftAligned []bool     `json:"leftAligned"`
+}
+
+// Keeps track of the latest sample, and tells all connected browsers when
+// there is a new one
+type webBroadcaster struct {
+	mutex     sync.Mutex
+	latest    []byte // JSON encoded webSample
+	listeners map[chan struct{}]bool
+}
+
+// Serve a live updating dashboard on http://address/ until we fail. Processes
+// not matching filter are left out. columnIds are the per-process columns to
+// show, nil means the defaults.
+func ServeHttp(source snapshots.Source, address string, filter string, columnIds []string) error {
+	broadcaster := &webBroadcaster{
+		listeners: make(map[chan struct{}]bool),
+	}
+
+	go func() {
+		defer func() {
+			log.PanicHandler("web dashboard", recover(), debug.Stack())
+		}()
+
+		// Only used for rendering to fake screens, colors don't matter
+		theme := themes.NewTheme("dark", nil)
+
+		for range source.OnUpdate() {
+			snapshot := source.Snapshot()
+			snapshot.Processes = processes.Filter(snapshot.Processes, filter)
+
+			encoded, err := json.Marshal(toWebSample(theme, filter, columnIds, snapshot))
+			if err != nil {
+				log.Errorf("failed to encode web dashboard sample: %v", err)
+				continue
+			}
+			broadcaster.publish(encoded)
+		}
+	}()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(webDashboardHtml)
+	})
+	mux.HandleFunc("/events", broadcaster.serveEvents)
+
+	return http.ListenAndServe(address, mux)
+}
+
+func (b *webBroadcaster) publish(encoded []byte) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.latest = encoded
+	for listener := range b.listeners {
+		// Listeners only care about the latest sample, so if one is already
+		// pending there is no need to queue another one
+		select {
+		case listener <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Push samples to one browser using Server-Sent Events, until it disconnects:
+// https://html.spec.whatwg.org/multipage/server-sent-events.html
+func (b *webBroadcaster) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	listener := make(chan struct{}, 1)
+	b.mutex.Lock()
+	b.listeners[listener] = true
+	if b.latest != nil {
+		// Don't make new browsers wait for the next update
+		listener <- struct{}{}
+	}
+	b.mutex.Unlock()
+
+	defer func() {
+		b.mutex.Lock()
+		delete(b.listeners, listener)
+		b.mutex.Unlock()
+	}()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-listener:
+		}
+
+		b.mutex.Lock()
+		encoded := b.latest
+		b.mutex.Unlock()
+
+		_, err := fmt.Fprintf(w, "data: %s\n\n", encoded)
+		if err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// The snapshot's processes should already be filtered
+func toWebSample(theme themes.Theme, filter string, columnIds []string, snapshot snapshots.Snapshot) webSample {
+	procs := snapshot.Processes
+
+	u := newTablesUi(theme, filter, columnIds, SortModeScore)
+
+	ioStats := slices.Clone(snapshot.IoStats)
+	sortIoStats(ioStats)
+	io := [][]string{}
+	for _, stat := range ioStats {
+		io = append(io, []string{stat.DeviceName, ioRateString(stat)})
+	}
+
+	launches := launchedCommandsLines(theme, snapshot.Launches)
+	if launches == nil {
+		launches = []string{}
+	}
+
+	return webSample{
+		Title:     snapshot.Timestamp.Format(DISPLAY_TIME_FORMAT),
+		Overview:  overviewLines(theme, snapshot),
+		Io:        io,
+		Processes: toWebTextTable(u.processesTextTable(procs)),
+		Users:     toWebTextTable(u.usersTextTable(procs)),
+		Commands:  toWebTextTable(u.commandsTextTable(procs)),
+		Launches:  launches,
+	}
+}
+
+func toWebTextTable(table textTable) webTextTable {
+	return webTextTable{
+		Headers:     table.rows[0],
+		Rows:        table.rows[1:],
+		LeftAligned: table.leftAligned,
+	}
+}
@@ -0,0 +1,80 @@
+package ftop
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/walles/ftop/internal/assert"
+	"github.com/walles/ftop/internal/io"
+	"github.com/walles/ftop/internal/processes"
+	"github.com/walles/ftop/internal/snapshots"
+	"github.com/walles/ftop/internal/themes"
+)
+
+func TestToWebSample(t *testing.T) {
+	procs := []processes.Process{
+		{Pid: 2, Cmdline: "small", Username: "www", RssKb: 20, CpuTime: toDuration(20)},
+		{Pid: 1, Cmdline: "big", Username: "root", RssKb: 10, CpuTime: toDuration(60)},
+	}
+
+	sample := toWebSample(themes.NewTheme("dark", nil), "", nil, snapshots.Snapshot{
+		Timestamp: time.Now(),
+		Processes: procs,
+		IoStats: []io.Stat{
+			{DeviceName: "quiet", BytesPerSecond: 1, HighWatermark: 1},
+			{DeviceName: "busy", BytesPerSecond: 2048, HighWatermark: 4096},
+		},
+	})
+
+	assert.Equal(t, strings.HasPrefix(sample.Overview[0], "Sysload: "), true)
+	assert.SlicesEqual(t, sample.Io[0], []string{"busy", "2.0kB/s"})
+
+	// Same order and formatting as in the interactive UI
+	assert.Equal(t, sample.Processes.Headers[0], "PID")
+	assert.Equal(t, sample.Processes.Rows[0][1], "big")
+	assert.Equal(t, sample.Processes.Rows[1][1], "small")
+	assert.SlicesEqual(t, sample.Users.Rows[0], []string{"root", "1m00s", "10k"})
+	assert.SlicesEqual(t, sample.Commands.Rows[0], []string{"big", "1m00s", "10k"})
+
+	// Should be encoded as an empty list, not as null
+	assert.Equal(t, len(sample.Launches), 0)
+	assert.Equal(t, sample.Launches != nil, true)
+}
+
+func TestWebBroadcaster(t *testing.T) {
+	broadcaster := &webBroadcaster{listeners: make(map[chan struct{}]bool)}
+	broadcaster.publish([]byte(`{"title":"first"}`))
+
+	server := httptest.NewServer(http.HandlerFunc(broadcaster.serveEvents))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	assert.Equal(t, err, nil)
+	response, err := http.DefaultClient.Do(request)
+	assert.Equal(t, err, nil)
+	defer func() { _ = response.Body.Close() }()
+	assert.Equal(t, response.Header.Get("Content-Type"), "text/event-stream")
+
+	reader := bufio.NewReader(response.Body)
+	readEvent := func() string {
+		line, err := reader.ReadString('\n')
+		assert.Equal(t, err, nil)
+		empty, err := reader.ReadString('\n')
+		assert.Equal(t, err, nil)
+		assert.Equal(t, empty, "\n")
+		return line
+	}
+
+	// New listeners should get the latest sample right away
+	assert.Equal(t, readEvent(), "data: {\"title\":\"first\"}\n")
+
+	broadcaster.publish([]byte(`{"title":"second"}`))
+	assert.Equal(t, readEvent(), "data: {\"title\":\"second\"}\n")
+}
@@ -28,6 +28,9 @@ type Stat struct {
 	DeviceName     string
 	BytesPerSecond float64
 	HighWatermark  float64 // Max value of BytesPerSecond seen so far
+
+	// Bytes transferred since whenever the OS started counting, usually boot
+	BytesTotal uint64
 }
 
 func NewTracker() *Tracker {
@@ -119,6 +122,7 @@ func (tracker *Tracker) Stats() []Stat {
 			DeviceName:     deviceName,
 			BytesPerSecond: bytesPerSecond,
 			HighWatermark:  peakThroughput,
+			BytesTotal:     currentBytes,
 		})
 	}
 
@@ -0,0 +1,127 @@
+package processes
+
+import (
+	"bufio"
+	"regexp"
+	"strings"
+)
+
+// Container IDs are 64 hex digits, we show the first 12 of them just like
+// "docker ps" does.
+const shortContainerIdLength = 12
+
+var containerIdRegexp = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// Matches both "kubepods-burstable-pod<uid>.slice" (systemd cgroup driver) and
+// "pod<uid>" (cgroupfs driver). The systemd driver uses underscores instead of
+// dashes in the UID.
+var kubernetesPodRegexp = regexp.MustCompile(`pod([0-9a-f]{8}[-_][0-9a-f]{4}[-_][0-9a-f]{4}[-_][0-9a-f]{4}[-_][0-9a-f]{12})(\.slice)?$`)
+
+// Container runtime cgroup name prefixes, and what we call those runtimes
+var containerPrefixes = []struct {
+	prefix  string
+	runtime string
+}{
+	{"docker-", "docker"},
+	{"libpod-", "podman"},
+	{"cri-containerd-", "containerd"},
+	{"crio-", "cri-o"},
+}
+
+// Extract the cgroup path from the contents of a /proc/<pid>/cgroup file.
+//
+// Prefers the unified (v2) hierarchy, then the systemd v1 hierarchy, then any
+// other v1 hierarchy. Returns "/" if the process is in the root cgroup
+// everywhere.
+//
+// Example input:
+//
+//	0::/system.slice/nginx.service
+func parseProcPidCgroup(cgroup string) string { // nolint:unused
+	v2Path := ""
+	systemdPath := ""
+	otherPath := ""
+
+	scanner := bufio.NewScanner(strings.NewReader(cgroup))
+	for scanner.Scan() {
+		// "hierarchy-ID:controller-list:cgroup-path"
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+
+		controllers := parts[1]
+		path := parts[2]
+		if path == "" || path == "/" {
+			continue
+		}
+
+		switch {
+		case parts[0] == "0" && controllers == "":
+			v2Path = path
+		case controllers == "name=systemd":
+			systemdPath = path
+		case otherPath == "":
+			otherPath = path
+		}
+	}
+
+	for _, path := range []string{v2Path, systemdPath, otherPath} {
+		if path != "" {
+			return path
+		}
+	}
+
+	return "/"
+}
+
+// Turn a cgroup path into something a human would call a group of processes.
+//
+// Example return values:
+//
+//	pod:5b1d6e0a-8f3c-4b5e-9d2a-0c1e2f3a4b5c
+//	docker:0123456789ab
+//	nginx.service
+func cgroupPathToGroup(path string) string { // nolint:unused
+	components := strings.Split(strings.Trim(path, "/"), "/")
+
+	// Kubernetes pods contain containers, so the pod is the bigger group
+	for _, component := range components {
+		match := kubernetesPodRegexp.FindStringSubmatch(component)
+		if match != nil {
+			return "pod:" + strings.ReplaceAll(match[1], "_", "-")
+		}
+	}
+
+	for i := len(components) - 1; i >= 0; i-- {
+		name := strings.TrimSuffix(components[i], ".scope")
+
+		runtime := "container"
+		if i > 0 && components[i-1] == "docker" {
+			// "/docker/<id>", from Docker's cgroupfs driver
+			runtime = "docker"
+		}
+		for _, container := range containerPrefixes {
+			if strings.HasPrefix(name, container.prefix) {
+				name = strings.TrimPrefix(name, container.prefix)
+				runtime = container.runtime
+				break
+			}
+		}
+
+		if containerIdRegexp.MatchString(name) {
+			return runtime + ":" + name[:shortContainerIdLength]
+		}
+	}
+
+	for i := len(components) - 1; i >= 0; i-- {
+		if strings.HasSuffix(components[i], ".service") || strings.HasSuffix(components[i], ".scope") {
+			return components[i]
+		}
+	}
+
+	if components[len(components)-1] == "" {
+		return "/"
+	}
+	return components[len(components)-1]
+}
@@ -0,0 +1,55 @@
+package processes
+
+import (
+	"testing"
+
+	"github.com/walles/ftop/internal/assert"
+)
+
+func TestParseProcPidCgroup(t *testing.T) {
+	// Pure v2
+	assert.Equal(t, parseProcPidCgroup("0::/system.slice/nginx.service\n"), "/system.slice/nginx.service")
+
+	// Hybrid, the v2 line is there but empty
+	assert.Equal(t, parseProcPidCgroup(
+		"12:memory:/docker/abc\n"+
+			"1:name=systemd:/system.slice/docker.service\n"+
+			"0::/\n"), "/system.slice/docker.service")
+
+	// v1 only, a process in a Docker container
+	assert.Equal(t, parseProcPidCgroup(
+		"9:name=systemd:/\n"+
+			"4:memory:/docker/4f3c2a1b0e9d8c7b6a5f4e3d2c1b0a9f8e7d6c5b4a3f2e1d0c9b8a7f6e5d4c3b\n"+
+			"1:cpu:/\n"+
+			"0::/\n"), "/docker/4f3c2a1b0e9d8c7b6a5f4e3d2c1b0a9f8e7d6c5b4a3f2e1d0c9b8a7f6e5d4c3b")
+
+	// Root cgroup, kernel threads live here
+	assert.Equal(t, parseProcPidCgroup("0::/\n"), "/")
+	assert.Equal(t, parseProcPidCgroup(""), "/")
+}
+
+func TestCgroupPathToGroup(t *testing.T) {
+	const containerId = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	assert.Equal(t, cgroupPathToGroup("/"), "/")
+	assert.Equal(t, cgroupPathToGroup("/system.slice/nginx.service"), "nginx.service")
+	assert.Equal(t, cgroupPathToGroup("/user.slice/user-1000.slice/session-2.scope"), "session-2.scope")
+	assert.Equal(t, cgroupPathToGroup("/kubepods/burstable/pod1234"), "pod1234")
+
+	// Docker, systemd and cgroupfs drivers
+	assert.Equal(t, cgroupPathToGroup("/system.slice/docker-"+containerId+".scope"), "docker:0123456789ab")
+	assert.Equal(t, cgroupPathToGroup("/docker/"+containerId), "docker:0123456789ab")
+
+	// Podman, both for the container itself and for processes in its init
+	// sub-cgroup
+	assert.Equal(t, cgroupPathToGroup("/machine.slice/libpod-"+containerId+".scope"), "podman:0123456789ab")
+	assert.Equal(t, cgroupPathToGroup("/machine.slice/libpod-"+containerId+".scope/container"), "podman:0123456789ab")
+
+	// Kubernetes, systemd and cgroupfs drivers
+	assert.Equal(t,
+		cgroupPathToGroup("/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod5b1d6e0a_8f3c_4b5e_9d2a_0c1e2f3a4b5c.slice/cri-containerd-"+containerId+".scope"),
+		"pod:5b1d6e0a-8f3c-4b5e-9d2a-0c1e2f3a4b5c")
+	assert.Equal(t,
+		cgroupPathToGroup("/kubepods/besteffort/pod5b1d6e0a-8f3c-4b5e-9d2a-0c1e2f3a4b5c/"+containerId),
+		"pod:5b1d6e0a-8f3c-4b5e-9d2a-0c1e2f3a4b5c")
+}
@@ -0,0 +1,81 @@
+package processes
+
+import "time"
+
+// A copy of this process with CpuTime covering only the window ending at now,
+// summed from the deltas between consecutive history samples. The CPU
+// percentage is recomputed to match.
+//
+// Children CPU time isn't part of the history, so it's unknown in the copy.
+//
+// If we have no history, CpuTime and the CPU percentage are unknown.
+func (p Process) WithCpuWindow(window time.Duration, now time.Time) Process {
+	p.ChildrenCpuTime = nil
+	p.ChildrenCpuTimeTotal = nil
+	p.CpuTime = nil
+	p.cpuPercent = nil
+	if p.history == nil {
+		return p
+	}
+
+	windowStart := now.Add(-window)
+	var cpuTime time.Duration
+	var covered time.Duration // How much of the window we have samples for
+	var previous *HistorySample
+	for _, sample := range p.history.SamplesBetween(windowStart, now) {
+		if previous == nil && !p.startTime.Before(windowStart) {
+			// Born inside the window, everything it ever used counts
+			cpuTime += sample.CpuTime
+			covered += sample.Timestamp.Sub(p.startTime)
+		}
+
+		if previous != nil && sample.Timestamp.After(windowStart) && sample.Timestamp.After(previous.Timestamp) {
+			// If this interval straddles the window start, count only the part
+			// inside the window
+			segmentStart := previous.Timestamp
+			if segmentStart.Before(windowStart) {
+				segmentStart = windowStart
+			}
+			fraction := float64(sample.Timestamp.Sub(segmentStart)) / float64(sample.Timestamp.Sub(previous.Timestamp))
+			cpuTime += time.Duration(fraction * float64(sample.CpuTime-previous.CpuTime))
+			covered += sample.Timestamp.Sub(segmentStart)
+		}
+
+		previous = &sample
+	}
+	if previous == nil {
+		return p
+	}
+
+	p.CpuTime = &cpuTime
+	if covered > 0 {
+		percent := 100.0 * float64(cpuTime) / float64(covered)
+		p.cpuPercent = &percent
+	}
+
+	return p
+}
+
+// A copy of this process with CpuTime and ChildrenCpuTime counted from
+// baseline rather than from when ftop started. baseline should be an earlier
+// snapshot of the same process, or nil if the process is younger than the
+// baseline.
+func (p Process) WithCpuBaseline(baseline *Process) Process {
+	p.CpuTime = p.CpuTimeTotal
+	p.ChildrenCpuTime = p.ChildrenCpuTimeTotal
+	if baseline == nil || !p.SameAs(baseline) {
+		return p
+	}
+
+	if p.CpuTimeTotal != nil && baseline.CpuTimeTotal != nil {
+		// Never negative, even if we're rewound to before the baseline
+		cpuTime := max(*p.CpuTimeTotal-*baseline.CpuTimeTotal, 0)
+		p.CpuTime = &cpuTime
+	}
+	if p.ChildrenCpuTimeTotal != nil && baseline.ChildrenCpuTimeTotal != nil {
+		childrenCpuTime := max(*p.ChildrenCpuTimeTotal-*baseline.ChildrenCpuTimeTotal, 0)
+		p.ChildrenCpuTime = &childrenCpuTime
+	}
+
+	return p
+}
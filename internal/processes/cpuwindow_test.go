@@ -0,0 +1,78 @@
+package processes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/walles/ftop/internal/assert"
+)
+
+func TestWithCpuWindow(t *testing.T) {
+	t0 := time.Now()
+	history := &History{}
+	for i, cpuSeconds := range []int{100, 100, 103, 106, 106, 110} {
+		// One sample every five seconds
+		history.add(HistorySample{Timestamp: t0.Add(time.Duration(i) * 5 * time.Second), CpuTime: time.Duration(cpuSeconds) * time.Second})
+	}
+	p := Process{Pid: 1, startTime: t0.Add(-time.Hour), history: history}
+	now := t0.Add(25 * time.Second)
+
+	// 106 -> 106 -> 110
+	windowed := p.WithCpuWindow(10*time.Second, now)
+	assert.Equal(t, *windowed.CpuTime, 4*time.Second)
+	assert.Equal(t, *windowed.CpuPercent(), 40.0)
+
+	// Half of the 100 -> 103 interval, then 103 -> 106 -> 106 -> 110
+	windowed = p.WithCpuWindow(17500*time.Millisecond, now)
+	assert.Equal(t, *windowed.CpuTime, 8500*time.Millisecond)
+
+	// When rewound, ignore samples after what we're showing. 100 -> 103 -> 106.
+	windowed = p.WithCpuWindow(60*time.Second, t0.Add(15*time.Second))
+	assert.Equal(t, *windowed.CpuTime, 6*time.Second)
+}
+
+func TestWithCpuWindow_BornInsideWindow(t *testing.T) {
+	t0 := time.Now()
+	history := &History{}
+	history.add(HistorySample{Timestamp: t0, CpuTime: 2 * time.Second})
+	history.add(HistorySample{Timestamp: t0.Add(time.Second), CpuTime: 3 * time.Second})
+	p := Process{Pid: 1, startTime: t0.Add(-3 * time.Second), history: history}
+
+	// Counting from when it started
+	windowed := p.WithCpuWindow(10*time.Second, t0.Add(time.Second))
+	assert.Equal(t, *windowed.CpuTime, 3*time.Second)
+	assert.Equal(t, *windowed.CpuPercent(), 75.0)
+}
+
+func TestWithCpuWindow_NoHistory(t *testing.T) {
+	cpuTime := time.Second
+	p := Process{Pid: 1, CpuTime: &cpuTime, ChildrenCpuTime: &cpuTime}
+
+	windowed := p.WithCpuWindow(10*time.Second, time.Now())
+	assert.Equal(t, windowed.CpuTime == nil, true)
+	assert.Equal(t, windowed.ChildrenCpuTime == nil, true)
+}
+
+func TestWithCpuBaseline(t *testing.T) {
+	startTime := time.Now().Add(-time.Hour)
+	baseCpu := 10 * time.Second
+	baseChildrenCpu := 20 * time.Second
+	baseline := &Process{Pid: 1, startTime: startTime, CpuTimeTotal: &baseCpu, ChildrenCpuTimeTotal: &baseChildrenCpu}
+
+	cpu := 15 * time.Second
+	childrenCpu := 50 * time.Second
+	p := Process{Pid: 1, startTime: startTime, CpuTimeTotal: &cpu, ChildrenCpuTimeTotal: &childrenCpu}
+
+	adjusted := p.WithCpuBaseline(baseline)
+	assert.Equal(t, *adjusted.CpuTime, 5*time.Second)
+	assert.Equal(t, *adjusted.ChildrenCpuTime, 30*time.Second)
+
+	// Started after the baseline was taken, everything counts
+	adjusted = p.WithCpuBaseline(nil)
+	assert.Equal(t, *adjusted.CpuTime, 15*time.Second)
+
+	// Same PID but a different process
+	reused := &Process{Pid: 1, startTime: startTime.Add(-time.Hour), CpuTimeTotal: &baseCpu}
+	adjusted = p.WithCpuBaseline(reused)
+	assert.Equal(t, *adjusted.CpuTime, 15*time.Second)
+}
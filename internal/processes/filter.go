@@ -39,6 +39,10 @@ func (p *Process) Matches(filter string) bool {
 		return true
 	}
 
+	if strings.Contains(strings.ToLower(p.Cgroup), lowerCaseFilter) {
+		return true
+	}
+
 	pidStr := strconv.Itoa(p.Pid)
 	if strings.Contains(pidStr, filter) { // nolint:S1008
 		return true
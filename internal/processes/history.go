@@ -0,0 +1,251 @@
+package processes
+
+import (
+	"math"
+	"slices"
+	"sync"
+	"time"
+)
+
+// How many samples to keep per process. With one sample per tracker update,
+// this is five minutes, which is more than fits in any chart we draw.
+const HISTORY_LENGTH = 300
+
+type HistorySample struct {
+	Timestamp time.Time
+	CpuTime   time.Duration // Since the process started
+	RssKb     int
+}
+
+// How a HistorySample is stored, relative to the History's base values. 12
+// bytes rather than the 40 of a HistorySample, we keep a lot of these.
+type compactSample struct {
+	timeMs uint32 // Since baseTime
+	cpuMs  uint32 // Since baseCpuTime
+	rssKb  uint32
+}
+
+// Recent samples for one process, oldest first. Shared between all snapshots of
+// the same process, so it must be safe to read while the tracker is adding to
+// it.
+type History struct {
+	mutex sync.Mutex
+
+	// Ring buffer, next is where the next sample goes. Grows until it reaches
+	// HISTORY_LENGTH, then wraps around.
+	samples []compactSample
+	next    int
+
+	// What the samples are relative to
+	baseTime    time.Time
+	baseCpuTime time.Duration
+
+	// Downsampled RSS samples covering the whole time we have been watching
+	// this process, for leak detection. See addLongTerm().
+	longTerm         []rssSample
+	longTermBase     time.Time // What the longTerm samples are relative to
+	longTermInterval time.Duration
+	longTermAdded    int // Bumped whenever longTerm changes
+
+	// Computed on demand by MemoryTrend(), valid if memoryTrendFor equals
+	// longTermAdded
+	memoryTrend    MemoryTrend
+	memoryTrendFor int
+}
+
+func (h *History) add(sample HistorySample) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.addLongTerm(sample)
+
+	if len(h.samples) == 0 {
+		h.baseTime = sample.Timestamp
+		h.baseCpuTime = sample.CpuTime
+	}
+	compact, fits := h.compact(sample)
+	if !fits {
+		h.rebase(sample)
+		compact, _ = h.compact(sample)
+	}
+
+	if len(h.samples) < HISTORY_LENGTH {
+		h.samples = append(h.samples, compact)
+		return
+	}
+
+	h.samples[h.next] = compact
+	h.next = (h.next + 1) % HISTORY_LENGTH
+}
+
+// If fits is false, the sample was clamped to fit relative to our base values
+func (h *History) compact(sample HistorySample) (compact compactSample, fits bool) {
+	timeMs := sample.Timestamp.Sub(h.baseTime).Milliseconds()
+	cpuMs := (sample.CpuTime - h.baseCpuTime).Milliseconds()
+
+	fits = timeMs >= 0 && timeMs <= math.MaxUint32 && cpuMs >= 0 && cpuMs <= math.MaxUint32
+	return compactSample{
+		timeMs: uint32(min(max(timeMs, 0), math.MaxUint32)),
+		cpuMs:  uint32(min(max(cpuMs, 0), math.MaxUint32)),
+		rssKb:  uint32(min(max(int64(sample.RssKb), 0), math.MaxUint32)),
+	}, fits
+}
+
+func (h *History) expand(compact compactSample) HistorySample {
+	return HistorySample{
+		Timestamp: h.baseTime.Add(time.Duration(compact.timeMs) * time.Millisecond),
+		CpuTime:   h.baseCpuTime + time.Duration(compact.cpuMs)*time.Millisecond,
+		RssKb:     int(compact.rssKb),
+	}
+}
+
+// Make our samples relative to the oldest one, needed after about 49 days of
+// wall clock or CPU time. If the new sample is too far from even the oldest
+// one, start over from the new sample.
+func (h *History) rebase(sample HistorySample) {
+	expanded := make([]HistorySample, len(h.samples))
+	for i, compact := range h.samples {
+		expanded[i] = h.expand(compact)
+	}
+
+	oldest := expanded[h.next]
+	h.baseTime = oldest.Timestamp
+	h.baseCpuTime = oldest.CpuTime
+	if _, fits := h.compact(sample); !fits {
+		h.samples = h.samples[:0]
+		h.next = 0
+		h.baseTime = sample.Timestamp
+		h.baseCpuTime = sample.CpuTime
+		return
+	}
+
+	for i, expandedSample := range expanded {
+		h.samples[i], _ = h.compact(expandedSample)
+	}
+}
+
+// Forget all samples taken at or after timestamp
+func (h *History) dropFrom(timestamp time.Time) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	// Put the ring in order, oldest first, so that we can cut off the end
+	if h.next > 0 {
+		h.samples = slices.Concat(h.samples[h.next:], h.samples[:h.next])
+		h.next = 0
+	}
+	for len(h.samples) > 0 && !h.expand(h.samples[len(h.samples)-1]).Timestamp.Before(timestamp) {
+		h.samples = h.samples[:len(h.samples)-1]
+	}
+
+	kept := len(h.longTerm)
+	for kept > 0 && !h.longTermTimestamp(h.longTerm[kept-1]).Before(timestamp) {
+		kept--
+	}
+	if kept < len(h.longTerm) {
+		h.longTerm = h.longTerm[:kept]
+		h.longTermAdded++
+	}
+}
+
+func (h *History) isEmpty() bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return len(h.samples) == 0
+}
+
+// Returns a copy of all samples, oldest first
+func (h *History) Samples() []HistorySample {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	samples := make([]HistorySample, 0, len(h.samples))
+	for _, compact := range h.samples[h.next:] {
+		samples = append(samples, h.expand(compact))
+	}
+	for _, compact := range h.samples[:h.next] {
+		samples = append(samples, h.expand(compact))
+	}
+	return samples
+}
+
+// Returns copies of the samples from start to end, oldest first. Includes the
+// last sample before start, if any, so that the caller can tell what happened
+// at the start.
+//
+// Walks backwards from the newest sample, so that short windows don't need to
+// look at the whole history.
+func (h *History) SamplesBetween(start time.Time, end time.Time) []HistorySample {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	var reversed []HistorySample
+	for i := range h.samples {
+		// Newest first
+		sample := h.expand(h.samples[(h.next-1-i+2*len(h.samples))%len(h.samples)])
+		if sample.Timestamp.After(end) {
+			// When rewinding, the history goes on past what we're showing
+			continue
+		}
+
+		reversed = append(reversed, sample)
+		if !sample.Timestamp.After(start) {
+			break
+		}
+	}
+
+	slices.Reverse(reversed)
+	return reversed
+}
+
+// How much RSS changed during the window ending at now. ok is false unless we
+// have at least two samples in the window.
+func (h *History) RssKbChange(window time.Duration, now time.Time) (change int, ok bool) {
+	var first *HistorySample
+	var last *HistorySample
+	for _, sample := range h.SamplesBetween(now.Add(-window), now) {
+		if sample.Timestamp.Before(now.Add(-window)) {
+			continue
+		}
+
+		if first == nil {
+			first = &sample
+		}
+		last = &sample
+	}
+
+	if first == nil || first == last {
+		return 0, false
+	}
+	return last.RssKb - first.RssKb, true
+}
+
+// nil if the tracker hasn't seen this process
+func (p *Process) History() *History {
+	return p.history
+}
+
+// Add one sample per process to its history. Processes we have seen before
+// (according to SameAs()) keep their history from last time, new processes get
+// a new one.
+func trackHistory(procs map[int]*Process, matching ProcessMatching, now time.Time) {
+	for _, proc := range procs {
+		match, found := matching.Matched[proc.Pid]
+		if found && match.Old.history != nil {
+			proc.history = match.Old.history
+		} else {
+			proc.history = &History{}
+		}
+
+		cpuTime := time.Duration(0)
+		if proc.CpuTimeTotal != nil {
+			cpuTime = *proc.CpuTimeTotal
+		}
+		proc.history.add(HistorySample{
+			Timestamp: now,
+			CpuTime:   cpuTime,
+			RssKb:     proc.RssKb,
+		})
+	}
+}
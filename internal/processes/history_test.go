@@ -0,0 +1,116 @@
+package processes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/walles/ftop/internal/assert"
+)
+
+func TestHistoryWrapsAround(t *testing.T) {
+	history := History{}
+	for i := range HISTORY_LENGTH + 2 {
+		history.add(HistorySample{RssKb: i})
+	}
+
+	samples := history.Samples()
+	assert.Equal(t, len(samples), HISTORY_LENGTH)
+	assert.Equal(t, samples[0].RssKb, 2)
+	assert.Equal(t, samples[len(samples)-1].RssKb, HISTORY_LENGTH+1)
+}
+
+func TestHistoryRebase(t *testing.T) {
+	t0 := time.Date(2026, 2, 18, 10, 0, 0, 0, time.UTC)
+	history := History{}
+
+	// Busy enough that the CPU time outgrows what we can store relative to the
+	// first sample
+	cpuStep := 10_000 * time.Second
+	for i := range 2 * HISTORY_LENGTH {
+		history.add(HistorySample{Timestamp: t0.Add(time.Duration(i) * time.Second), CpuTime: time.Duration(i) * cpuStep})
+	}
+
+	samples := history.Samples()
+	assert.Equal(t, len(samples), HISTORY_LENGTH)
+	assert.Equal(t, samples[0].Timestamp, t0.Add(HISTORY_LENGTH*time.Second))
+	assert.Equal(t, samples[0].CpuTime, HISTORY_LENGTH*cpuStep)
+	assert.Equal(t, samples[len(samples)-1].CpuTime, (2*HISTORY_LENGTH-1)*cpuStep)
+
+	// Too far from all other samples, start over
+	late := t0.Add(100 * 24 * time.Hour)
+	history.add(HistorySample{Timestamp: late, RssKb: 3})
+	assert.SlicesEqual(t, history.Samples(), []HistorySample{{Timestamp: late, RssKb: 3}})
+}
+
+func TestSamplesBetween(t *testing.T) {
+	t0 := time.Date(2026, 2, 18, 10, 0, 0, 0, time.UTC)
+	history := History{}
+	for i := range HISTORY_LENGTH + 2 {
+		history.add(HistorySample{Timestamp: t0.Add(time.Duration(i) * time.Second), RssKb: i})
+	}
+
+	rssKbs := func(samples []HistorySample) []int {
+		var result []int
+		for _, sample := range samples {
+			result = append(result, sample.RssKb)
+		}
+		return result
+	}
+
+	// Includes the sample before the start, but nothing after the end
+	assert.SlicesEqual(t, rssKbs(history.SamplesBetween(t0.Add(297500*time.Millisecond), t0.Add(299*time.Second))), []int{297, 298, 299})
+
+	// Starting before the oldest sample gets us all of them
+	assert.Equal(t, len(history.SamplesBetween(t0, t0.Add(time.Hour))), HISTORY_LENGTH)
+}
+
+func TestTrackHistory(t *testing.T) {
+	startTime := time.Date(2026, 2, 18, 10, 0, 0, 0, time.UTC)
+	t0 := startTime.Add(time.Minute)
+
+	previous := map[int]*Process{
+		1: {Pid: 1, startTime: startTime, RssKb: 100},
+		2: {Pid: 2, startTime: startTime, RssKb: 200},
+	}
+	trackHistory(previous, ProcessMatching{}, t0)
+
+	current := map[int]*Process{
+		1: {Pid: 1, startTime: startTime, RssKb: 110},
+
+		// PID reused by a new process
+		2: {Pid: 2, startTime: t0, RssKb: 50},
+	}
+	matches, err := buildProcessMatches(previous, current)
+	assert.Equal(t, err, nil)
+	trackHistory(current, matches, t0.Add(time.Second))
+
+	assert.SlicesEqual(t, current[1].History().Samples(), []HistorySample{
+		{Timestamp: t0, RssKb: 100},
+		{Timestamp: t0.Add(time.Second), RssKb: 110},
+	})
+	assert.SlicesEqual(t, current[2].History().Samples(), []HistorySample{
+		{Timestamp: t0.Add(time.Second), RssKb: 50},
+	})
+}
+
+func TestRssKbChange(t *testing.T) {
+	t0 := time.Now()
+	history := &History{}
+	for i, rssKb := range []int{100, 150, 130, 200} {
+		history.add(HistorySample{Timestamp: t0.Add(time.Duration(i) * 30 * time.Second), RssKb: rssKb})
+	}
+	now := t0.Add(90 * time.Second)
+
+	change, ok := history.RssKbChange(60*time.Second, now)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, change, 200-150)
+
+	// Rewound, only 100 and 150 are from before then
+	change, ok = history.RssKbChange(60*time.Second, t0.Add(30*time.Second))
+	assert.Equal(t, ok, true)
+	assert.Equal(t, change, 50)
+
+	// Only one sample in the window
+	_, ok = history.RssKbChange(time.Second, now)
+	assert.Equal(t, ok, false)
+}
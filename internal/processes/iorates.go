@@ -0,0 +1,25 @@
+package processes
+
+import "time"
+
+// Compute IoBytesPerSecond for all processes that were alive both last time
+// and this time.
+func fillInIoRates(matching ProcessMatching, elapsed time.Duration) {
+	if elapsed <= 0 {
+		return
+	}
+
+	for _, match := range matching.Matched {
+		if match.Old.ioBytes == nil || match.New.ioBytes == nil {
+			continue
+		}
+
+		if *match.New.ioBytes < *match.Old.ioBytes {
+			// Should never happen, but let's not report a huge number if it does
+			continue
+		}
+
+		bytesPerSecond := float64(*match.New.ioBytes-*match.Old.ioBytes) / elapsed.Seconds()
+		match.New.IoBytesPerSecond = &bytesPerSecond
+	}
+}
@@ -0,0 +1,39 @@
+package processes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/walles/ftop/internal/assert"
+)
+
+func TestFillInIoRates(t *testing.T) {
+	startTime := time.Date(2026, 2, 18, 10, 0, 0, 0, time.UTC)
+	oldBytes := uint64(1000)
+	newBytes := uint64(3000)
+
+	previous := map[int]*Process{
+		1: {Pid: 1, startTime: startTime, ioBytes: &oldBytes},
+		2: {Pid: 2, startTime: startTime},
+	}
+	current := map[int]*Process{
+		1: {Pid: 1, startTime: startTime, ioBytes: &newBytes},
+		2: {Pid: 2, startTime: startTime},
+		3: {Pid: 3, startTime: startTime, ioBytes: &newBytes},
+	}
+
+	matches, err := buildProcessMatches(previous, current)
+	assert.Equal(t, err, nil)
+
+	fillInIoRates(matches, 2*time.Second)
+
+	assert.Equal(t, *current[1].IoBytesPerSecond, 1000.0)
+	assert.Equal(t, current[1].IoRateString(), "1000B/s")
+
+	// No IO counters available
+	assert.Equal(t, current[2].IoBytesPerSecond == nil, true)
+	assert.Equal(t, current[2].IoRateString(), "--")
+
+	// New process, nothing to compare with
+	assert.Equal(t, current[3].IoBytesPerSecond == nil, true)
+}
@@ -0,0 +1,178 @@
+package processes
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+// How many downsampled RSS samples to keep per process for leak detection.
+// When this fills up, we drop every other sample and halve the sampling rate,
+// so that we always cover the whole time we have been watching the process.
+const LONG_TERM_HISTORY_LENGTH = 120
+
+// Initial time between downsampled RSS samples
+const LONG_TERM_HISTORY_INTERVAL = 15 * time.Second
+
+// Don't call anything a leak until we have watched it for this long. Lots of
+// processes grow for a while after starting up, and then level off.
+const LEAK_MIN_DURATION = 10 * time.Minute
+
+// A leak suspect must have grown by at least this much...
+const LEAK_MIN_GROWTH_KB = 10 * 1024
+
+// ... and by at least this fraction of its initial RSS
+const LEAK_MIN_GROWTH_FRACTION = 0.2
+
+// This fraction of the downsampled RSS samples must be at least as large as the
+// one before it for us to call the growth monotonic. Not 1.0, since garbage
+// collectors will make RSS go down every now and then, even in leaking
+// processes.
+const LEAK_MIN_MONOTONICITY = 0.8
+
+// Downsampled RSS sample, 8 bytes rather than the 32 a time.Time and an int
+// would need
+type rssSample struct {
+	seconds uint32 // Since History.longTermBase
+	rssKb   int32
+}
+
+type MemoryTrend struct {
+	// Robust estimate of RSS growth rate, based on the median of the slopes
+	// between all pairs of samples. Negative for shrinking processes.
+	KbPerMinute float64
+
+	// How long we have been watching this process' RSS
+	Duration time.Duration
+
+	// True if RSS has been growing long enough, monotonically enough and by
+	// enough to look like a memory leak
+	IsLeakSuspect bool
+}
+
+// Add a sample to the long term history if enough time has passed since the
+// last one. The caller must hold the history lock.
+func (h *History) addLongTerm(sample HistorySample) {
+	if h.longTermInterval == 0 {
+		h.longTermInterval = LONG_TERM_HISTORY_INTERVAL
+	}
+
+	if len(h.longTerm) > 0 {
+		last := h.longTerm[len(h.longTerm)-1]
+		if sample.Timestamp.Sub(h.longTermTimestamp(last)) < h.longTermInterval {
+			return
+		}
+	} else {
+		h.longTermBase = sample.Timestamp
+	}
+
+	if len(h.longTerm) >= LONG_TERM_HISTORY_LENGTH {
+		// Keep every other sample, starting with the oldest one
+		kept := h.longTerm[:0]
+		for i := 0; i < len(h.longTerm); i += 2 {
+			kept = append(kept, h.longTerm[i])
+		}
+		h.longTerm = kept
+		h.longTermInterval *= 2
+	}
+
+	seconds := max(0, sample.Timestamp.Sub(h.longTermBase)/time.Second)
+	h.longTerm = append(h.longTerm, rssSample{
+		seconds: uint32(min(seconds, math.MaxUint32)),
+		rssKb:   int32(min(sample.RssKb, math.MaxInt32)),
+	})
+	h.longTermAdded++
+}
+
+func (h *History) longTermTimestamp(sample rssSample) time.Time {
+	return h.longTermBase.Add(time.Duration(sample.seconds) * time.Second)
+}
+
+// RSS trend over all the time we have been watching this process.
+//
+// Computing the trend is expensive, so we do it only for processes somebody
+// asks about, and only once per long term sample.
+func (h *History) MemoryTrend() MemoryTrend {
+	h.mutex.Lock()
+	if h.memoryTrendFor == h.longTermAdded {
+		trend := h.memoryTrend
+		h.mutex.Unlock()
+		return trend
+	}
+	samples := slices.Clone(h.longTerm)
+	added := h.longTermAdded
+	h.mutex.Unlock()
+
+	// Don't block the tracker while computing
+	trend := computeMemoryTrend(samples)
+
+	h.mutex.Lock()
+	if h.longTermAdded == added {
+		h.memoryTrend = trend
+		h.memoryTrendFor = added
+	}
+	h.mutex.Unlock()
+
+	return trend
+}
+
+// Compute a Theil-Sen trend for some RSS samples. Theil-Sen is the median of
+// the slopes between all pairs of points, which makes it insensitive to the
+// occasional spike or garbage collection.
+func computeMemoryTrend(samples []rssSample) MemoryTrend {
+	if len(samples) < 2 {
+		return MemoryTrend{}
+	}
+
+	first := samples[0]
+	last := samples[len(samples)-1]
+	duration := time.Duration(last.seconds-first.seconds) * time.Second
+
+	slopes := make([]float64, 0, len(samples)*(len(samples)-1)/2)
+	for i := range samples {
+		for j := i + 1; j < len(samples); j++ {
+			if samples[j].seconds <= samples[i].seconds {
+				continue
+			}
+			minutes := float64(samples[j].seconds-samples[i].seconds) / 60
+			slopes = append(slopes, float64(int64(samples[j].rssKb)-int64(samples[i].rssKb))/minutes)
+		}
+	}
+	if len(slopes) == 0 {
+		return MemoryTrend{Duration: duration}
+	}
+
+	slices.Sort(slopes)
+	median := slopes[len(slopes)/2]
+	if len(slopes)%2 == 0 {
+		median = (slopes[len(slopes)/2-1] + slopes[len(slopes)/2]) / 2
+	}
+
+	nonDecreasing := 0
+	for i := 1; i < len(samples); i++ {
+		if samples[i].rssKb >= samples[i-1].rssKb {
+			nonDecreasing++
+		}
+	}
+	monotonicity := float64(nonDecreasing) / float64(len(samples)-1)
+
+	growthKb := median * duration.Minutes()
+
+	return MemoryTrend{
+		KbPerMinute: median,
+		Duration:    duration,
+		IsLeakSuspect: duration >= LEAK_MIN_DURATION &&
+			monotonicity >= LEAK_MIN_MONOTONICITY &&
+			growthKb >= LEAK_MIN_GROWTH_KB &&
+			growthKb >= LEAK_MIN_GROWTH_FRACTION*float64(first.rssKb),
+	}
+}
+
+// RSS trend for this process. Zero if the tracker hasn't seen it.
+func (p *Process) MemoryTrend() MemoryTrend {
+	if p.history == nil {
+		return MemoryTrend{}
+	}
+
+	return p.history.MemoryTrend()
+}
@@ -0,0 +1,96 @@
+package processes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/walles/ftop/internal/assert"
+)
+
+func TestComputeMemoryTrend_Leaking(t *testing.T) {
+	// Grows by 1MB per minute for 40 minutes, with a garbage collection every
+	// ten minutes
+	samples := []rssSample{}
+	for minute := range 41 {
+		rssKb := 100_000 + minute*1024
+		if minute%10 == 5 {
+			rssKb -= 3000
+		}
+		samples = append(samples, rssSample{seconds: uint32(minute * 60), rssKb: int32(rssKb)})
+	}
+
+	trend := computeMemoryTrend(samples)
+	assert.Equal(t, trend.KbPerMinute, 1024.0)
+	assert.Equal(t, trend.Duration, 40*time.Minute)
+	assert.Equal(t, trend.IsLeakSuspect, true)
+}
+
+func TestComputeMemoryTrend_TooShort(t *testing.T) {
+	trend := computeMemoryTrend([]rssSample{
+		{seconds: 0, rssKb: 1000},
+		{seconds: 60, rssKb: 100_000},
+	})
+	assert.Equal(t, trend.KbPerMinute, 99_000.0)
+	assert.Equal(t, trend.IsLeakSuspect, false)
+}
+
+func TestComputeMemoryTrend_Flat(t *testing.T) {
+	// Noisy but not growing
+	samples := []rssSample{}
+	for minute := range 30 {
+		samples = append(samples, rssSample{seconds: uint32(minute * 60), rssKb: int32(100_000 + (minute%3)*5000)})
+	}
+
+	trend := computeMemoryTrend(samples)
+	assert.Equal(t, trend.IsLeakSuspect, false)
+}
+
+func TestComputeMemoryTrend_OneBigStep(t *testing.T) {
+	// Loaded a big file once, that's not a leak
+	samples := []rssSample{}
+	for minute := range 30 {
+		rssKb := 100_000
+		if minute >= 20 {
+			rssKb = 500_000
+		}
+		samples = append(samples, rssSample{seconds: uint32(minute * 60), rssKb: int32(rssKb)})
+	}
+
+	trend := computeMemoryTrend(samples)
+	assert.Equal(t, trend.KbPerMinute, 0.0)
+	assert.Equal(t, trend.IsLeakSuspect, false)
+}
+
+func TestHistoryLongTermDownsampling(t *testing.T) {
+	t0 := time.Date(2026, 2, 18, 10, 0, 0, 0, time.UTC)
+
+	history := History{}
+	for second := range 3 * LONG_TERM_HISTORY_LENGTH * int(LONG_TERM_HISTORY_INTERVAL.Seconds()) {
+		history.add(HistorySample{Timestamp: t0.Add(time.Duration(second) * time.Second), RssKb: second})
+	}
+
+	// Should have been compacted twice
+	assert.Equal(t, history.longTermInterval, 4*LONG_TERM_HISTORY_INTERVAL)
+	assert.Equal(t, len(history.longTerm) <= LONG_TERM_HISTORY_LENGTH, true)
+
+	// And we should still cover the whole time
+	assert.Equal(t, history.longTermTimestamp(history.longTerm[0]), t0)
+	assert.Equal(t, history.MemoryTrend().Duration > 85*time.Minute, true)
+	assert.Equal(t, history.MemoryTrend().KbPerMinute, 60.0)
+}
+
+func TestMemoryTrendIsComputedLazily(t *testing.T) {
+	t0 := time.Date(2026, 2, 18, 10, 0, 0, 0, time.UTC)
+
+	history := History{}
+	history.add(HistorySample{Timestamp: t0, RssKb: 1000})
+	history.add(HistorySample{Timestamp: t0.Add(LONG_TERM_HISTORY_INTERVAL), RssKb: 1100})
+	assert.Equal(t, history.memoryTrendFor, 0)
+
+	assert.Equal(t, history.MemoryTrend().KbPerMinute, 400.0)
+	assert.Equal(t, history.memoryTrendFor, 2)
+
+	// Too soon for the long term history, the cached trend is still valid
+	history.add(HistorySample{Timestamp: t0.Add(LONG_TERM_HISTORY_INTERVAL + time.Second), RssKb: 5000})
+	assert.Equal(t, history.memoryTrendFor, history.longTermAdded)
+}
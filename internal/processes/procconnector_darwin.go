@@ -0,0 +1,17 @@
+package processes
+
+import (
+	"errors"
+	"time"
+)
+
+// There is no proc connector on macOS, we only poll there
+type procConnector struct{}
+
+func startProcConnector() (*procConnector, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (c *procConnector) takeLaunched(now time.Time) []launchedProcess {
+	return nil
+}
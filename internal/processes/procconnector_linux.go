@@ -0,0 +1,288 @@
+package processes
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/walles/ftop/internal/log"
+	"golang.org/x/sys/unix"
+)
+
+// From linux/connector.h and linux/cn_proc.h, see "man 7 netlink" and
+// https://docs.kernel.org/driver-api/connector.html
+const (
+	cnIdxProc         = 0x1
+	cnValProc         = 0x1
+	procCnMcastListen = 1
+
+	procEventNone = 0x0 // Acks our listen request
+	procEventFork = 0x1
+	procEventExec = 0x2
+	procEventExit = 0x80000000
+
+	cnMsgHeaderLength     = 20 // struct cn_msg without its data
+	procEventHeaderLength = 16 // what, cpu and timestamp_ns
+)
+
+// If the tracker stops collecting launches, don't collect more than this
+const maxPendingLaunches = 10_000
+
+// Processes usually exec whatever they were forked for right away. Give them
+// this long to do that before handing them to the tracker.
+const launchSettleTime = 2 * time.Second
+
+// Tells us about processes as they are launched, so that we see them even if
+// they exit before the next tracker update. Needs CAP_NET_ADMIN, which in
+// practice means root.
+type procConnector struct {
+	fd int
+
+	mutex    sync.Mutex
+	launched []launchedProcess
+	byPid    map[int]int // Indices into launched
+}
+
+// One process event from the kernel
+type procEvent struct {
+	what uint32
+
+	// The process the event is about, the child for forks
+	tgid int
+
+	// If true, the event is about a thread rather than a process
+	isThread bool
+
+	parentTgid int    // Forks only
+	ackErrno   uint32 // procEventNone only
+}
+
+func startProcConnector() (*procConnector, error) {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, unix.NETLINK_CONNECTOR)
+	if err != nil {
+		return nil, err
+	}
+
+	connector := &procConnector{fd: fd, byPid: make(map[int]int)}
+	err = connector.listen()
+	if err != nil {
+		_ = unix.Close(fd)
+		return nil, err
+	}
+
+	go func() {
+		defer func() {
+			log.PanicHandler("proc connector", recover(), debug.Stack())
+		}()
+
+		err := connector.receive()
+		log.Infof("Stopped listening for process events, short-lived processes may go unnoticed: %v", err)
+		_ = unix.Close(fd)
+	}()
+
+	return connector, nil
+}
+
+// Ask the kernel to send us process events
+func (c *procConnector) listen() error {
+	err := unix.Bind(c.fd, &unix.SockaddrNetlink{Family: unix.AF_NETLINK, Groups: cnIdxProc})
+	if err != nil {
+		return err
+	}
+
+	// Fork storms can be bursty, make room for them
+	err = unix.SetsockoptInt(c.fd, unix.SOL_SOCKET, unix.SO_RCVBUF, 1024*1024)
+	if err != nil {
+		log.Debugf("Failed to enlarge the process events receive buffer: %v", err)
+	}
+
+	// A netlink header, followed by a cn_msg with our request in it
+	request := make([]byte, unix.NLMSG_HDRLEN+cnMsgHeaderLength+4)
+	binary.NativeEndian.PutUint32(request[0:], uint32(len(request)))
+	binary.NativeEndian.PutUint16(request[4:], unix.NLMSG_DONE)
+	binary.NativeEndian.PutUint32(request[12:], uint32(os.Getpid()))
+
+	cnMsg := request[unix.NLMSG_HDRLEN:]
+	binary.NativeEndian.PutUint32(cnMsg[0:], cnIdxProc)
+	binary.NativeEndian.PutUint32(cnMsg[4:], cnValProc)
+	binary.NativeEndian.PutUint32(cnMsg[12:], 1) // Ack, so that we hear if this fails
+	binary.NativeEndian.PutUint16(cnMsg[16:], 4) // Data length
+	binary.NativeEndian.PutUint32(cnMsg[cnMsgHeaderLength:], procCnMcastListen)
+
+	return unix.Sendto(c.fd, request, 0, &unix.SockaddrNetlink{Family: unix.AF_NETLINK})
+}
+
+// Returns when we can't receive any more events
+func (c *procConnector) receive() error {
+	buffer := make([]byte, os.Getpagesize())
+	for {
+		n, _, err := unix.Recvfrom(c.fd, buffer, 0)
+		if errors.Is(err, unix.ENOBUFS) {
+			// We were too slow and the kernel dropped events. Polling will
+			// still see the processes that are alive.
+			log.Debugf("Process events were dropped, short-lived processes may go unnoticed")
+			continue
+		}
+		if errors.Is(err, unix.EINTR) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		messages, err := syscall.ParseNetlinkMessage(buffer[:n])
+		if err != nil {
+			return err
+		}
+
+		for _, message := range messages {
+			event, err := parseProcEvent(message.Data)
+			if err != nil {
+				return err
+			}
+
+			err = c.handle(event, time.Now())
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Parse the data part of a netlink message, a cn_msg with a proc_event in it
+func parseProcEvent(data []byte) (procEvent, error) {
+	const minLength = cnMsgHeaderLength + procEventHeaderLength + 16
+	if len(data) < minLength {
+		return procEvent{}, fmt.Errorf("process event too short, %d bytes < %d", len(data), minLength)
+	}
+
+	eventData := data[cnMsgHeaderLength+procEventHeaderLength:]
+	field := func(index int) int {
+		return int(binary.NativeEndian.Uint32(eventData[index*4:]))
+	}
+
+	event := procEvent{what: binary.NativeEndian.Uint32(data[cnMsgHeaderLength:])}
+	switch event.what {
+	case procEventNone:
+		event.ackErrno = binary.NativeEndian.Uint32(eventData)
+	case procEventFork:
+		// Parent PID, parent TGID, child PID, child TGID
+		event.parentTgid = field(1)
+		event.tgid = field(3)
+		event.isThread = field(2) != field(3)
+	case procEventExec, procEventExit:
+		// PID, TGID
+		event.tgid = field(1)
+		event.isThread = field(0) != field(1)
+	}
+
+	return event, nil
+}
+
+func (c *procConnector) handle(event procEvent, now time.Time) error {
+	if event.what == procEventNone {
+		if event.ackErrno != 0 {
+			return fmt.Errorf("kernel refused to send process events: %w", syscall.Errno(event.ackErrno))
+		}
+
+		log.Debugf("Listening for process events")
+		return nil
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	switch event.what {
+	case procEventFork:
+		if event.isThread {
+			return nil
+		}
+		if len(c.launched) >= maxPendingLaunches {
+			return nil
+		}
+
+		c.byPid[event.tgid] = len(c.launched)
+		c.launched = append(c.launched, launchedProcess{
+			pid:       event.tgid,
+			ppid:      event.parentTgid,
+			cmdline:   readCmdline(event.tgid),
+			startTime: now,
+		})
+
+	case procEventExec:
+		// For multi threaded processes, the exec event may come from any
+		// thread, but TGID is the process
+		index, found := c.byPid[event.tgid]
+		if !found {
+			// Launched before we started listening, the tracker knows about it
+			return nil
+		}
+		if cmdline := readCmdline(event.tgid); cmdline != "" {
+			c.launched[index].cmdline = cmdline
+		}
+
+	case procEventExit:
+		if event.isThread {
+			return nil
+		}
+		if index, found := c.byPid[event.tgid]; found {
+			c.launched[index].exited = true
+		}
+	}
+
+	return nil
+}
+
+// Launches that have exited or are older than launchSettleTime. The rest stay
+// with us until then, in case they exec something new.
+func (c *procConnector) takeLaunched(now time.Time) []launchedProcess {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var settled []launchedProcess
+	var unsettled []launchedProcess
+	clear(c.byPid)
+	for _, launch := range c.launched {
+		if launch.exited || now.Sub(launch.startTime) > launchSettleTime {
+			settled = append(settled, launch)
+			continue
+		}
+
+		c.byPid[launch.pid] = len(unsettled)
+		unsettled = append(unsettled, launch)
+	}
+	c.launched = unsettled
+
+	return settled
+}
+
+// Empty if the process is already gone
+func readCmdline(pid int) string {
+	pidDir := filepath.Join("/proc", strconv.Itoa(pid))
+	cmdline, err := os.ReadFile(filepath.Join(pidDir, "cmdline"))
+	if err != nil {
+		return ""
+	}
+	if len(cmdline) > 0 {
+		return procCmdlineToString(string(cmdline), procPidStat{})
+	}
+
+	// No command line, probably because the process has already exited. Go
+	// for the executable name from the stat file.
+	statBytes, err := os.ReadFile(filepath.Join(pidDir, "stat"))
+	if err != nil {
+		return ""
+	}
+	stat, err := parseProcPidStat(string(statBytes))
+	if err != nil {
+		return ""
+	}
+	return stat.comm
+}
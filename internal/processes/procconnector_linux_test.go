@@ -0,0 +1,67 @@
+package processes
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/walles/ftop/internal/assert"
+)
+
+// A cn_msg with a proc_event in it, as the kernel would send it
+func procEventData(what uint32, fields ...uint32) []byte {
+	data := make([]byte, cnMsgHeaderLength+procEventHeaderLength+24)
+	binary.NativeEndian.PutUint32(data[cnMsgHeaderLength:], what)
+	for i, field := range fields {
+		binary.NativeEndian.PutUint32(data[cnMsgHeaderLength+procEventHeaderLength+i*4:], field)
+	}
+	return data
+}
+
+func TestParseProcEvent(t *testing.T) {
+	event, err := parseProcEvent(procEventData(procEventFork, 100, 100, 200, 200))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, event, procEvent{what: procEventFork, tgid: 200, parentTgid: 100})
+
+	// A new thread in process 100
+	event, err = parseProcEvent(procEventData(procEventFork, 100, 100, 201, 100))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, event.isThread, true)
+
+	event, err = parseProcEvent(procEventData(procEventExit, 200, 200, 0, 0))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, event, procEvent{what: procEventExit, tgid: 200})
+
+	event, err = parseProcEvent(procEventData(procEventNone, 1))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, event.ackErrno, uint32(1))
+
+	_, err = parseProcEvent(make([]byte, 10))
+	assert.Equal(t, err != nil, true)
+}
+
+func TestProcConnectorTakeLaunched(t *testing.T) {
+	connector := &procConnector{byPid: make(map[int]int)}
+	now := time.Now()
+
+	// PIDs that don't exist, so that we don't read any command lines
+	assert.Equal(t, connector.handle(procEvent{what: procEventFork, tgid: -2, parentTgid: 1}, now), nil)
+	assert.Equal(t, connector.handle(procEvent{what: procEventFork, tgid: -3, parentTgid: 1}, now), nil)
+	assert.Equal(t, connector.handle(procEvent{what: procEventExit, tgid: -2}, now), nil)
+
+	// Only the exited one is settled
+	launched := connector.takeLaunched(now)
+	assert.Equal(t, len(launched), 1)
+	assert.Equal(t, launched[0].pid, -2)
+	assert.Equal(t, launched[0].exited, true)
+
+	// Exit events still find launches that weren't taken
+	assert.Equal(t, connector.handle(procEvent{what: procEventExit, tgid: -3}, now), nil)
+	launched = connector.takeLaunched(now)
+	assert.Equal(t, len(launched), 1)
+	assert.Equal(t, launched[0].pid, -3)
+
+	// Unless they exit, launches are handed over after launchSettleTime
+	assert.Equal(t, connector.handle(procEvent{what: procEventFork, tgid: -4, parentTgid: 1}, now), nil)
+	assert.Equal(t, len(connector.takeLaunched(now.Add(launchSettleTime+time.Second))), 1)
+}
@@ -25,6 +25,11 @@ var hiddenSelfChildCommands = map[string]struct{}{
 // How old children count towards a process' nativity?
 const NATIVITY_MAX_AGE = 60 * time.Second
 
+// How many generations of descendants count towards a process'
+// SubtreeNativity. Three is enough for brew -> sh -> curl style chains without
+// crediting init with everything.
+const SUBTREE_NATIVITY_MAX_DEPTH = 3
+
 // At least on macOS, ps' elapsed-time metric (etime) comes without decimals
 const ETIME_PRECISION = time.Second
 
@@ -36,6 +41,9 @@ const MAX_PS_DURATION = 5 * time.Second
 
 // Tolerance for matching same-PID processes across snapshots. If PIDs match and
 // start times match within this tolerance, we consider it the same process.
+//
+// Only used when we don't have exact start times, which we do when reading
+// /proc on Linux.
 const SAME_PROCESS_STARTTIME_TOLERANCE = ETIME_PRECISION + MAX_PS_DURATION
 
 type Process struct {
@@ -51,18 +59,93 @@ type Process struct {
 
 	startTime time.Time
 
+	// Clock ticks since boot, as reported by /proc/<pid>/stat. nil means we
+	// got our start time from ps and only know it with ETIME_PRECISION.
+	startTicks *uint64
+
 	Username string
 
+	// Container, Kubernetes pod or systemd unit this process belongs to, based
+	// on its cgroup. "nginx.service", "docker:0123456789ab" or
+	// "pod:5b1d6e0a-8f3c-4b5e-9d2a-0c1e2f3a4b5c" for example. Empty if unknown,
+	// which it always is on macOS.
+	Cgroup string
+
 	RssKb         int
 	memoryPercent *float64
 
+	// Proportional set size: RSS, but with shared pages split evenly between
+	// the processes sharing them. Unlike RSS, PSS adds up to something
+	// meaningful when summed over a bunch of processes. nil if unknown, which
+	// it is for other users' processes unless we are root, and always on
+	// macOS.
+	PssKb *int
+
+	// Unique set size, the memory we would get back by killing this process.
+	// nil if unknown, see PssKb.
+	UssKb *int
+
+	// How much of this process has been swapped out. nil if unknown, see PssKb.
+	SwapKb *int
+
 	cpuPercent   *float64
 	CpuTime      *time.Duration // Since ftop started
 	CpuTimeTotal *time.Duration // Since the process started
 
+	// CPU time used by children that have exited and been waited for. nil if
+	// unknown, which it always is on macOS.
+	ChildrenCpuTime      *time.Duration // Since ftop started
+	ChildrenCpuTimeTotal *time.Duration // Since the process started
+
+	// Bytes read from plus written to storage since the process started. nil
+	// if unknown, which it is for other users' processes unless we are root,
+	// and always on macOS.
+	ioBytes *uint64
+
+	// Storage bytes read and written per second since the previous tracker
+	// update. nil if unknown.
+	IoBytesPerSecond *float64
+
+	// One letter: R (running), S (sleeping), D (uninterruptible sleep), Z
+	// (zombie), T (stopped) or one of the rarer ones listed in "man 5 proc".
+	// Empty if unknown.
+	State string
+
+	// Kernel function a sleeping process is waiting in, "nfs_wait_bit_killable"
+	// for example. Empty if unknown or not waiting. Only available from /proc,
+	// ps truncates it to six characters.
+	Wchan string
+
+	// Scheduling niceness, -20 to 19. nil if unknown, which it always is on
+	// macOS.
+	Nice *int
+
+	// nil if unknown, which it always is on macOS
+	Threads *int
+
+	// Controlling terminal, like "pts/3". "?" means none, empty means unknown,
+	// which it always is on macOS.
+	Tty string
+
+	// Number of consecutive tracker updates this process has been in its
+	// current D or Z state, 0 if it's in some other state
+	stuckUpdates int
+
+	// When we first saw this process in its current D or Z state
+	stuckSince time.Time
+
+	// CPU and RAM usage over time, filled in by the tracker
+	history *History
+
 	// Count of children younger than NATIVITY_MAX_AGE
 	Nativity int
 
+	// Like Nativity, but also counting descendants down to
+	// SUBTREE_NATIVITY_MAX_DEPTH generations. Launches further down count
+	// more, so that for brew -> sh -> curl, brew ranks above the sh it runs
+	// the curls through.
+	SubtreeNativity int
+
 	// Birth timestamps for all now-dead children, used for nativity calculation
 	deadChildrenBirthTimes []time.Time
 }
@@ -77,6 +160,11 @@ func (p *Process) String() string {
 	return fmt.Sprintf("%s(%d)", p.Command(), p.Pid)
 }
 
+// PID of the parent process, 0 if there is none
+func (p *Process) Ppid() int {
+	return p.ppid
+}
+
 func (p *Process) Parent() *Process {
 	return p.parent
 }
@@ -173,11 +261,32 @@ func uidToUsername(uid int) string {
 type timeAnomalyError error
 
 func GetAll() ([]*Process, error) {
+	processes, err := getAllByPid()
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolve parent-child relationships
+	resolveLinks(processes)
+
+	// Without this our every-second calls to ps will mess up the launched
+	// commands view.
+	removeSelfChildren(processes, os.Getpid())
+
+	processList := make([]*Process, 0, len(processes))
+	for _, proc := range processes {
+		processList = append(processList, proc)
+	}
+	return processList, nil
+}
+
+// Get all processes by running ps. Works on both macOS and Linux.
+func getAllFromPs() (map[int]*Process, error) {
 	command := []string{
 		"/bin/ps",
 		"-ax",
 		"-o",
-		"pid=,ppid=,rss=,etime=,uid=,pcpu=,time=,%mem=,command=",
+		"pid=,ppid=,rss=,etime=,uid=,pcpu=,time=,%mem=,state=,command=",
 	}
 
 	processes := make(map[int]*Process, 0)
@@ -203,18 +312,7 @@ func GetAll() ([]*Process, error) {
 			util.FormatDuration(MAX_PS_DURATION)))
 	}
 
-	// Resolve parent-child relationships
-	resolveLinks(processes)
-
-	// Without this our every-second calls to ps will mess up the launched
-	// commands view.
-	removeSelfChildren(processes, os.Getpid())
-
-	processList := make([]*Process, 0, len(processes))
-	for _, proc := range processes {
-		processList = append(processList, proc)
-	}
-	return processList, nil
+	return processes, nil
 }
 
 // On entry, this function assumes that all processes have a "ppid" field
@@ -305,6 +403,37 @@ func fillInNativities(processes map[int]*Process) {
 
 		proc.Nativity = nativity
 	}
+
+	fillInSubtreeNativities(processes)
+}
+
+// Credit each process' recent launches to its ancestors, weighted by how many
+// generations up they are. Requires Nativity to be filled in already.
+func fillInSubtreeNativities(processes map[int]*Process) {
+	for _, proc := range processes {
+		proc.SubtreeNativity = 0
+	}
+
+	for _, proc := range processes {
+		if proc.Nativity == 0 {
+			continue
+		}
+
+		// Nativity counts proc's children, so proc itself is the first
+		// generation's ancestor. Without the weighting, a shell script and the
+		// process running it would tie.
+		ancestor := proc
+		for depth := 0; depth < SUBTREE_NATIVITY_MAX_DEPTH && ancestor != nil; depth++ {
+			ancestor.SubtreeNativity += proc.Nativity * (depth + 1)
+			ancestor = ancestor.parent
+		}
+	}
+}
+
+// Average CPU usage since the process started, as reported by ps. nil if
+// unknown.
+func (p *Process) CpuPercent() *float64 {
+	return p.cpuPercent
 }
 
 func (p *Process) CpuPercentString() string {
@@ -315,6 +444,16 @@ func (p *Process) CpuPercentString() string {
 	return fmt.Sprintf("%.0f%%", *p.cpuPercent)
 }
 
+// Our best estimate of how much RAM this process is using: PSS if we have it,
+// RSS otherwise.
+func (p *Process) PssOrRssKb() int {
+	if p.PssKb != nil {
+		return *p.PssKb
+	}
+
+	return p.RssKb
+}
+
 func (p *Process) RamPercentString() string {
 	if p.memoryPercent == nil {
 		return "--"
@@ -323,6 +462,39 @@ func (p *Process) RamPercentString() string {
 	return fmt.Sprintf("%.0f%%", *p.memoryPercent)
 }
 
+// Example return values: "--", "0.0B/s", "1.2MB/s"
+func (p *Process) IoRateString() string {
+	if p.IoBytesPerSecond == nil {
+		return "--"
+	}
+
+	return strings.TrimSuffix(util.FormatMemory(int64(*p.IoBytesPerSecond)), "B") + "B/s"
+}
+
+// CPU time since ftop started, including what exited children have used. This
+// is where build tools and package managers spend their CPU, through their
+// compilers and downloaders.
+//
+// nil if unknown.
+func (p *Process) CpuTimeInclChildren() *time.Duration {
+	if p.CpuTime == nil || p.ChildrenCpuTime == nil {
+		return nil
+	}
+
+	total := *p.CpuTime + *p.ChildrenCpuTime
+	return &total
+}
+
+// Like CpuTimeString(), but including exited children
+func (p *Process) CpuTimeInclChildrenString() string {
+	total := p.CpuTimeInclChildren()
+	if total == nil {
+		return "--"
+	}
+
+	return util.FormatDuration(*total)
+}
+
 // Converts cpuTime to a string. Example outputs:
 //
 //	45s
@@ -342,6 +514,11 @@ func (p *Process) SameAs(other *Process) bool {
 		return false
 	}
 
+	if p.startTicks != nil && other.startTicks != nil {
+		// Both start times are exact, no need for any tolerance
+		return *p.startTicks == *other.startTicks
+	}
+
 	delta := p.startTime.Sub(other.startTime).Abs()
 
 	return delta <= SAME_PROCESS_STARTTIME_TOLERANCE
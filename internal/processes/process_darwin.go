@@ -0,0 +1,6 @@
+package processes
+
+// There is no /proc on macOS, ps it is
+func getAllByPid() (map[int]*Process, error) {
+	return getAllFromPs()
+}
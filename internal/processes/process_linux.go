@@ -0,0 +1,335 @@
+package processes
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/walles/ftop/internal/log"
+)
+
+// Boot time doesn't change, but the btime value in /proc/stat can jitter by a
+// second when the system clock is adjusted. Reading it once keeps our start
+// times stable for the whole ftop run.
+var getBootTime = sync.OnceValues(func() (time.Time, error) {
+	procStat, err := os.ReadFile("/proc/stat")
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return parseProcStatBootTime(string(procStat))
+})
+
+// How often to re-read a process' smaps_rollup file. Reading it makes the
+// kernel walk all of the process' memory mappings, which is way more expensive
+// than reading stat.
+const SMAPS_ROLLUP_MAX_AGE = 10 * time.Second
+
+// How often to re-read a process' cgroup file. Processes rarely change cgroups,
+// so there's no point in reading it for every process on every update.
+const CGROUP_MAX_AGE = 60 * time.Second
+
+// Only used by getAllByPid(), which the tracker calls from one goroutine
+var liveSmapsRollups = newSmapsRollupCache()
+var liveCgroups = newCgroupCache()
+
+// Read the process list from /proc, falling back to ps if that fails
+func getAllByPid() (map[int]*Process, error) {
+	processes, err := getAllFromProc("/proc", liveSmapsRollups, liveCgroups)
+	if err == nil {
+		return processes, nil
+	}
+
+	log.Infof("Reading processes from /proc failed, falling back to ps: %v", err)
+	return getAllFromPs()
+}
+
+func getAllFromProc(procDir string, smapsRollups *smapsRollupCache, cgroups *cgroupCache) (map[int]*Process, error) {
+	bootTime, err := getBootTime()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get boot time: %w", err)
+	}
+
+	memInfo, err := os.ReadFile(filepath.Join(procDir, "meminfo"))
+	if err != nil {
+		return nil, err
+	}
+	memTotalKb, err := parseProcMemInfoTotalKb(string(memInfo))
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(procDir)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	pageSizeKb := os.Getpagesize() / 1024
+
+	smapsRollups.startUpdate()
+	defer smapsRollups.finishUpdate()
+	cgroups.startUpdate()
+	defer cgroups.finishUpdate()
+
+	processes := make(map[int]*Process, len(entries))
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			// Not a process directory
+			continue
+		}
+
+		proc, err := procPidToProcess(filepath.Join(procDir, entry.Name()), pid, bootTime, now, memTotalKb, pageSizeKb, smapsRollups, cgroups)
+		if err != nil {
+			if isProcessGone(err) {
+				// It died while we were looking at it, never mind
+				continue
+			}
+
+			log.Debugf("Failed to read process %d from %s: %v", pid, procDir, err)
+			continue
+		}
+
+		processes[pid] = proc
+	}
+
+	if len(processes) == 0 {
+		return nil, fmt.Errorf("no processes found in %s", procDir)
+	}
+
+	return processes, nil
+}
+
+func isProcessGone(err error) bool {
+	return errors.Is(err, os.ErrNotExist) || errors.Is(err, syscall.ESRCH)
+}
+
+func procPidToProcess(pidDir string, pid int, bootTime time.Time, now time.Time, memTotalKb int, pageSizeKb int, smapsRollups *smapsRollupCache, cgroups *cgroupCache) (*Process, error) {
+	statBytes, err := os.ReadFile(filepath.Join(pidDir, "stat"))
+	if err != nil {
+		return nil, err
+	}
+	stat, err := parseProcPidStat(string(statBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	statusBytes, err := os.ReadFile(filepath.Join(pidDir, "status"))
+	if err != nil {
+		return nil, err
+	}
+	uid, err := parseProcPidStatusUid(string(statusBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s/status: %w", pidDir, err)
+	}
+
+	cmdlineBytes, err := os.ReadFile(filepath.Join(pidDir, "cmdline"))
+	if err != nil {
+		return nil, err
+	}
+
+	// Only readable for our own processes unless we are root, so don't fail on
+	// this one.
+	var ioBytes *uint64
+	ioFileBytes, err := os.ReadFile(filepath.Join(pidDir, "io"))
+	if err == nil {
+		readBytes, writeBytes, err := parseProcPidIo(string(ioFileBytes))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s/io: %w", pidDir, err)
+		}
+
+		sum := readBytes + writeBytes
+		ioBytes = &sum
+	}
+
+	smapsRollup, err := smapsRollups.get(pidDir, pid, stat.startTicks, now)
+	if err != nil {
+		return nil, err
+	}
+
+	// The wait channel is what tells us why a process is stuck in D state.
+	// Don't read it for all the S state processes, there are a lot of those
+	// and it would cost us one extra file read per process and update.
+	wchan := ""
+	if stat.state == 'D' {
+		wchanBytes, err := os.ReadFile(filepath.Join(pidDir, "wchan"))
+		if err == nil {
+			wchan = parseProcPidWchan(string(wchanBytes))
+		}
+	}
+
+	cgroup := cgroups.get(pidDir, pid, stat.startTicks, now)
+
+	startTicks := stat.startTicks
+	startTime := bootTime.Add(ticksToDuration(startTicks))
+	cpuTime := ticksToDuration(stat.utimeTicks + stat.stimeTicks)
+	childrenCpuTime := ticksToDuration(stat.cutimeTicks + stat.cstimeTicks)
+	nice := stat.nice
+	threads := stat.numThreads
+
+	// Same as ps' pcpu on Linux: CPU time divided by process age
+	cpuPercent := 0.0
+	if age := now.Sub(startTime); age > 0 {
+		cpuPercent = 100.0 * cpuTime.Seconds() / age.Seconds()
+	}
+
+	rssKb := stat.rssPages * pageSizeKb
+	memoryPercent := 0.0
+	if memTotalKb > 0 {
+		memoryPercent = 100.0 * float64(rssKb) / float64(memTotalKb)
+	}
+
+	proc := &Process{
+		Pid:          pid,
+		ppid:         stat.ppid,
+		RssKb:        rssKb,
+		startTime:    startTime,
+		startTicks:   &startTicks,
+		Username:     uidToUsername(uid),
+		Cgroup:       cgroup,
+		cpuPercent:   &cpuPercent,
+		CpuTime:      &cpuTime,
+		CpuTimeTotal: &cpuTime,
+
+		ChildrenCpuTime:      &childrenCpuTime,
+		ChildrenCpuTimeTotal: &childrenCpuTime,
+
+		memoryPercent: &memoryPercent,
+		ioBytes:       ioBytes,
+		State:         string(stat.state),
+		Wchan:         wchan,
+		Nice:          &nice,
+		Threads:       &threads,
+		Tty:           ttyName(stat.ttyNr),
+		Cmdline:       procCmdlineToString(string(cmdlineBytes), stat),
+	}
+
+	if smapsRollup != nil {
+		proc.PssKb = &smapsRollup.pssKb
+		proc.UssKb = &smapsRollup.ussKb
+		proc.SwapKb = &smapsRollup.swapKb
+	}
+
+	return proc, nil
+}
+
+type smapsRollupCacheEntry struct {
+	startTicks uint64 // To tell reused PIDs apart
+	readTime   time.Time
+	rollup     *procPidSmapsRollup // nil if we couldn't read it
+}
+
+// Remembers smaps_rollup contents between updates, so that we don't have to
+// read them every time, see SMAPS_ROLLUP_MAX_AGE.
+type smapsRollupCache struct {
+	entries map[int]smapsRollupCacheEntry // From the previous update
+	next    map[int]smapsRollupCacheEntry // Being collected during this update
+}
+
+func newSmapsRollupCache() *smapsRollupCache {
+	return &smapsRollupCache{entries: make(map[int]smapsRollupCacheEntry)}
+}
+
+func (c *smapsRollupCache) startUpdate() {
+	c.next = make(map[int]smapsRollupCacheEntry, len(c.entries))
+}
+
+// Forget about processes we didn't see during this update
+func (c *smapsRollupCache) finishUpdate() {
+	c.entries = c.next
+	c.next = nil
+}
+
+// Cached smaps_rollup contents for a process, or fresh ones if the cached ones
+// are too old. nil if unreadable.
+func (c *smapsRollupCache) get(pidDir string, pid int, startTicks uint64, now time.Time) (*procPidSmapsRollup, error) {
+	entry, found := c.entries[pid]
+	if found && entry.startTicks == startTicks && now.Sub(entry.readTime) < SMAPS_ROLLUP_MAX_AGE {
+		c.next[pid] = entry
+		return entry.rollup, nil
+	}
+
+	readTime := now
+	if !found {
+		// Spread out the re-reads over time rather than doing all of them on
+		// the same update
+		readTime = now.Add(-time.Duration(pid%int(SMAPS_ROLLUP_MAX_AGE.Seconds())) * time.Second)
+	}
+
+	// Only readable for our own processes unless we are root, so don't fail on
+	// this one. Unreadable files are cached as nil, they won't become readable.
+	var rollup *procPidSmapsRollup
+	smapsRollupBytes, err := os.ReadFile(filepath.Join(pidDir, "smaps_rollup"))
+	if err == nil && len(smapsRollupBytes) > 0 {
+		// Kernel threads have empty smaps_rollup files
+		parsed, err := parseProcPidSmapsRollup(string(smapsRollupBytes))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s/smaps_rollup: %w", pidDir, err)
+		}
+		rollup = &parsed
+	}
+
+	c.next[pid] = smapsRollupCacheEntry{startTicks: startTicks, readTime: readTime, rollup: rollup}
+	return rollup, nil
+}
+
+type cgroupCacheEntry struct {
+	startTicks uint64 // To tell reused PIDs apart
+	readTime   time.Time
+	cgroup     string
+}
+
+// Remembers which cgroup each process is in between updates, see
+// CGROUP_MAX_AGE.
+type cgroupCache struct {
+	entries map[int]cgroupCacheEntry // From the previous update
+	next    map[int]cgroupCacheEntry // Being collected during this update
+}
+
+func newCgroupCache() *cgroupCache {
+	return &cgroupCache{entries: make(map[int]cgroupCacheEntry)}
+}
+
+func (c *cgroupCache) startUpdate() {
+	c.next = make(map[int]cgroupCacheEntry, len(c.entries))
+}
+
+// Forget about processes we didn't see during this update
+func (c *cgroupCache) finishUpdate() {
+	c.entries = c.next
+	c.next = nil
+}
+
+// Cached cgroup for a process, or a fresh one if the cached one is too old. ""
+// if unreadable.
+func (c *cgroupCache) get(pidDir string, pid int, startTicks uint64, now time.Time) string {
+	entry, found := c.entries[pid]
+	if found && entry.startTicks == startTicks && now.Sub(entry.readTime) < CGROUP_MAX_AGE {
+		c.next[pid] = entry
+		return entry.cgroup
+	}
+
+	readTime := now
+	if !found {
+		// Spread out the re-reads over time rather than doing all of them on
+		// the same update
+		readTime = now.Add(-time.Duration(pid%int(CGROUP_MAX_AGE.Seconds())) * time.Second)
+	}
+
+	// Can't fail for any reason other than the process being gone, but if it
+	// does, missing cgroup info is no reason to drop the whole process
+	cgroup := ""
+	cgroupBytes, err := os.ReadFile(filepath.Join(pidDir, "cgroup"))
+	if err == nil {
+		cgroup = cgroupPathToGroup(parseProcPidCgroup(string(cgroupBytes)))
+	}
+
+	c.next[pid] = cgroupCacheEntry{startTicks: startTicks, readTime: readTime, cgroup: cgroup}
+	return cgroup
+}
@@ -0,0 +1,111 @@
+package processes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/walles/ftop/internal/assert"
+)
+
+func TestGetAllFromProc(t *testing.T) {
+	procs, err := getAllFromProc("procfs_parser_test/proc", newSmapsRollupCache(), newCgroupCache())
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(procs), 4)
+
+	bootTime, err := getBootTime()
+	assert.Equal(t, err, nil)
+
+	tmux := procs[4242]
+	assert.Equal(t, tmux.ppid, 1)
+	assert.Equal(t, tmux.Cmdline, "tmux new-session -s my session")
+	assert.Equal(t, tmux.Username, uidToUsername(0))
+	assert.Equal(t, *tmux.startTicks, uint64(91737))
+	assert.Equal(t, tmux.startTime, bootTime.Add(917370*time.Millisecond))
+	assert.Equal(t, *tmux.CpuTime, 18010*time.Millisecond)
+	assert.Equal(t, *tmux.CpuTimeTotal, 18010*time.Millisecond)
+	assert.Equal(t, *tmux.ioBytes, uint64(40960+8192))
+	assert.Equal(t, tmux.State, "S")
+	assert.Equal(t, tmux.Wchan, "")
+	assert.Equal(t, *tmux.Nice, 5)
+	assert.Equal(t, *tmux.Threads, 3)
+	assert.Equal(t, tmux.Tty, "pts/1")
+	assert.Equal(t, tmux.Cgroup, "session-2.scope")
+	assert.Equal(t, *tmux.PssKb, 1530)
+	assert.Equal(t, *tmux.UssKb, 704+804)
+	assert.Equal(t, *tmux.SwapKb, 128)
+
+	zombie := procs[4243]
+	assert.Equal(t, zombie.Cmdline, "[bash] <defunct>")
+	assert.Equal(t, zombie.ioBytes == nil, true)
+	assert.Equal(t, zombie.State, "Z")
+	assert.Equal(t, zombie.PssKb == nil, true)
+
+	cp := procs[4244]
+	assert.Equal(t, cp.Cmdline, "cp /mnt/nfs/big.iso /tmp")
+	assert.Equal(t, cp.State, "D")
+	assert.Equal(t, cp.Wchan, "nfs_wait_bit_killable")
+	assert.Equal(t, cp.Cgroup, "docker:0123456789ab")
+
+	kthreadd := procs[2]
+	assert.Equal(t, kthreadd.Cmdline, "[kthreadd]")
+	assert.Equal(t, kthreadd.Command(), "[kthreadd]")
+	assert.Equal(t, kthreadd.RssKb, 0)
+}
+
+func TestSmapsRollupCache(t *testing.T) {
+	pidDir := "procfs_parser_test/proc/4242"
+	t0 := time.Date(2026, 2, 18, 10, 0, 0, 0, time.UTC)
+	cache := newSmapsRollupCache()
+
+	cache.startUpdate()
+	first, err := cache.get(pidDir, 10, 1234, t0)
+	cache.finishUpdate()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, first.pssKb, 1530)
+
+	// Recently read, should come from the cache
+	cache.startUpdate()
+	cached, err := cache.get(pidDir, 10, 1234, t0.Add(time.Second))
+	cache.finishUpdate()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, cached == first, true)
+
+	// Same PID but another process, should be re-read
+	cache.startUpdate()
+	reread, err := cache.get(pidDir, 10, 5678, t0.Add(2*time.Second))
+	cache.finishUpdate()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, reread == first, false)
+
+	// Too old, should be re-read
+	cache.startUpdate()
+	old, err := cache.get(pidDir, 10, 5678, t0.Add(2*time.Second+SMAPS_ROLLUP_MAX_AGE))
+	cache.finishUpdate()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, old == reread, false)
+}
+
+func TestCgroupCache(t *testing.T) {
+	t0 := time.Date(2026, 2, 18, 10, 0, 0, 0, time.UTC)
+	cache := newCgroupCache()
+
+	cache.startUpdate()
+	assert.Equal(t, cache.get("procfs_parser_test/proc/4242", 10, 1234, t0), "session-2.scope")
+	cache.finishUpdate()
+
+	// Recently read, should come from the cache even though the file says
+	// something else now
+	cache.startUpdate()
+	assert.Equal(t, cache.get("procfs_parser_test/proc/4244", 10, 1234, t0.Add(time.Second)), "session-2.scope")
+	cache.finishUpdate()
+
+	// Same PID but another process, should be re-read
+	cache.startUpdate()
+	assert.Equal(t, cache.get("procfs_parser_test/proc/4244", 10, 5678, t0.Add(2*time.Second)), "docker:0123456789ab")
+	cache.finishUpdate()
+
+	// Too old, should be re-read
+	cache.startUpdate()
+	assert.Equal(t, cache.get("procfs_parser_test/proc/4242", 10, 5678, t0.Add(2*time.Second+CGROUP_MAX_AGE)), "session-2.scope")
+	cache.finishUpdate()
+}
@@ -161,3 +161,63 @@ func TestProcessCommandLine_PreservesArgsOnInvalidCoalescingInput(t *testing.T)
 	commandLine := process.DisplayCommandLine()
 	assert.SlicesEqual(t, commandLine, []string{"/tmp/\x00", "broken"})
 }
+
+func TestProcessSameAs_ExactStartTicks(t *testing.T) {
+	base := time.Date(2026, time.March, 15, 8, 51, 33, 0, time.Local)
+	ticks := uint64(91737)
+	nextTick := ticks + 1
+
+	proc := &Process{Pid: 1234, startTime: base, startTicks: &ticks}
+	same := &Process{Pid: 1234, startTime: base.Add(time.Second), startTicks: &ticks}
+	reused := &Process{Pid: 1234, startTime: base, startTicks: &nextTick}
+
+	// With exact start times, only the ticks matter
+	assert.Equal(t, proc.SameAs(same), true)
+	assert.Equal(t, proc.SameAs(reused), false)
+}
+
+func TestFillInNativities_Subtree(t *testing.T) {
+	longAgo := time.Now().Add(-10 * NATIVITY_MAX_AGE)
+	justNow := time.Now()
+
+	init := &Process{Pid: 1, Cmdline: "init", startTime: longAgo}
+	brew := &Process{Pid: 2, Cmdline: "brew", startTime: longAgo, parent: init}
+	sh := &Process{Pid: 3, Cmdline: "sh", startTime: justNow, parent: brew}
+	curl1 := &Process{Pid: 4, Cmdline: "curl", startTime: justNow, parent: sh}
+	curl2 := &Process{Pid: 5, Cmdline: "curl", startTime: justNow, parent: sh}
+	init.children = []*Process{brew}
+	brew.children = []*Process{sh}
+	sh.children = []*Process{curl1, curl2}
+
+	fillInNativities(map[int]*Process{1: init, 2: brew, 3: sh, 4: curl1, 5: curl2})
+
+	assert.Equal(t, sh.Nativity, 2)
+	assert.Equal(t, sh.SubtreeNativity, 2)
+
+	// brew launched sh, and through it the curls, which count double
+	assert.Equal(t, brew.Nativity, 1)
+	assert.Equal(t, brew.SubtreeNativity, 1+2*2)
+
+	// Three generations down from init, both sh and the curls count
+	assert.Equal(t, init.Nativity, 0)
+	assert.Equal(t, init.SubtreeNativity, 1*2+2*3)
+}
+
+// brew runs its downloads through a long running sh. brew is the one doing the
+// work, so it must rank above the sh.
+func TestFillInNativities_OrchestratorAboveShell(t *testing.T) {
+	longAgo := time.Now().Add(-10 * NATIVITY_MAX_AGE)
+	justNow := time.Now()
+
+	brew := &Process{Pid: 2, Cmdline: "brew", startTime: longAgo}
+	sh := &Process{Pid: 3, Cmdline: "sh", startTime: longAgo, parent: brew}
+	curl := &Process{Pid: 4, Cmdline: "curl", startTime: justNow, parent: sh}
+	brew.children = []*Process{sh}
+	sh.children = []*Process{curl}
+
+	fillInNativities(map[int]*Process{2: brew, 3: sh, 4: curl})
+
+	assert.Equal(t, brew.Nativity, 0)
+	assert.Equal(t, sh.Nativity, 1)
+	assert.Equal(t, brew.SubtreeNativity > sh.SubtreeNativity, true)
+}
@@ -0,0 +1,20 @@
+package processes
+
+import "errors"
+
+// Returned by OpenHandle() if the PID now belongs to some other process than
+// the one we got from the tracker
+var ErrPidReused = errors.New("PID has been reused by another process")
+
+// A reference to one specific process. Unlike a PID, a handle can't end up
+// referring to some other process if ours dies and its PID gets reused.
+//
+// Only available on Linux, where it's a pidfd.
+type ProcessHandle struct {
+	process *Process
+	fd      int // nolint:unused
+}
+
+func (h *ProcessHandle) Process() *Process {
+	return h.process
+}
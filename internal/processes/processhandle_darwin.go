@@ -0,0 +1,24 @@
+package processes
+
+import (
+	"errors"
+	"syscall"
+	"time"
+)
+
+// There are no pidfds on macOS, callers should fall back to using PIDs
+func (p *Process) OpenHandle() (*ProcessHandle, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (h *ProcessHandle) Signal(signal syscall.Signal) error {
+	return errors.ErrUnsupported
+}
+
+func (h *ProcessHandle) Close() error {
+	return errors.ErrUnsupported
+}
+
+func WaitForAnyExit(handles []*ProcessHandle, timeout time.Duration) ([]*ProcessHandle, error) {
+	return nil, errors.ErrUnsupported
+}
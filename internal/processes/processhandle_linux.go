@@ -0,0 +1,83 @@
+package processes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// Open a pidfd for this process, after verifying that the PID still belongs to
+// the process the tracker saw.
+//
+// Returns ErrPidReused if the PID belongs to some other process now, and
+// an error wrapping ESRCH or os.ErrNotExist if the process is gone.
+func (p *Process) OpenHandle() (*ProcessHandle, error) {
+	if p.startTicks == nil {
+		return nil, fmt.Errorf("start time of %s unknown, can't verify it's still the same process", p.String())
+	}
+
+	fd, err := unix.PidfdOpen(p.Pid, 0)
+	if err != nil {
+		return nil, fmt.Errorf("pidfd_open %s: %w", p.String(), err)
+	}
+
+	// We open the pidfd first and verify afterwards. Verifying first would
+	// leave a window for the PID to be reused before we got our pidfd.
+	statBytes, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(p.Pid), "stat"))
+	if err != nil {
+		_ = unix.Close(fd)
+		return nil, err
+	}
+	stat, err := parseProcPidStat(string(statBytes))
+	if err != nil {
+		_ = unix.Close(fd)
+		return nil, err
+	}
+	if stat.startTicks != *p.startTicks {
+		_ = unix.Close(fd)
+		return nil, ErrPidReused
+	}
+
+	return &ProcessHandle{process: p, fd: fd}, nil
+}
+
+func (h *ProcessHandle) Signal(signal syscall.Signal) error {
+	return unix.PidfdSendSignal(h.fd, signal, nil, 0)
+}
+
+func (h *ProcessHandle) Close() error {
+	return unix.Close(h.fd)
+}
+
+// Wait until at least one of the handles' processes has exited, or until the
+// timeout expires. Returns the handles of all processes that have exited.
+func WaitForAnyExit(handles []*ProcessHandle, timeout time.Duration) ([]*ProcessHandle, error) {
+	pollFds := make([]unix.PollFd, 0, len(handles))
+	for _, handle := range handles {
+		// A pidfd becomes readable when its process exits
+		pollFds = append(pollFds, unix.PollFd{Fd: int32(handle.fd), Events: unix.POLLIN})
+	}
+
+	_, err := unix.Poll(pollFds, int(timeout.Milliseconds()))
+	if err == unix.EINTR {
+		// Interrupted, as if nothing happened
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	exited := []*ProcessHandle{}
+	for i, pollFd := range pollFds {
+		if pollFd.Revents != 0 {
+			exited = append(exited, handles[i])
+		}
+	}
+
+	return exited, nil
+}
@@ -0,0 +1,55 @@
+package processes
+
+import (
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/walles/ftop/internal/assert"
+)
+
+func TestProcessHandle(t *testing.T) {
+	cmd := exec.Command("sleep", "10")
+	assert.Equal(t, cmd.Start(), nil)
+	defer func() { _ = cmd.Wait() }()
+
+	procs, err := getAllFromProc("/proc", newSmapsRollupCache(), newCgroupCache())
+	assert.Equal(t, err, nil)
+	sleep := procs[cmd.Process.Pid]
+
+	handle, err := sleep.OpenHandle()
+	assert.Equal(t, err, nil)
+	defer func() { _ = handle.Close() }()
+
+	exited, err := WaitForAnyExit([]*ProcessHandle{handle}, 10*time.Millisecond)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(exited), 0)
+
+	assert.Equal(t, handle.Signal(syscall.SIGTERM), nil)
+
+	exited, err = WaitForAnyExit([]*ProcessHandle{handle}, 5*time.Second)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(exited), 1)
+}
+
+func TestProcessHandle_PidReused(t *testing.T) {
+	cmd := exec.Command("sleep", "10")
+	assert.Equal(t, cmd.Start(), nil)
+	defer func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+
+	procs, err := getAllFromProc("/proc", newSmapsRollupCache(), newCgroupCache())
+	assert.Equal(t, err, nil)
+	sleep := *procs[cmd.Process.Pid]
+
+	// Pretend the process we saw was started earlier than the one now
+	// holding its PID
+	startTicks := *sleep.startTicks - 1
+	sleep.startTicks = &startTicks
+
+	_, err = sleep.OpenHandle()
+	assert.Equal(t, err, ErrPidReused)
+}
@@ -0,0 +1,354 @@
+package processes
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kernel clock ticks per second, the unit of the time fields in
+// /proc/<pid>/stat. This is USER_HZ, which is 100 on all Linux architectures we
+// care about, and Go can't ask sysconf(_SC_CLK_TCK) for it without cgo.
+const userHz = 100
+
+// The fields we use from one /proc/<pid>/stat file. Field numbers in the
+// comments are from "man 5 proc".
+type procPidStat struct {
+	comm        string // (2) Executable name, without the parentheses
+	state       byte   // (3) One of "RSDZTtWXxKWP"
+	ppid        int    // (4)
+	ttyNr       int    // (7) Controlling terminal, see ttyName()
+	utimeTicks  uint64 // (14) User mode CPU time
+	stimeTicks  uint64 // (15) Kernel mode CPU time
+	cutimeTicks uint64 // (16) User mode CPU time of waited-for children
+	cstimeTicks uint64 // (17) Kernel mode CPU time of waited-for children
+	nice        int    // (19) -20 to 19, higher is nicer
+	numThreads  int    // (20)
+	startTicks  uint64 // (22) Start time, in clock ticks since boot
+	rssPages    int    // (24) Resident set size, in pages
+}
+
+// Parse the contents of a /proc/<pid>/stat file.
+//
+// Example input:
+//
+//	20687 (cat) R 20681 20687 20681 0 -1 4194304 81 0 0 0 0 0 0 0 20 0 1 0 91737 2703360 287 ...
+func parseProcPidStat(stat string) (procPidStat, error) { // nolint:unused
+	// The command name can contain anything, including spaces and parentheses,
+	// so we look for the last closing parenthesis rather than splitting on
+	// spaces.
+	commStart := strings.IndexByte(stat, '(')
+	commEnd := strings.LastIndexByte(stat, ')')
+	if commStart < 0 || commEnd < commStart {
+		return procPidStat{}, fmt.Errorf("no command name found in stat line <%s>", stat)
+	}
+
+	// Fields after the command name, starting with field 3 (state)
+	fields := strings.Fields(stat[commEnd+1:])
+	field := func(number int) string {
+		return fields[number-3]
+	}
+	if len(fields) < 24-2 {
+		return procPidStat{}, fmt.Errorf("expected at least 24 fields in stat line, got %d: <%s>", len(fields)+2, stat)
+	}
+
+	if len(field(3)) != 1 {
+		return procPidStat{}, fmt.Errorf("failed to parse state <%s> from stat line <%s>", field(3), stat)
+	}
+
+	ppid, err := strconv.Atoi(field(4))
+	if err != nil {
+		return procPidStat{}, fmt.Errorf("failed to parse ppid <%s> from stat line <%s>: %v", field(4), stat, err)
+	}
+
+	ttyNr, err := strconv.Atoi(field(7))
+	if err != nil {
+		return procPidStat{}, fmt.Errorf("failed to parse tty_nr <%s> from stat line <%s>: %v", field(7), stat, err)
+	}
+
+	utime, err := strconv.ParseUint(field(14), 10, 64)
+	if err != nil {
+		return procPidStat{}, fmt.Errorf("failed to parse utime <%s> from stat line <%s>: %v", field(14), stat, err)
+	}
+
+	stime, err := strconv.ParseUint(field(15), 10, 64)
+	if err != nil {
+		return procPidStat{}, fmt.Errorf("failed to parse stime <%s> from stat line <%s>: %v", field(15), stat, err)
+	}
+
+	cutime, err := strconv.ParseUint(field(16), 10, 64)
+	if err != nil {
+		return procPidStat{}, fmt.Errorf("failed to parse cutime <%s> from stat line <%s>: %v", field(16), stat, err)
+	}
+
+	cstime, err := strconv.ParseUint(field(17), 10, 64)
+	if err != nil {
+		return procPidStat{}, fmt.Errorf("failed to parse cstime <%s> from stat line <%s>: %v", field(17), stat, err)
+	}
+
+	nice, err := strconv.Atoi(field(19))
+	if err != nil {
+		return procPidStat{}, fmt.Errorf("failed to parse nice <%s> from stat line <%s>: %v", field(19), stat, err)
+	}
+
+	numThreads, err := strconv.Atoi(field(20))
+	if err != nil {
+		return procPidStat{}, fmt.Errorf("failed to parse num_threads <%s> from stat line <%s>: %v", field(20), stat, err)
+	}
+
+	startTicks, err := strconv.ParseUint(field(22), 10, 64)
+	if err != nil {
+		return procPidStat{}, fmt.Errorf("failed to parse starttime <%s> from stat line <%s>: %v", field(22), stat, err)
+	}
+
+	rssPages, err := strconv.Atoi(field(24))
+	if err != nil {
+		return procPidStat{}, fmt.Errorf("failed to parse rss <%s> from stat line <%s>: %v", field(24), stat, err)
+	}
+
+	return procPidStat{
+		comm:        stat[commStart+1 : commEnd],
+		state:       field(3)[0],
+		ppid:        ppid,
+		ttyNr:       ttyNr,
+		utimeTicks:  utime,
+		stimeTicks:  stime,
+		cutimeTicks: cutime,
+		cstimeTicks: cstime,
+		nice:        nice,
+		numThreads:  numThreads,
+		startTicks:  startTicks,
+		rssPages:    rssPages,
+	}, nil
+}
+
+// Turn a tty_nr from /proc/<pid>/stat into a name like "pts/3", the way ps
+// shows it. "?" means no controlling terminal.
+func ttyName(ttyNr int) string { // nolint:unused
+	if ttyNr == 0 {
+		return "?"
+	}
+
+	// See MAJOR() and MINOR() in linux/kdev_t.h
+	major := (ttyNr >> 8) & 0xfff
+	minor := (ttyNr & 0xff) | ((ttyNr >> 12) & 0xfff00)
+
+	// Device numbers from https://docs.kernel.org/admin-guide/devices.html
+	switch {
+	case major >= 136 && major <= 143:
+		return fmt.Sprintf("pts/%d", (major-136)*256+minor)
+	case major == 4 && minor < 64:
+		return fmt.Sprintf("tty%d", minor)
+	case major == 4:
+		return fmt.Sprintf("ttyS%d", minor-64)
+	}
+
+	return fmt.Sprintf("%d:%d", major, minor)
+}
+
+// Extract the effective UID from the contents of a /proc/<pid>/status file.
+// Effective rather than real, since that's what "ps -o uid=" shows.
+func parseProcPidStatusUid(status string) (int, error) { // nolint:unused
+	scanner := bufio.NewScanner(strings.NewReader(status))
+	for scanner.Scan() {
+		// Example: "Uid:	1000	1000	1000	1000"
+		uids, found := strings.CutPrefix(scanner.Text(), "Uid:")
+		if !found {
+			continue
+		}
+
+		// Real, effective, saved set and filesystem UIDs
+		fields := strings.Fields(uids)
+		if len(fields) < 2 {
+			return 0, fmt.Errorf("expected at least two UIDs, got <%s>", uids)
+		}
+
+		uid, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse effective UID <%s>: %v", fields[1], err)
+		}
+
+		return uid, nil
+	}
+
+	return 0, fmt.Errorf("no Uid line found")
+}
+
+// Extract read_bytes and write_bytes from the contents of a /proc/<pid>/io file.
+// These are the bytes that actually hit the storage layer, as opposed to rchar
+// and wchar which also count reads from the page cache and pipes.
+func parseProcPidIo(io string) (readBytes uint64, writeBytes uint64, err error) { // nolint:unused
+	foundRead := false
+	foundWrite := false
+
+	scanner := bufio.NewScanner(strings.NewReader(io))
+	for scanner.Scan() {
+		name, value, found := strings.Cut(scanner.Text(), ":")
+		if !found {
+			continue
+		}
+
+		var target *uint64
+		switch name {
+		case "read_bytes":
+			target = &readBytes
+			foundRead = true
+		case "write_bytes":
+			target = &writeBytes
+			foundWrite = true
+		default:
+			continue
+		}
+
+		*target, err = strconv.ParseUint(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to parse %s <%s>: %v", name, value, err)
+		}
+	}
+
+	if !foundRead || !foundWrite {
+		return 0, 0, fmt.Errorf("read_bytes or write_bytes missing")
+	}
+
+	return readBytes, writeBytes, nil
+}
+
+type procPidSmapsRollup struct {
+	pssKb  int
+	ussKb  int // Private_Clean + Private_Dirty
+	swapKb int
+}
+
+// Extract memory accounting numbers from the contents of a
+// /proc/<pid>/smaps_rollup file.
+//
+// PSS (proportional set size) splits shared pages evenly between the processes
+// sharing them, so unlike RSS it can be summed over processes. USS (unique set
+// size) is what we would get back by killing the process.
+func parseProcPidSmapsRollup(smapsRollup string) (procPidSmapsRollup, error) { // nolint:unused
+	var result procPidSmapsRollup
+	foundPss := false
+	foundPrivateClean := false
+	foundPrivateDirty := false
+	foundSwap := false
+
+	scanner := bufio.NewScanner(strings.NewReader(smapsRollup))
+	for scanner.Scan() {
+		// Example: "Pss:                1234 kB"
+		name, value, found := strings.Cut(scanner.Text(), ":")
+		if !found {
+			continue
+		}
+
+		switch name {
+		case "Pss", "Private_Clean", "Private_Dirty", "Swap":
+		default:
+			continue
+		}
+
+		kb, err := strconv.Atoi(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(value), "kB")))
+		if err != nil {
+			return procPidSmapsRollup{}, fmt.Errorf("failed to parse %s <%s>: %v", name, value, err)
+		}
+
+		switch name {
+		case "Pss":
+			result.pssKb = kb
+			foundPss = true
+		case "Private_Clean":
+			result.ussKb += kb
+			foundPrivateClean = true
+		case "Private_Dirty":
+			result.ussKb += kb
+			foundPrivateDirty = true
+		case "Swap":
+			result.swapKb = kb
+			foundSwap = true
+		}
+	}
+
+	if !foundPss || !foundPrivateClean || !foundPrivateDirty || !foundSwap {
+		return procPidSmapsRollup{}, fmt.Errorf("Pss, Private_Clean, Private_Dirty or Swap missing")
+	}
+
+	return result, nil
+}
+
+// Convert the contents of a /proc/<pid>/wchan file into a kernel function name.
+// The kernel says "0" when the process isn't waiting, and also when we aren't
+// allowed to know.
+func parseProcPidWchan(wchan string) string { // nolint:unused
+	wchan = strings.TrimSpace(wchan)
+	if wchan == "0" {
+		return ""
+	}
+
+	return wchan
+}
+
+// Convert the contents of a /proc/<pid>/cmdline file into a command line
+// string of the same kind that "ps -o command=" would give us.
+//
+// Kernel threads and zombies have empty cmdline files, those get their comm
+// value in brackets, just like ps does it.
+func procCmdlineToString(cmdline string, stat procPidStat) string { // nolint:unused
+	cmdline = strings.TrimRight(cmdline, "\x00")
+	if cmdline != "" {
+		return strings.ReplaceAll(cmdline, "\x00", " ")
+	}
+
+	if stat.state == 'Z' {
+		return "[" + stat.comm + "] <defunct>"
+	}
+
+	return "[" + stat.comm + "]"
+}
+
+// Extract the boot time from the contents of /proc/stat
+func parseProcStatBootTime(procStat string) (time.Time, error) { // nolint:unused
+	scanner := bufio.NewScanner(strings.NewReader(procStat))
+	for scanner.Scan() {
+		// Example: "btime 1792217116"
+		btime, found := strings.CutPrefix(scanner.Text(), "btime ")
+		if !found {
+			continue
+		}
+
+		seconds, err := strconv.ParseInt(strings.TrimSpace(btime), 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to parse btime <%s>: %v", btime, err)
+		}
+
+		return time.Unix(seconds, 0), nil
+	}
+
+	return time.Time{}, fmt.Errorf("no btime line found")
+}
+
+// Extract MemTotal from the contents of /proc/meminfo
+func parseProcMemInfoTotalKb(memInfo string) (int, error) { // nolint:unused
+	scanner := bufio.NewScanner(strings.NewReader(memInfo))
+	for scanner.Scan() {
+		// Example: "MemTotal:        6579644 kB"
+		total, found := strings.CutPrefix(scanner.Text(), "MemTotal:")
+		if !found {
+			continue
+		}
+
+		kb, err := strconv.Atoi(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(total), "kB")))
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse MemTotal <%s>: %v", total, err)
+		}
+
+		return kb, nil
+	}
+
+	return 0, fmt.Errorf("no MemTotal line found")
+}
+
+// Convert clock ticks to a duration
+func ticksToDuration(ticks uint64) time.Duration { // nolint:unused
+	return time.Duration(ticks) * (time.Second / userHz)
+}
@@ -0,0 +1,123 @@
+package processes
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/walles/ftop/internal/assert"
+)
+
+func TestParseProcPidStat(t *testing.T) {
+	exampleBytes, err := os.ReadFile("procfs_parser_test/proc/4242/stat")
+	assert.Equal(t, err, nil)
+
+	stat, err := parseProcPidStat(string(exampleBytes))
+	assert.Equal(t, err, nil)
+
+	// Note the parentheses and spaces in the command name
+	assert.Equal(t, stat.comm, "tmux: server (1)")
+	assert.Equal(t, stat.state, byte('S'))
+	assert.Equal(t, stat.ppid, 1)
+	assert.Equal(t, stat.utimeTicks, uint64(1234))
+	assert.Equal(t, stat.stimeTicks, uint64(567))
+	assert.Equal(t, stat.cutimeTicks, uint64(89))
+	assert.Equal(t, stat.cstimeTicks, uint64(11))
+	assert.Equal(t, stat.ttyNr, 34817)
+	assert.Equal(t, stat.nice, 5)
+	assert.Equal(t, stat.numThreads, 3)
+	assert.Equal(t, stat.startTicks, uint64(91737))
+	assert.Equal(t, stat.rssPages, 1500)
+}
+
+func TestParseProcPidStat_Truncated(t *testing.T) {
+	_, err := parseProcPidStat("4242 (bash) S 1 4242")
+	assert.Equal(t, err != nil, true)
+}
+
+func TestParseProcPidStatusUid(t *testing.T) {
+	exampleBytes, err := os.ReadFile("procfs_parser_test/proc/4242/status")
+	assert.Equal(t, err, nil)
+
+	uid, err := parseProcPidStatusUid(string(exampleBytes))
+	assert.Equal(t, err, nil)
+
+	// Effective UID, not the real one
+	assert.Equal(t, uid, 0)
+}
+
+func TestProcCmdlineToString(t *testing.T) {
+	exampleBytes, err := os.ReadFile("procfs_parser_test/proc/4242/cmdline")
+	assert.Equal(t, err, nil)
+
+	assert.Equal(t,
+		procCmdlineToString(string(exampleBytes), procPidStat{comm: "tmux: server (1)", state: 'S'}),
+		"tmux new-session -s my session")
+
+	assert.Equal(t, procCmdlineToString("", procPidStat{comm: "kthreadd", state: 'S'}), "[kthreadd]")
+	assert.Equal(t, procCmdlineToString("", procPidStat{comm: "bash", state: 'Z'}), "[bash] <defunct>")
+}
+
+func TestParseProcPidWchan(t *testing.T) {
+	assert.Equal(t, parseProcPidWchan("nfs_wait_bit_killable"), "nfs_wait_bit_killable")
+	assert.Equal(t, parseProcPidWchan("0"), "")
+	assert.Equal(t, parseProcPidWchan(""), "")
+}
+
+func TestParseProcStatBootTime(t *testing.T) {
+	exampleBytes, err := os.ReadFile("procfs_parser_test/proc/stat")
+	assert.Equal(t, err, nil)
+
+	bootTime, err := parseProcStatBootTime(string(exampleBytes))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, bootTime, time.Unix(1792217116, 0))
+}
+
+func TestParseProcMemInfoTotalKb(t *testing.T) {
+	exampleBytes, err := os.ReadFile("procfs_parser_test/proc/meminfo")
+	assert.Equal(t, err, nil)
+
+	totalKb, err := parseProcMemInfoTotalKb(string(exampleBytes))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, totalKb, 6579644)
+}
+
+func TestTicksToDuration(t *testing.T) {
+	assert.Equal(t, ticksToDuration(0), time.Duration(0))
+	assert.Equal(t, ticksToDuration(150), 1500*time.Millisecond)
+}
+
+func TestParseProcPidIo(t *testing.T) {
+	exampleBytes, err := os.ReadFile("procfs_parser_test/proc/4242/io")
+	assert.Equal(t, err, nil)
+
+	readBytes, writeBytes, err := parseProcPidIo(string(exampleBytes))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, readBytes, uint64(40960))
+	assert.Equal(t, writeBytes, uint64(8192))
+
+	_, _, err = parseProcPidIo("rchar: 3980\n")
+	assert.Equal(t, err != nil, true)
+}
+
+func TestParseProcPidSmapsRollup(t *testing.T) {
+	exampleBytes, err := os.ReadFile("procfs_parser_test/proc/4242/smaps_rollup")
+	assert.Equal(t, err, nil)
+
+	smapsRollup, err := parseProcPidSmapsRollup(string(exampleBytes))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, smapsRollup.pssKb, 1530)
+	assert.Equal(t, smapsRollup.ussKb, 704+804)
+	assert.Equal(t, smapsRollup.swapKb, 128)
+
+	_, err = parseProcPidSmapsRollup("Rss: 5232 kB\nPss: 1530 kB\n")
+	assert.Equal(t, err != nil, true)
+}
+
+func TestTtyName(t *testing.T) {
+	assert.Equal(t, ttyName(0), "?")
+	assert.Equal(t, ttyName(136<<8|1), "pts/1")
+	assert.Equal(t, ttyName(137<<8|2), "pts/258")
+	assert.Equal(t, ttyName(4<<8|2), "tty2")
+	assert.Equal(t, ttyName(4<<8|65), "ttyS1")
+}
@@ -109,8 +109,8 @@ func parseElapsedDuration(s string) (time.Duration, error) {
 	return 0, fmt.Errorf("failed to parse elapsed duration string <%s>", s)
 }
 
-func processFieldsToProcess(fields [9]string, line string, snapshotTime time.Time) (*Process, error) {
-	if fields[8] == "" {
+func processFieldsToProcess(fields [10]string, line string, snapshotTime time.Time) (*Process, error) {
+	if fields[9] == "" {
 		return nil, fmt.Errorf("failed to match ps line <%q>", line)
 	}
 
@@ -165,7 +165,9 @@ func processFieldsToProcess(fields [9]string, line string, snapshotTime time.Tim
 		return nil, fmt.Errorf("failed to parse memory_percent <%s> from line <%s>: %v", fields[7], line, err)
 	}
 
-	cmdline := fields[8]
+	state := psStateToState(fields[8])
+
+	cmdline := fields[9]
 
 	return &Process{
 		Pid:           pid,
@@ -177,12 +179,29 @@ func processFieldsToProcess(fields [9]string, line string, snapshotTime time.Tim
 		CpuTime:       &cpu_time,
 		CpuTimeTotal:  &cpu_time,
 		memoryPercent: &memory_percent,
+		State:         state,
 		Cmdline:       cmdline,
 	}, nil
 }
 
+// Convert a ps state string like "Ss+" into a one letter state like the ones
+// in /proc/<pid>/stat.
+func psStateToState(psState string) string {
+	if psState == "" {
+		return ""
+	}
+
+	state := psState[:1]
+	if state == "U" {
+		// macOS ps says U for uninterruptible wait, Linux says D
+		return "D"
+	}
+
+	return state
+}
+
 func psLineToProcess(line string, snapshotTime time.Time) (*Process, error) {
-	var fields [9]string
+	var fields [10]string
 	fieldIdx := 0
 	start := 0
 	inWord := false
@@ -198,15 +217,15 @@ func psLineToProcess(line string, snapshotTime time.Time) (*Process, error) {
 				fields[fieldIdx] = line[start:i]
 				fieldIdx++
 				inWord = false
-				if fieldIdx == 8 {
-					// The 9th field is the command string, which may contain spaces.
-					// Find the start of the 9th field and grab the rest of the string.
+				if fieldIdx == 9 {
+					// The 10th field is the command string, which may contain spaces.
+					// Find the start of the 10th field and grab the rest of the string.
 					remaining := line[i:]
 					trimStart := 0
 					for trimStart < len(remaining) && remaining[trimStart] == ' ' {
 						trimStart++
 					}
-					fields[8] = remaining[trimStart:]
+					fields[9] = remaining[trimStart:]
 					break
 				}
 			}
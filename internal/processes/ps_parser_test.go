@@ -14,7 +14,7 @@ func BenchmarkPsLineRetention(b *testing.B) {
 	snapshotTime := time.Now()
 
 	var builder strings.Builder
-	lineStr := " 974 973 588 00:00 501 0.0 0:00.00 0.0 We intentionally use a very long command line here to ensure the simulated bufio.Scanner allocates large backing strings allowing the benchmark to accurately measure the heap retention caused by keeping substrings of these long lines\n"
+	lineStr := " 974 973 588 00:00 501 0.0 0:00.00 0.0 S We intentionally use a very long command line here to ensure the simulated bufio.Scanner allocates large backing strings allowing the benchmark to accurately measure the heap retention caused by keeping substrings of these long lines\n"
 	const numLines = 1000
 	for range numLines {
 		builder.WriteString(lineStr)
@@ -43,7 +43,7 @@ func BenchmarkPsLineRetention(b *testing.B) {
 }
 
 func TestPsLineToProcess_HappyPathMacOS(t *testing.T) {
-	line := " 974 973 588 00:00 501 0.0 0:00.00 0.0 /bin/sleep"
+	line := " 974 973 588 00:00 501 0.0 0:00.00 0.0 Ss /bin/sleep"
 	snapshotTime := time.Date(2026, time.March, 15, 9, 55, 27, 0, time.Local)
 
 	proc, err := psLineToProcess(line, snapshotTime)
@@ -56,6 +56,7 @@ func TestPsLineToProcess_HappyPathMacOS(t *testing.T) {
 	assert.Equal(t, proc.Username, uidToUsername(501))
 	assert.Equal(t, proc.Command(), "sleep")
 	assert.Equal(t, proc.Cmdline, "/bin/sleep")
+	assert.Equal(t, proc.State, "S")
 
 	assert.Equal(t, true, proc.cpuPercent != nil)
 	assert.Equal(t, *proc.cpuPercent, 0.0)
@@ -68,7 +69,7 @@ func TestPsLineToProcess_HappyPathMacOS(t *testing.T) {
 }
 
 func TestPsLineToProcess_HappyPathLinux(t *testing.T) {
-	line := "    1     0  3196       00:21     0  0.1 00:00:00  0.0 bash"
+	line := "    1     0  3196       00:21     0  0.1 00:00:00  0.0 Ss   bash"
 	snapshotTime := time.Date(2026, time.March, 15, 8, 51, 54, 0, time.Local)
 
 	proc, err := psLineToProcess(line, snapshotTime)
@@ -81,6 +82,7 @@ func TestPsLineToProcess_HappyPathLinux(t *testing.T) {
 	assert.Equal(t, proc.Username, uidToUsername(0))
 	assert.Equal(t, proc.Command(), "bash")
 	assert.Equal(t, proc.Cmdline, "bash")
+	assert.Equal(t, proc.State, "S")
 
 	assert.Equal(t, true, proc.cpuPercent != nil)
 	assert.Equal(t, *proc.cpuPercent, 0.1)
@@ -92,9 +94,21 @@ func TestPsLineToProcess_HappyPathLinux(t *testing.T) {
 	assert.Equal(t, *proc.memoryPercent, 0.0)
 }
 
+func TestPsLineToProcess_UninterruptibleMacOS(t *testing.T) {
+	// macOS ps says "U" where Linux says "D"
+	line := "  812     1  4096  1-02:03:04   501   0.0   0:00.12  0.0 U    /bin/cp /Volumes/nfs/big.iso /tmp"
+	snapshotTime := time.Date(2026, time.March, 15, 9, 55, 27, 0, time.Local)
+
+	proc, err := psLineToProcess(line, snapshotTime)
+	assert.Equal(t, err, nil)
+
+	assert.Equal(t, proc.State, "D")
+	assert.Equal(t, proc.Cmdline, "/bin/cp /Volumes/nfs/big.iso /tmp")
+}
+
 func TestPsLineToProcess_StableAcrossEtimeRefreshes(t *testing.T) {
-	lineA := "    1     0  3196       00:21     0  0.1 00:00:00  0.0 bash"
-	lineB := "    1     0  3196       00:22     0  0.1 00:00:00  0.0 bash"
+	lineA := "    1     0  3196       00:21     0  0.1 00:00:00  0.0 S    bash"
+	lineB := "    1     0  3196       00:22     0  0.1 00:00:00  0.0 S    bash"
 
 	snapshotA := time.Date(2026, time.March, 15, 8, 51, 54, 900000000, time.Local)
 	snapshotB := time.Date(2026, time.March, 15, 8, 51, 55, 100000000, time.Local)
@@ -111,8 +125,8 @@ func TestPsLineToProcess_StableAcrossEtimeRefreshes(t *testing.T) {
 }
 
 func TestPsLineToProcess_IgnoresMonotonicClockInSameAs(t *testing.T) {
-	lineA := "    1     0  3196       00:21     0  0.1 00:00:00  0.0 bash"
-	lineB := "    1     0  3196       00:21     0  0.1 00:00:00  0.0 bash"
+	lineA := "    1     0  3196       00:21     0  0.1 00:00:00  0.0 S    bash"
+	lineB := "    1     0  3196       00:21     0  0.1 00:00:00  0.0 S    bash"
 
 	baseSnapshot := time.Now()
 	snapshotA := baseSnapshot
@@ -137,7 +151,7 @@ func TestPsLineToProcess_IgnoresMonotonicClockInSameAs(t *testing.T) {
 
 // Real world example from macOS ps
 func TestPsLineToProcess_MalformedElapsedTime(t *testing.T) {
-	line := "24381 48334   1024       00:-1   501   0.0   0:00.00  0.0 netstat -ib"
+	line := "24381 48334   1024       00:-1   501   0.0   0:00.00  0.0 R    netstat -ib"
 	snapshotTime := time.Date(2026, time.March, 15, 11, 22, 0, 0, time.Local)
 
 	proc, err := psLineToProcess(line, snapshotTime)
@@ -160,7 +174,7 @@ func TestPsLineToProcess_MalformedElapsedTime(t *testing.T) {
 //	macOS 15.7.4 (24G517)
 //	"PROGRAM:ps  PROJECT:adv_cmds-235" from "what /bin/ps"
 func TestPsLineToProcess_NegativeMinutesElapsedTime(t *testing.T) {
-	line := "54898 1 7384 -14:-1 0 0.0 0:00.08 0.0 /usr/libexec/mdmclient daemon"
+	line := "54898 1 7384 -14:-1 0 0.0 0:00.08 0.0 Ss /usr/libexec/mdmclient daemon"
 	snapshotTime := time.Date(2026, time.March, 15, 11, 22, 0, 0, time.Local)
 
 	proc, err := psLineToProcess(line, snapshotTime)
@@ -0,0 +1,243 @@
+package processes
+
+import "time"
+
+// Everything we know about one process at one point in time, in a form that
+// can be written to disk and read back. See Record() and RestoreProcesses().
+//
+// Unknown values are nil, just like in Process. Nil pointers and pointers to
+// zero mean different things, so don't serialize this with encoding/gob.
+type ProcessRecord struct {
+	Pid                  int            `json:"pid"`
+	Ppid                 int            `json:"ppid"`
+	Cmdline              string         `json:"cmdline"`
+	DeduplicationSuffix  string         `json:"deduplicationSuffix,omitempty"`
+	StartTime            time.Time      `json:"startTime"`
+	StartTicks           *uint64        `json:"startTicks,omitempty"`
+	Username             string         `json:"username"`
+	Cgroup               string         `json:"cgroup,omitempty"`
+	RssKb                int            `json:"rssKb"`
+	MemoryPercent        *float64       `json:"memoryPercent,omitempty"`
+	PssKb                *int           `json:"pssKb,omitempty"`
+	UssKb                *int           `json:"ussKb,omitempty"`
+	SwapKb               *int           `json:"swapKb,omitempty"`
+	CpuPercent           *float64       `json:"cpuPercent,omitempty"`
+	CpuTime              *time.Duration `json:"cpuTime,omitempty"`
+	CpuTimeTotal         *time.Duration `json:"cpuTimeTotal,omitempty"`
+	ChildrenCpuTime      *time.Duration `json:"childrenCpuTime,omitempty"`
+	ChildrenCpuTimeTotal *time.Duration `json:"childrenCpuTimeTotal,omitempty"`
+	IoBytes              *uint64        `json:"ioBytes,omitempty"`
+	IoBytesPerSecond     *float64       `json:"ioBytesPerSecond,omitempty"`
+	State                string         `json:"state,omitempty"`
+	Wchan                string         `json:"wchan,omitempty"`
+	Nice                 *int           `json:"nice,omitempty"`
+	Threads              *int           `json:"threads,omitempty"`
+	Tty                  string         `json:"tty,omitempty"`
+	StuckUpdates         int            `json:"stuckUpdates,omitempty"`
+	StuckSince           time.Time      `json:"stuckSince,omitzero"`
+	Nativity             int            `json:"nativity,omitempty"`
+	SubtreeNativity      int            `json:"subtreeNativity,omitempty"`
+}
+
+func (p *Process) Record() ProcessRecord {
+	return ProcessRecord{
+		Pid:                  p.Pid,
+		Ppid:                 p.ppid,
+		Cmdline:              p.Cmdline,
+		DeduplicationSuffix:  p.DeduplicationSuffix,
+		StartTime:            p.startTime,
+		StartTicks:           p.startTicks,
+		Username:             p.Username,
+		Cgroup:               p.Cgroup,
+		RssKb:                p.RssKb,
+		MemoryPercent:        p.memoryPercent,
+		PssKb:                p.PssKb,
+		UssKb:                p.UssKb,
+		SwapKb:               p.SwapKb,
+		CpuPercent:           p.cpuPercent,
+		CpuTime:              p.CpuTime,
+		CpuTimeTotal:         p.CpuTimeTotal,
+		ChildrenCpuTime:      p.ChildrenCpuTime,
+		ChildrenCpuTimeTotal: p.ChildrenCpuTimeTotal,
+		IoBytes:              p.ioBytes,
+		IoBytesPerSecond:     p.IoBytesPerSecond,
+		State:                p.State,
+		Wchan:                p.Wchan,
+		Nice:                 p.Nice,
+		Threads:              p.Threads,
+		Tty:                  p.Tty,
+		StuckUpdates:         p.stuckUpdates,
+		StuckSince:           p.stuckSince,
+		Nativity:             p.Nativity,
+		SubtreeNativity:      p.SubtreeNativity,
+	}
+}
+
+// True if all fields are equal, comparing what pointers point to rather than the
+// pointers themselves
+func (r ProcessRecord) Equal(other ProcessRecord) bool {
+	return r.Pid == other.Pid &&
+		r.Ppid == other.Ppid &&
+		r.Cmdline == other.Cmdline &&
+		r.DeduplicationSuffix == other.DeduplicationSuffix &&
+		r.StartTime.Equal(other.StartTime) &&
+		equalPointees(r.StartTicks, other.StartTicks) &&
+		r.Username == other.Username &&
+		r.Cgroup == other.Cgroup &&
+		r.RssKb == other.RssKb &&
+		equalPointees(r.MemoryPercent, other.MemoryPercent) &&
+		equalPointees(r.PssKb, other.PssKb) &&
+		equalPointees(r.UssKb, other.UssKb) &&
+		equalPointees(r.SwapKb, other.SwapKb) &&
+		equalPointees(r.CpuPercent, other.CpuPercent) &&
+		equalPointees(r.CpuTime, other.CpuTime) &&
+		equalPointees(r.CpuTimeTotal, other.CpuTimeTotal) &&
+		equalPointees(r.ChildrenCpuTime, other.ChildrenCpuTime) &&
+		equalPointees(r.ChildrenCpuTimeTotal, other.ChildrenCpuTimeTotal) &&
+		equalPointees(r.IoBytes, other.IoBytes) &&
+		equalPointees(r.IoBytesPerSecond, other.IoBytesPerSecond) &&
+		r.State == other.State &&
+		r.Wchan == other.Wchan &&
+		equalPointees(r.Nice, other.Nice) &&
+		equalPointees(r.Threads, other.Threads) &&
+		r.Tty == other.Tty &&
+		r.StuckUpdates == other.StuckUpdates &&
+		r.StuckSince.Equal(other.StuckSince) &&
+		r.Nativity == other.Nativity &&
+		r.SubtreeNativity == other.SubtreeNativity
+}
+
+func equalPointees[T comparable](a *T, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// Like SameAs(), but for comparing with a process we only have a record of
+func (p *Process) SameAsRecord(record ProcessRecord) bool {
+	return p.SameAs(&Process{Pid: record.Pid, startTime: record.StartTime, startTicks: record.StartTicks})
+}
+
+// Process histories, built from a series of snapshots. See
+// RestoreProcesses().
+type RecordHistories struct {
+	byPid map[int]recordHistory
+
+	adds int // Number of Add() calls
+}
+
+type recordHistory struct {
+	process *Process // Just enough of it for SameAs() to work
+	history *History
+
+	lastAdd int // When this process was last in an Add() call
+}
+
+func NewRecordHistories() *RecordHistories {
+	return &RecordHistories{byPid: make(map[int]recordHistory)}
+}
+
+// Add one sample per process to its history. Processes we have seen before
+// (according to SameAs()) keep their history from last time, new processes get
+// a new one. Processes not in records are forgotten after HISTORY_LENGTH more
+// calls, until then DropFrom() can bring them back.
+//
+// Add snapshots in the order they were taken, or the histories will be out of
+// order.
+func (h *RecordHistories) Add(records []ProcessRecord, timestamp time.Time) {
+	h.adds++
+
+	byPid := make(map[int]recordHistory, len(records))
+	for pid, entry := range h.byPid {
+		if h.adds-entry.lastAdd <= HISTORY_LENGTH {
+			byPid[pid] = entry
+		}
+	}
+
+	for _, record := range records {
+		entry, found := h.byPid[record.Pid]
+		if !found || !entry.process.SameAsRecord(record) {
+			process := &Process{Pid: record.Pid, startTime: record.StartTime, startTicks: record.StartTicks}
+			entry = recordHistory{process: process, history: &History{}}
+		}
+
+		cpuTime := time.Duration(0)
+		if record.CpuTimeTotal != nil {
+			cpuTime = *record.CpuTimeTotal
+		}
+		entry.history.add(HistorySample{
+			Timestamp: timestamp,
+			CpuTime:   cpuTime,
+			RssKb:     record.RssKb,
+		})
+
+		entry.lastAdd = h.adds
+		byPid[record.Pid] = entry
+	}
+
+	h.byPid = byPid
+}
+
+// Forget all samples taken at or after timestamp. For going back in time
+// without rebuilding the histories from scratch.
+func (h *RecordHistories) DropFrom(timestamp time.Time) {
+	for pid, entry := range h.byPid {
+		entry.history.dropFrom(timestamp)
+		if entry.history.isEmpty() {
+			delete(h.byPid, pid)
+		}
+	}
+}
+
+// Turn records back into processes, with parents and children linked up just
+// like Tracker.Processes() does it.
+//
+// The records are added to histories first, and the processes get their
+// histories from there.
+func RestoreProcesses(records []ProcessRecord, histories *RecordHistories, timestamp time.Time) []Process {
+	histories.Add(records, timestamp)
+
+	procsMap := make(map[int]*Process, len(records))
+	for _, record := range records {
+		procsMap[record.Pid] = &Process{
+			Pid:                  record.Pid,
+			ppid:                 record.Ppid,
+			Cmdline:              record.Cmdline,
+			DeduplicationSuffix:  record.DeduplicationSuffix,
+			startTime:            record.StartTime,
+			startTicks:           record.StartTicks,
+			Username:             record.Username,
+			Cgroup:               record.Cgroup,
+			RssKb:                record.RssKb,
+			memoryPercent:        record.MemoryPercent,
+			PssKb:                record.PssKb,
+			UssKb:                record.UssKb,
+			SwapKb:               record.SwapKb,
+			cpuPercent:           record.CpuPercent,
+			CpuTime:              record.CpuTime,
+			CpuTimeTotal:         record.CpuTimeTotal,
+			ChildrenCpuTime:      record.ChildrenCpuTime,
+			ChildrenCpuTimeTotal: record.ChildrenCpuTimeTotal,
+			ioBytes:              record.IoBytes,
+			IoBytesPerSecond:     record.IoBytesPerSecond,
+			State:                record.State,
+			Wchan:                record.Wchan,
+			Nice:                 record.Nice,
+			Threads:              record.Threads,
+			Tty:                  record.Tty,
+			stuckUpdates:         record.StuckUpdates,
+			stuckSince:           record.StuckSince,
+			history:              histories.byPid[record.Pid].history,
+			Nativity:             record.Nativity,
+			SubtreeNativity:      record.SubtreeNativity,
+		}
+	}
+	resolveLinks(procsMap)
+
+	procs := make([]Process, 0, len(procsMap))
+	for _, p := range procsMap {
+		procs = append(procs, *p)
+	}
+	return procs
+}
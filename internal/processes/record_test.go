@@ -0,0 +1,39 @@
+package processes
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/walles/ftop/internal/assert"
+)
+
+func TestRecordAndRestore(t *testing.T) {
+	zero := time.Duration(0)
+	startTime := time.Date(2026, 10, 17, 3, 0, 0, 0, time.UTC)
+	parent := &Process{Pid: 1, Cmdline: "init", startTime: startTime}
+	child := &Process{Pid: 2, ppid: 1, Cmdline: "sleep 1000", startTime: startTime, CpuTime: &zero}
+
+	bytes, err := json.Marshal([]ProcessRecord{parent.Record(), child.Record()})
+	assert.Equal(t, err, nil)
+	var records []ProcessRecord
+	assert.Equal(t, json.Unmarshal(bytes, &records), nil)
+
+	histories := NewRecordHistories()
+	first := RestoreProcesses(records, histories, startTime)
+	for _, p := range first {
+		if p.Pid == 2 {
+			assert.Equal(t, p.Parent().Pid, 1)
+
+			// Known zero is not the same as unknown
+			assert.Equal(t, p.CpuTimeString(), "0ms")
+		}
+		assert.Equal(t, len(p.History().Samples()), 1)
+	}
+
+	// Histories should carry over to the next restore
+	second := RestoreProcesses(records, histories, startTime.Add(time.Second))
+	for _, p := range second {
+		assert.Equal(t, len(p.History().Samples()), 2)
+	}
+}
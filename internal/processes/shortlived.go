@@ -0,0 +1,87 @@
+package processes
+
+import (
+	"os"
+	"time"
+)
+
+// A process the OS told us was launched. If it's gone before the next tracker
+// update, this is the only trace we'll have of it.
+type launchedProcess struct {
+	pid       int
+	ppid      int
+	cmdline   string
+	startTime time.Time
+	exited    bool
+}
+
+// Find the launched processes that neither the previous nor the current
+// tracker update saw. Those are returned as processes, linked to their parents
+// if we know about them.
+//
+// Launches we can't tell about yet, because they happened after updateTime and
+// may show up in the next update, are returned as undecided.
+func findMissedLaunches(launched []launchedProcess, previous map[int]*Process, current map[int]*Process, updateTime time.Time) (missed []*Process, undecided []launchedProcess) {
+	missedByPid := make(map[int]*Process)
+	for _, launch := range launched {
+		_, wasSeen := previous[launch.pid]
+		_, isSeen := current[launch.pid]
+		if wasSeen || isSeen {
+			// The tracker knows about this one
+			continue
+		}
+
+		if launch.cmdline == "" {
+			// Gone before we could tell what it was
+			continue
+		}
+
+		if !launch.exited && !launch.startTime.Before(updateTime) {
+			undecided = append(undecided, launch)
+			continue
+		}
+
+		proc := &Process{
+			Pid:       launch.pid,
+			ppid:      launch.ppid,
+			Cmdline:   launch.cmdline,
+			startTime: launch.startTime,
+		}
+		missed = append(missed, proc)
+		missedByPid[proc.Pid] = proc
+	}
+
+	for _, proc := range missed {
+		// Parents could have exited since the previous update, or have been
+		// missed themselves. Parents are born before their children, checking
+		// that protects us from loops if PIDs have been reused.
+		for _, candidates := range []map[int]*Process{current, previous, missedByPid} {
+			parent, found := candidates[proc.ppid]
+			if found && !parent.startTime.After(proc.startTime) {
+				proc.parent = parent
+				break
+			}
+		}
+	}
+
+	return missed, undecided
+}
+
+// Count launches the tracker didn't see itself, and remember their birth times
+// for their parents' nativities
+func trackMissedLaunches(root *LaunchNode, missed []*Process, current map[int]*Process) *LaunchNode {
+	for _, proc := range missed {
+		if proc.ppid == os.Getpid() && shouldHideSelfChild(proc) {
+			// Same as removeSelfChildren() does for live processes
+			continue
+		}
+
+		root = incrementLaunchCount(root, proc)
+
+		if parent, found := current[proc.ppid]; found && proc.parent == parent {
+			parent.deadChildrenBirthTimes = append(parent.deadChildrenBirthTimes, proc.startTime)
+		}
+	}
+
+	return root
+}
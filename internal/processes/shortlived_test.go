@@ -0,0 +1,74 @@
+package processes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/walles/ftop/internal/assert"
+)
+
+func TestFindMissedLaunches(t *testing.T) {
+	updateTime := time.Now()
+	before := updateTime.Add(-500 * time.Millisecond)
+
+	init := &Process{Pid: 1, Cmdline: "init", startTime: before.Add(-time.Hour)}
+	brew := &Process{Pid: 2, Cmdline: "brew", startTime: before.Add(-time.Minute), parent: init}
+	gone := &Process{Pid: 3, Cmdline: "make", startTime: before.Add(-time.Minute), parent: init}
+	previous := map[int]*Process{1: init, 2: brew, 3: gone}
+	current := map[int]*Process{1: init, 2: brew}
+
+	missed, undecided := findMissedLaunches([]launchedProcess{
+		// Seen by the tracker, not missed
+		{pid: 2, ppid: 1, cmdline: "brew", startTime: before, exited: false},
+
+		// Came and went between updates
+		{pid: 10, ppid: 2, cmdline: "sh -c curl", startTime: before, exited: true},
+		{pid: 11, ppid: 10, cmdline: "curl", startTime: before.Add(time.Millisecond), exited: true},
+
+		// Parent exited since the previous update
+		{pid: 12, ppid: 3, cmdline: "cc", startTime: before, exited: true},
+
+		// Exit event lost, but the update should have seen it if it was alive
+		{pid: 13, ppid: 2, cmdline: "git", startTime: before, exited: false},
+
+		// Launched after the update started, may be in the next one
+		{pid: 14, ppid: 2, cmdline: "curl", startTime: updateTime.Add(time.Millisecond), exited: false},
+
+		// Nothing to show for this one
+		{pid: 15, ppid: 2, cmdline: "", startTime: before, exited: true},
+	}, previous, current, updateTime)
+
+	assert.Equal(t, len(missed), 4)
+	assert.Equal(t, missed[0].Pid, 10)
+	assert.Equal(t, missed[0].parent, brew)
+	assert.Equal(t, missed[1].Pid, 11)
+	assert.Equal(t, missed[1].parent, missed[0])
+	assert.Equal(t, missed[2].Pid, 12)
+	assert.Equal(t, missed[2].parent, gone)
+	assert.Equal(t, missed[3].Pid, 13)
+
+	assert.Equal(t, len(undecided), 1)
+	assert.Equal(t, undecided[0].pid, 14)
+}
+
+func TestTrackMissedLaunches(t *testing.T) {
+	init := &Process{Pid: 1, Cmdline: "init"}
+	brew := &Process{Pid: 2, Cmdline: "brew", parent: init}
+	curl1 := &Process{Pid: 10, ppid: 2, Cmdline: "curl", parent: brew, startTime: time.Now()}
+	curl2 := &Process{Pid: 11, ppid: 2, Cmdline: "curl", parent: brew, startTime: time.Now()}
+	current := map[int]*Process{1: init, 2: brew}
+
+	root := trackMissedLaunches(nil, []*Process{curl1, curl2}, current)
+	assertAncestry(t, root, ancestry{
+		Command: "init",
+		Children: []ancestry{{
+			Command: "brew",
+			Children: []ancestry{
+				{Command: "curl", LaunchCount: 2},
+			},
+		}},
+	})
+
+	fillInNativities(current)
+	assert.Equal(t, brew.Nativity, 2)
+}
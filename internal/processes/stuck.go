@@ -0,0 +1,67 @@
+package processes
+
+import "time"
+
+// How many consecutive tracker updates a process must spend in D or Z state
+// before we call it stuck. Processes doing disk IO pass through D state all the
+// time, so seeing that once doesn't mean anything.
+const STUCK_MIN_UPDATES = 3
+
+// Human readable version of State, suitable for "bash(1234) is ..." sentences
+func (p *Process) StateDescription() string {
+	switch p.State {
+	case "R":
+		return "running"
+	case "S":
+		return "sleeping"
+	case "D":
+		return "in uninterruptible sleep"
+	case "Z":
+		return "a zombie"
+	case "T", "t":
+		return "stopped"
+	case "I":
+		return "idle"
+	case "":
+		return "in an unknown state"
+	}
+
+	return "in state " + p.State
+}
+
+// True if this process has been in D or Z state for at least
+// STUCK_MIN_UPDATES consecutive tracker updates.
+func (p *Process) IsStuck() bool {
+	return p.stuckUpdates >= STUCK_MIN_UPDATES
+}
+
+// When we first saw this process in its current D or Z state. Only meaningful
+// if IsStuck() returns true.
+func (p *Process) StuckSince() time.Time {
+	return p.stuckSince
+}
+
+func isStuckState(state string) bool {
+	return state == "D" || state == "Z"
+}
+
+// Count how many consecutive updates each process has been in D or Z state.
+// Staying in D state but switching to Z counts as a new state.
+func trackStuckProcesses(procs map[int]*Process, matching ProcessMatching, now time.Time) {
+	for _, proc := range procs {
+		if !isStuckState(proc.State) {
+			continue
+		}
+
+		proc.stuckUpdates = 1
+		proc.stuckSince = now
+
+		match, found := matching.Matched[proc.Pid]
+		if !found || match.Old.State != proc.State {
+			continue
+		}
+
+		proc.stuckUpdates = match.Old.stuckUpdates + 1
+		proc.stuckSince = match.Old.stuckSince
+	}
+}
@@ -0,0 +1,49 @@
+package processes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/walles/ftop/internal/assert"
+)
+
+func TestTrackStuckProcesses(t *testing.T) {
+	startTime := time.Date(2026, 2, 18, 10, 0, 0, 0, time.UTC)
+	t0 := startTime.Add(time.Minute)
+
+	current := map[int]*Process{
+		1: {Pid: 1, startTime: startTime, State: "D"},
+		2: {Pid: 2, startTime: startTime, State: "S"},
+		3: {Pid: 3, startTime: startTime, State: "Z"},
+	}
+	trackStuckProcesses(current, ProcessMatching{}, t0)
+
+	for i := 1; i < STUCK_MIN_UPDATES; i++ {
+		previous := current
+		current = map[int]*Process{
+			1: {Pid: 1, startTime: startTime, State: "D"},
+			2: {Pid: 2, startTime: startTime, State: "S"},
+			3: {Pid: 3, startTime: startTime, State: "Z"},
+		}
+		if i == STUCK_MIN_UPDATES-1 {
+			// Process 1 finishes its IO just before it would have counted as stuck
+			current[1].State = "R"
+		}
+
+		matches, err := buildProcessMatches(previous, current)
+		assert.Equal(t, err, nil)
+		trackStuckProcesses(current, matches, t0.Add(time.Duration(i)*time.Second))
+	}
+
+	assert.Equal(t, current[1].IsStuck(), false)
+	assert.Equal(t, current[2].IsStuck(), false)
+	assert.Equal(t, current[3].IsStuck(), true)
+	assert.Equal(t, current[3].StuckSince(), t0)
+}
+
+func TestStateDescription(t *testing.T) {
+	assert.Equal(t, (&Process{State: "Z"}).StateDescription(), "a zombie")
+	assert.Equal(t, (&Process{State: "D"}).StateDescription(), "in uninterruptible sleep")
+	assert.Equal(t, (&Process{State: "W"}).StateDescription(), "in state W")
+	assert.Equal(t, (&Process{}).StateDescription(), "in an unknown state")
+}
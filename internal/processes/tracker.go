@@ -16,10 +16,21 @@ type Tracker struct {
 	current  map[int]*Process
 	launches *LaunchNode
 
+	// When current was collected
+	currentTime time.Time
+
 	longestCommandLength int
 
 	deduplicator deduplicator
 
+	// Tells us about processes launched between updates. nil if we can't
+	// listen for that, we only poll then.
+	connector *procConnector
+
+	// Launches the connector told us about, that may show up in the next
+	// update
+	undecidedLaunches []launchedProcess
+
 	OnUpdate chan struct{} // Call GetProcesses() to get the updated list
 }
 
@@ -28,6 +39,13 @@ func NewTracker() *Tracker {
 	tracker.OnUpdate = make(chan struct{}, 1)
 	tracker.deduplicator = deduplicator{}
 
+	connector, err := startProcConnector()
+	if err == nil {
+		tracker.connector = connector
+	} else {
+		log.Infof("Not listening for process events, short-lived processes may go unnoticed: %v", err)
+	}
+
 	// Periodically update the process list
 	go func() {
 		defer func() {
@@ -67,6 +85,7 @@ func isFatal(err error) bool {
 }
 
 func (tracker *Tracker) update() {
+	now := time.Now()
 	procs, err := GetAll()
 	if err != nil {
 		if isFatal(err) {
@@ -133,16 +152,29 @@ func (tracker *Tracker) update() {
 		// Update launch counts tree
 		tracker.launches = updateLaunches(tracker.launches, matches)
 
+		if tracker.connector != nil {
+			// Count what came and went since the previous update
+			launched := append(tracker.undecidedLaunches, tracker.connector.takeLaunched(now)...)
+			var missed []*Process
+			missed, tracker.undecidedLaunches = findMissedLaunches(launched, tracker.current, procsMap, now)
+			tracker.launches = trackMissedLaunches(tracker.launches, missed, procsMap)
+		}
+
 		trackDeaths(matches)
+
+		fillInIoRates(matches, now.Sub(tracker.currentTime))
 	}
 
 	fillInNativities(procsMap)
+	trackStuckProcesses(procsMap, matches, now)
+	trackHistory(procsMap, matches, now)
 
 	if tracker.baseline == nil {
 		// First iteration
 		tracker.baseline = procsMap
 	}
 	tracker.current = procsMap
+	tracker.currentTime = now
 
 	tracker.mutex.Unlock()
 
@@ -164,6 +196,9 @@ func (tracker *Tracker) Processes() []Process {
 			// No baseline yet, all processes are new
 			zero := time.Duration(0)
 			proc.CpuTime = &zero
+			if proc.ChildrenCpuTime != nil {
+				proc.ChildrenCpuTime = &zero
+			}
 		} else {
 			// For processes already running when we launched, report their
 			// times relative to our start time.
@@ -175,6 +210,10 @@ func (tracker *Tracker) Processes() []Process {
 				adjusted := *proc.CpuTime - *baseProc.CpuTime
 				proc.CpuTime = &adjusted
 			}
+			if ok && proc.SameAs(baseProc) && proc.ChildrenCpuTime != nil && baseProc.ChildrenCpuTime != nil {
+				adjusted := *proc.ChildrenCpuTime - *baseProc.ChildrenCpuTime
+				proc.ChildrenCpuTime = &adjusted
+			}
 		}
 		procs = append(procs, proc)
 	}
@@ -0,0 +1,379 @@
+package snapshots
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"runtime/debug"
+	"sync"
+	"syscall"
+
+	"github.com/walles/ftop/internal/log"
+	"github.com/walles/ftop/internal/processes"
+	"golang.org/x/sys/unix"
+)
+
+// Agents talk to their clients using gzipped JSON, one document per line. The
+// first line is an agentHello, and each line after that is an agentMessage.
+//
+// Clients talk back using uncompressed JSON, one clientMessage per line.
+const agentFormat = "ftop-agent"
+
+// Clients' requests are small, anything longer than this is an attack or a bug
+const maxRequestLength = 1024 * 1024
+
+// Frames list every process on the agent's machine, but even on machines with
+// tens of thousands of processes they stay well below this
+const maxMessageLength = 256 * 1024 * 1024
+
+// Bump this whenever an agent can't talk to an older client any more. Clients
+// refuse to talk to agents using newer versions than they know of.
+const AGENT_PROTOCOL_VERSION = 1
+
+type agentHello struct {
+	Format  string `json:"format"`
+	Version int    `json:"version"`
+
+	// Name of the machine the agent is running on
+	Hostname string `json:"hostname"`
+
+	// If false, the agent will refuse all signalRequests
+	SignalsAllowed bool `json:"signalsAllowed"`
+}
+
+// Exactly one field is set
+type agentMessage struct {
+	Frame        *frame        `json:"frame,omitempty"`
+	SignalResult *signalResult `json:"signalResult,omitempty"`
+}
+
+// Exactly one field is set
+type clientMessage struct {
+	Signal *signalRequest `json:"signal,omitempty"`
+}
+
+type signalRequest struct {
+	Id int `json:"id"` // Sent back in the signalResult
+
+	// "TERM" for SIGTERM. Signal numbers differ between platforms, names
+	// don't.
+	Signal string `json:"signal"`
+
+	// Parents before children. Only the fields needed for
+	// processes.Process.SameAsRecord() are used, the agent won't signal
+	// processes whose PIDs have been reused since.
+	Targets []processes.ProcessRecord `json:"targets"`
+}
+
+type signalResult struct {
+	Id    int    `json:"id"`
+	Error string `json:"error,omitempty"` // Empty if the signal was sent
+}
+
+// Streams snapshots from a source to any number of clients, see Serve()
+type Agent struct {
+	source         Source
+	signalsAllowed bool
+	hostname       string
+
+	mutex     sync.Mutex
+	latest    []byte // JSON encoded agentMessage with a frame in it
+	listeners map[chan struct{}]bool
+}
+
+// If signalsAllowed is false, clients can look but not kill
+func NewAgent(source Source, signalsAllowed bool) *Agent {
+	hostname, err := os.Hostname()
+	if err != nil {
+		log.Infof("Failed to get hostname: %v", err)
+		hostname = "unknown"
+	}
+
+	agent := &Agent{
+		source:         source,
+		signalsAllowed: signalsAllowed,
+		hostname:       hostname,
+		listeners:      make(map[chan struct{}]bool),
+	}
+
+	go func() {
+		defer func() {
+			log.PanicHandler("agent", recover(), debug.Stack())
+		}()
+
+		for range source.OnUpdate() {
+			f := toFrame(source.Snapshot())
+			encoded, err := json.Marshal(agentMessage{Frame: &f})
+			if err != nil {
+				log.Errorf("Failed to encode frame: %v", err)
+				continue
+			}
+
+			agent.publish(encoded)
+		}
+	}()
+
+	return agent
+}
+
+func (a *Agent) publish(encoded []byte) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.latest = encoded
+	for listener := range a.listeners {
+		// Slow clients skip frames rather than falling behind
+		select {
+		case listener <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Accept clients on a TCP address until that fails
+func (a *Agent) Listen(address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+
+		go func() {
+			defer func() {
+				log.PanicHandler("agent connection", recover(), debug.Stack())
+			}()
+			defer func() {
+				_ = conn.Close()
+			}()
+
+			err := a.Serve(conn, conn)
+			log.Infof("Client %s went away: %v", conn.RemoteAddr(), err)
+		}()
+	}
+}
+
+// Talk to one client until it goes away. Returns nil if the input ended, which
+// is what happens when the client closes the connection.
+func (a *Agent) Serve(input io.Reader, output io.Writer) error {
+	zipped := gzip.NewWriter(output)
+	var writeMutex sync.Mutex
+	writeLine := func(line []byte) error {
+		writeMutex.Lock()
+		defer writeMutex.Unlock()
+
+		_, err := zipped.Write(append(line, '\n'))
+		if err != nil {
+			return err
+		}
+
+		// Don't keep the client waiting for more data to compress
+		return zipped.Flush()
+	}
+
+	hello, err := json.Marshal(agentHello{
+		Format:         agentFormat,
+		Version:        AGENT_PROTOCOL_VERSION,
+		Hostname:       a.hostname,
+		SignalsAllowed: a.signalsAllowed,
+	})
+	if err != nil {
+		return err
+	}
+	err = writeLine(hello)
+	if err != nil {
+		return err
+	}
+
+	listener := make(chan struct{}, 1)
+	a.mutex.Lock()
+	a.listeners[listener] = true
+	if a.latest != nil {
+		// Don't make new clients wait for the next update
+		listener <- struct{}{}
+	}
+	a.mutex.Unlock()
+
+	defer func() {
+		a.mutex.Lock()
+		delete(a.listeners, listener)
+		a.mutex.Unlock()
+	}()
+
+	done := make(chan error, 2)
+	go func() {
+		defer func() {
+			log.PanicHandler("agent reader", recover(), debug.Stack())
+		}()
+		done <- a.handleRequests(input, writeLine)
+	}()
+
+	for {
+		select {
+		case err := <-done:
+			return err
+		case <-listener:
+		}
+
+		a.mutex.Lock()
+		encoded := a.latest
+		a.mutex.Unlock()
+
+		err := writeLine(encoded)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Returns nil when the input ends
+func (a *Agent) handleRequests(input io.Reader, writeLine func([]byte) error) error {
+	reader := bufio.NewReader(input)
+	for {
+		line, err := readLine(reader, maxRequestLength)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var message clientMessage
+		err = json.Unmarshal(line, &message)
+		if err != nil {
+			return fmt.Errorf("bad request from client: %w", err)
+		}
+		if message.Signal == nil {
+			// From some newer client, ignore
+			continue
+		}
+
+		result := signalResult{Id: message.Signal.Id}
+		err = a.signal(*message.Signal)
+		if err != nil {
+			result.Error = err.Error()
+		}
+
+		encoded, err := json.Marshal(agentMessage{SignalResult: &result})
+		if err != nil {
+			return err
+		}
+		err = writeLine(encoded)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Like reader.ReadBytes('\n'), but gives up on lines longer than maxLength
+// rather than buffering any amount of data
+func readLine(reader *bufio.Reader, maxLength int) ([]byte, error) {
+	var line []byte
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		if len(line)+len(chunk) > maxLength {
+			return nil, fmt.Errorf("line longer than %d bytes", maxLength)
+		}
+		line = append(line, chunk...)
+
+		if errors.Is(err, bufio.ErrBufferFull) {
+			// No newline yet, keep reading
+			continue
+		}
+		return line, err
+	}
+}
+
+// Signal the requested processes. Processes that have gone away are skipped,
+// and so are processes whose PIDs now belong to some other process.
+func (a *Agent) signal(request signalRequest) error {
+	if !a.signalsAllowed {
+		return fmt.Errorf("not allowed, start the agent with --allow-kill to allow it")
+	}
+
+	signal := unix.SignalNum("SIG" + request.Signal)
+	if signal == 0 {
+		return fmt.Errorf("unknown signal %s", request.Signal)
+	}
+
+	byPid := make(map[int]processes.Process)
+	for _, p := range a.source.Snapshot().Processes {
+		byPid[p.Pid] = p
+	}
+
+	var firstErr error
+	failures := 0
+	for _, target := range request.Targets {
+		err := signalTarget(byPid, target, signal)
+		if err != nil {
+			failures++
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	if firstErr == nil {
+		return nil
+	}
+	if failures == len(request.Targets) {
+		return firstErr
+	}
+	return fmt.Errorf("%w (%d of %d processes)", firstErr, failures, len(request.Targets))
+}
+
+func signalTarget(byPid map[int]processes.Process, target processes.ProcessRecord, signal syscall.Signal) error {
+	if target.Pid == os.Getpid() {
+		return fmt.Errorf("the agent won't signal itself")
+	}
+
+	p, found := byPid[target.Pid]
+	if !found || !p.SameAsRecord(target) {
+		// Already gone, that's what the client wanted
+		return nil
+	}
+
+	handle, err := p.OpenHandle()
+	if errors.Is(err, processes.ErrPidReused) || errors.Is(err, syscall.ESRCH) || errors.Is(err, os.ErrNotExist) {
+		// Whatever has this PID now, it's not what the client picked
+		return nil
+	}
+	if err == nil {
+		defer func() {
+			_ = handle.Close()
+		}()
+		err = handle.Signal(signal)
+	} else {
+		if !errors.Is(err, errors.ErrUnsupported) {
+			log.Infof("Failed to open a handle for %s, falling back to its PID: %v", p.String(), err)
+		}
+
+		var osProcess *os.Process
+		osProcess, err = os.FindProcess(p.Pid)
+		if err == nil {
+			err = osProcess.Signal(signal)
+		}
+	}
+	if errors.Is(err, os.ErrProcessDone) || errors.Is(err, syscall.ESRCH) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("Sent signal %d to process %s on behalf of a client", signal, p.String())
+	return nil
+}
@@ -0,0 +1,131 @@
+package snapshots
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os/exec"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/walles/ftop/internal/assert"
+	"github.com/walles/ftop/internal/processes"
+	"github.com/walles/ftop/internal/sysload"
+)
+
+// One end of a connection between an agent and a client
+type pipeConn struct {
+	io.Reader
+	io.WriteCloser
+}
+
+// Returns a connected client, and a channel receiving what Serve() returned
+// once the client has gone away
+func connectToAgent(t *testing.T, agent *Agent) (*Remote, chan error) {
+	clientReader, agentWriter := io.Pipe()
+	agentReader, clientWriter := io.Pipe()
+
+	served := make(chan error, 1)
+	go func() {
+		served <- agent.Serve(agentReader, agentWriter)
+		_ = agentWriter.Close()
+	}()
+
+	remote, err := NewRemote(pipeConn{Reader: clientReader, WriteCloser: clientWriter})
+	assert.Equal(t, err, nil)
+	return remote, served
+}
+
+func TestAgentRoundTrip(t *testing.T) {
+	source := &fakeSource{onUpdate: make(chan struct{})}
+	source.snapshot = Snapshot{
+		Timestamp: time.Date(2026, 10, 17, 3, 0, 0, 0, time.UTC),
+		Processes: []processes.Process{
+			{Pid: 1, Cmdline: "init", Username: "root", RssKb: 100},
+			{Pid: 2, Cmdline: "sleep 1000", Username: "johan", RssKb: 20},
+		},
+		Sysload: sysload.Sysload{CpuCoresLogical: 4},
+	}
+	agent := NewAgent(source, false)
+
+	remote, served := connectToAgent(t, agent)
+	assert.Equal(t, remote.SignalsAllowed(), false)
+
+	source.onUpdate <- struct{}{}
+	<-remote.OnUpdate()
+
+	snapshot := remote.Snapshot()
+	assert.Equal(t, snapshot.Timestamp, source.snapshot.Timestamp)
+	assert.Equal(t, snapshot.Sysload.CpuCoresLogical, 4)
+	assert.Equal(t, len(snapshot.Processes), 2)
+
+	// Restored processes come in no particular order
+	sleep := snapshot.Processes[0]
+	if sleep.Pid != 2 {
+		sleep = snapshot.Processes[1]
+	}
+	assert.Equal(t, sleep.Command(), "sleep")
+	assert.Equal(t, sleep.Parent(), nil)
+	assert.Equal(t, remote.IsAlive(&sleep), true)
+
+	// The client shouldn't even ask
+	err := remote.Signal([]*processes.Process{&sleep}, "TERM")
+	assert.Equal(t, err != nil, true)
+
+	// The agent shouldn't do it even if asked
+	err = agent.signal(signalRequest{Signal: "TERM", Targets: []processes.ProcessRecord{{Pid: 2}}})
+	assert.Equal(t, err != nil, true)
+
+	remote.Close()
+	assert.Equal(t, <-served, nil)
+}
+
+func TestAgentSignal(t *testing.T) {
+	sleep := exec.Command("sleep", "100")
+	assert.Equal(t, sleep.Start(), nil)
+	defer func() {
+		_ = sleep.Process.Kill()
+	}()
+
+	source := &fakeSource{onUpdate: make(chan struct{})}
+	source.snapshot = Snapshot{
+		Processes: []processes.Process{{Pid: sleep.Process.Pid, Cmdline: "sleep 100"}},
+	}
+	agent := NewAgent(source, true)
+
+	remote, _ := connectToAgent(t, agent)
+	defer remote.Close()
+	assert.Equal(t, remote.SignalsAllowed(), true)
+
+	source.onUpdate <- struct{}{}
+	<-remote.OnUpdate()
+	target := remote.Snapshot().Processes[0]
+
+	// Not the same process, since it started at some other time. Should be
+	// silently skipped, just like a process that has already exited. If it
+	// isn't, sleep will die from SIGKILL rather than from SIGTERM below.
+	impostor := processes.RestoreProcesses([]processes.ProcessRecord{{
+		Pid:       sleep.Process.Pid,
+		StartTime: time.Now().Add(-time.Hour),
+	}}, processes.NewRecordHistories(), time.Now())[0]
+	assert.Equal(t, remote.Signal([]*processes.Process{&impostor}, "KILL"), nil)
+
+	assert.Equal(t, remote.Signal([]*processes.Process{&target}, "TERM"), nil)
+
+	var exitError *exec.ExitError
+	assert.Equal(t, errors.As(sleep.Wait(), &exitError), true)
+	assert.Equal(t, exitError.Sys().(syscall.WaitStatus).Signal(), syscall.SIGTERM)
+}
+
+func TestReadLine(t *testing.T) {
+	reader := bufio.NewReaderSize(strings.NewReader("short\n"+strings.Repeat("x", 100)+"\n"), 16)
+
+	line, err := readLine(reader, 50)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, string(line), "short\n")
+
+	_, err = readLine(reader, 50)
+	assert.Equal(t, err != nil, true)
+}
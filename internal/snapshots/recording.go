@@ -0,0 +1,376 @@
+package snapshots
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	ftopio "github.com/walles/ftop/internal/io"
+	"github.com/walles/ftop/internal/log"
+	"github.com/walles/ftop/internal/processes"
+	"github.com/walles/ftop/internal/sysload"
+)
+
+// Recordings are gzipped, with one JSON document per line. The first line is a
+// recordingHeader, and each line after that is one frame.
+//
+// Each line is a gzip member of its own, so that a replay can start reading
+// from any line without decompressing everything before it. Older recordings
+// have all lines in a single member, those can be replayed too, just slower.
+const recordingFormat = "ftop-recording"
+
+// Bump this whenever a recording written by the new ftop can't be replayed by
+// an older one
+const RECORDING_VERSION = 1
+
+type recordingHeader struct {
+	Format  string `json:"format"`
+	Version int    `json:"version"`
+}
+
+// One snapshot, in a form that can be written to disk and read back
+type frame struct {
+	Timestamp time.Time                 `json:"timestamp"`
+	Processes []processes.ProcessRecord `json:"processes"`
+	IoStats   []ftopio.Stat             `json:"ioStats"`
+	Sysload   sysload.Sysload           `json:"sysload"`
+	Launches  *processes.LaunchNode     `json:"launches,omitempty"`
+}
+
+func toFrame(snapshot Snapshot) frame {
+	records := make([]processes.ProcessRecord, 0, len(snapshot.Processes))
+	for _, p := range snapshot.Processes {
+		records = append(records, p.Record())
+	}
+
+	return frame{
+		Timestamp: snapshot.Timestamp,
+		Processes: records,
+		IoStats:   snapshot.IoStats,
+		Sysload:   snapshot.Sysload,
+		Launches:  snapshot.Launches,
+	}
+}
+
+// Passes snapshots from another source through unchanged, writing each one of
+// them to a recording file on the way.
+type Recorder struct {
+	source   Source
+	onUpdate chan struct{}
+
+	mutex  sync.Mutex
+	latest Snapshot
+	file   *os.File
+	gzip   *gzip.Writer // Reused for all lines, nil after Close() or after a write failure
+}
+
+// Creates or truncates the recording file at path
+func NewRecorder(source Source, path string) (*Recorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	recorder := &Recorder{
+		source:   source,
+		onUpdate: make(chan struct{}, 1),
+		file:     file,
+		gzip:     gzip.NewWriter(file),
+	}
+
+	err = recorder.writeLine(recordingHeader{Format: recordingFormat, Version: RECORDING_VERSION})
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	go func() {
+		defer func() {
+			log.PanicHandler("recorder", recover(), debug.Stack())
+		}()
+
+		for range source.OnUpdate() {
+			recorder.record(source.Snapshot())
+
+			// Notify asynchronously in case nobody is listening
+			select {
+			case recorder.onUpdate <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	return recorder, nil
+}
+
+func (r *Recorder) OnUpdate() <-chan struct{} {
+	return r.onUpdate
+}
+
+func (r *Recorder) Wrapped() Source {
+	return r.source
+}
+
+// The latest snapshot we have recorded
+func (r *Recorder) Snapshot() Snapshot {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.latest
+}
+
+func (r *Recorder) record(snapshot Snapshot) {
+	r.mutex.Lock()
+	r.latest = snapshot
+	r.mutex.Unlock()
+
+	err := r.writeLine(toFrame(snapshot))
+	if err != nil {
+		log.Errorf("Recording to %s failed, giving up: %v", r.file.Name(), err)
+		r.Close()
+	}
+}
+
+// Writes each line as a gzip member of its own. That way everything up until the
+// last line is replayable even if we get killed before Close() is called.
+func (r *Recorder) writeLine(value any) error {
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.gzip == nil {
+		// Closed, nothing to do
+		return nil
+	}
+
+	r.gzip.Reset(r.file)
+	_, err = r.gzip.Write(append(bytes, '\n'))
+	if err != nil {
+		return err
+	}
+
+	return r.gzip.Close()
+}
+
+// Stop recording and close the recording file. Snapshots will still be passed
+// through.
+func (r *Recorder) Close() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.gzip == nil {
+		return
+	}
+
+	// Each line is a complete gzip member, so only the file needs closing
+	err := r.file.Close()
+	if err != nil {
+		log.Errorf("Closing recording %s failed: %v", r.file.Name(), err)
+	}
+
+	r.gzip = nil
+}
+
+// Frames read from a recording file when they are needed, so that replaying a
+// long recording doesn't need RAM for all of it. Only where each frame is in
+// the file is kept in memory.
+type recordingFrames struct {
+	file      *os.File
+	locations []frameLocation
+
+	// Where the last frame was read from, so that reading frames in order
+	// doesn't have to start over from the beginning of a gzip member for each
+	// one. nil before the first read.
+	cursor       *bufio.Reader
+	cursorOffset int64
+	cursorLine   int // Line in the cursorOffset member the cursor is at
+}
+
+type frameLocation struct {
+	offset    int64 // Of the gzip member the frame is in
+	line      int   // Zero based, in that member
+	timestamp time.Time
+}
+
+// Index the frames in a recording. Close the result when done.
+func openRecording(path string) (*recordingFrames, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	locations, err := indexRecording(file, path)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	return &recordingFrames{file: file, locations: locations}, nil
+}
+
+// Counts the bytes read through it
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count += int64(n)
+	return n, err
+}
+
+// Find all frames in a recording, one gzip member at a time
+func indexRecording(file io.Reader, path string) ([]frameLocation, error) {
+	counter := &countingReader{reader: file}
+
+	// gzip reads no further than it has to from a bufio.Reader, so the counter
+	// minus what's buffered is where the next member starts
+	buffered := bufio.NewReader(counter)
+	unzipped, err := gzip.NewReader(buffered)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not an ftop recording: %w", path, err)
+	}
+
+	var locations []frameLocation
+	memberOffset := int64(0)
+	lineNumber := 1
+	for {
+		unzipped.Multistream(false)
+		lines := bufio.NewReader(unzipped)
+		done := false
+		for line := 0; ; line++ {
+			bytes, err := lines.ReadBytes('\n')
+			if errors.Is(err, io.EOF) && len(bytes) == 0 {
+				// End of this member
+				break
+			}
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				// We end every line with a newline, so if there's anything left
+				// here, the recording was cut short. By ftop getting killed for
+				// example. Everything before this point is still good.
+				if len(bytes) > 0 {
+					log.Infof("Ignoring incomplete last line %d of %s", lineNumber, path)
+				}
+				done = true
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			if lineNumber == 1 {
+				var header recordingHeader
+				err = json.Unmarshal(bytes, &header)
+				if err != nil || header.Format != recordingFormat {
+					return nil, fmt.Errorf("%s is not an ftop recording", path)
+				}
+				if header.Version > RECORDING_VERSION {
+					return nil, fmt.Errorf("%s is a version %d recording, this ftop only supports up to version %d", path, header.Version, RECORDING_VERSION)
+				}
+			} else {
+				var timestamp struct {
+					Timestamp time.Time `json:"timestamp"`
+				}
+				err = json.Unmarshal(bytes, &timestamp)
+				if err != nil {
+					return nil, fmt.Errorf("%s line %d: %w", path, lineNumber, err)
+				}
+				locations = append(locations, frameLocation{offset: memberOffset, line: line, timestamp: timestamp.Timestamp})
+			}
+			lineNumber++
+		}
+		if done {
+			break
+		}
+
+		memberOffset = counter.count - int64(buffered.Buffered())
+		err = unzipped.Reset(buffered)
+		if errors.Is(err, io.EOF) {
+			// No more members
+			break
+		}
+		if err != nil {
+			// Cut short in the middle of a member header
+			log.Infof("Ignoring incomplete end of %s: %v", path, err)
+			break
+		}
+	}
+
+	if locations == nil {
+		return nil, fmt.Errorf("%s contains no snapshots", path)
+	}
+
+	return locations, nil
+}
+
+func (f *recordingFrames) count() int {
+	return len(f.locations)
+}
+
+func (f *recordingFrames) timestamp(index int) time.Time {
+	return f.locations[index].timestamp
+}
+
+// If reading fails, which it shouldn't since we have read everything once
+// already, the frame will have no processes
+func (f *recordingFrames) frame(index int) frame {
+	location := f.locations[index]
+	read, err := f.read(location)
+	if err != nil {
+		log.Errorf("Reading frame %d from %s failed: %v", index, f.file.Name(), err)
+		return frame{Timestamp: location.timestamp}
+	}
+	return read
+}
+
+func (f *recordingFrames) read(location frameLocation) (frame, error) {
+	if f.cursor == nil || f.cursorOffset != location.offset || f.cursorLine > location.line {
+		unzipped, err := gzip.NewReader(bufio.NewReader(io.NewSectionReader(f.file, location.offset, math.MaxInt64-location.offset)))
+		if err != nil {
+			f.cursor = nil
+			return frame{}, err
+		}
+		unzipped.Multistream(false)
+
+		f.cursor = bufio.NewReader(unzipped)
+		f.cursorOffset = location.offset
+		f.cursorLine = 0
+	}
+
+	for ; f.cursorLine < location.line; f.cursorLine++ {
+		_, err := f.cursor.ReadBytes('\n')
+		if err != nil {
+			f.cursor = nil
+			return frame{}, err
+		}
+	}
+
+	bytes, err := f.cursor.ReadBytes('\n')
+	if err != nil {
+		f.cursor = nil
+		return frame{}, err
+	}
+	f.cursorLine++
+
+	var read frame
+	err = json.Unmarshal(bytes, &read)
+	return read, err
+}
+
+func (f *recordingFrames) Close() {
+	err := f.file.Close()
+	if err != nil {
+		log.Infof("Closing %s failed: %v", f.file.Name(), err)
+	}
+}
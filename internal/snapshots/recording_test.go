@@ -0,0 +1,154 @@
+package snapshots
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/walles/ftop/internal/assert"
+	"github.com/walles/ftop/internal/io"
+	"github.com/walles/ftop/internal/processes"
+	"github.com/walles/ftop/internal/sysload"
+)
+
+type fakeSource struct {
+	onUpdate chan struct{}
+	snapshot Snapshot
+}
+
+func (s *fakeSource) OnUpdate() <-chan struct{} {
+	return s.onUpdate
+}
+
+func (s *fakeSource) Snapshot() Snapshot {
+	return s.snapshot
+}
+
+// Record three frames, one second apart, with "init" and "sleep" running in
+// all of them
+func makeRecording(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "recording.ftop")
+
+	source := &fakeSource{onUpdate: make(chan struct{})}
+	recorder, err := NewRecorder(source, path)
+	assert.Equal(t, err, nil)
+
+	start := time.Date(2026, 10, 17, 3, 0, 0, 0, time.UTC)
+	procs := []processes.Process{
+		{Pid: 1, Cmdline: "init", Username: "root", RssKb: 100},
+		{Pid: 2, Cmdline: "sleep 1000", Username: "johan", RssKb: 20},
+	}
+	for i := range 3 {
+		source.snapshot = Snapshot{
+			Timestamp: start.Add(time.Duration(i) * time.Second),
+			Processes: procs,
+			IoStats:   []io.Stat{{DeviceName: "disk0", BytesPerSecond: float64(i)}},
+			Sysload:   sysload.Sysload{CpuCoresLogical: 4, LoadAverage1M: float64(i)},
+		}
+		source.onUpdate <- struct{}{}
+		<-recorder.OnUpdate()
+	}
+	recorder.Close()
+
+	return path
+}
+
+func TestRecordingRoundTrip(t *testing.T) {
+	frames, err := openRecording(makeRecording(t))
+	assert.Equal(t, err, nil)
+	defer frames.Close()
+
+	assert.Equal(t, frames.count(), 3)
+	assert.Equal(t, frames.frame(2).Sysload.LoadAverage1M, 2.0)
+	assert.Equal(t, frames.frame(2).IoStats[0].BytesPerSecond, 2.0)
+	assert.Equal(t, frames.frame(2).Processes[1].Cmdline, "sleep 1000")
+	assert.Equal(t, frames.timestamp(1).Sub(frames.timestamp(0)), time.Second)
+
+	// Out of order works as well
+	assert.Equal(t, frames.frame(0).Sysload.LoadAverage1M, 0.0)
+
+	// One gzip member per line, so that we can start reading anywhere
+	for _, location := range frames.locations {
+		assert.Equal(t, location.line, 0)
+	}
+	assert.Equal(t, frames.locations[0].offset < frames.locations[1].offset, true)
+}
+
+// Gzip contents into a single member, like older versions of ftop wrote
+// recordings
+func writeGzipped(t *testing.T, contents string) string {
+	path := filepath.Join(t.TempDir(), "recording.ftop")
+
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	_, err := writer.Write([]byte(contents))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, writer.Close(), nil)
+	assert.Equal(t, os.WriteFile(path, buffer.Bytes(), 0o600), nil)
+
+	return path
+}
+
+func TestOpenRecording_SingleMember(t *testing.T) {
+	recording := `{"format":"ftop-recording","version":1}` + "\n" +
+		`{"timestamp":"2026-10-17T03:00:00Z","processes":[{"pid":1}]}` + "\n" +
+		`{"timestamp":"2026-10-17T03:00:01Z","processes":[{"pid":2}]}` + "\n" +
+		`{"timestamp":"2026-10-17T03:00:02Z","processes":[{"pid":3}]}` + "\n"
+
+	frames, err := openRecording(writeGzipped(t, recording))
+	assert.Equal(t, err, nil)
+	defer frames.Close()
+
+	assert.Equal(t, frames.count(), 3)
+	assert.Equal(t, frames.frame(1).Processes[0].Pid, 2)
+	assert.Equal(t, frames.frame(2).Processes[0].Pid, 3)
+	assert.Equal(t, frames.frame(0).Processes[0].Pid, 1)
+}
+
+func TestOpenRecording_Truncated(t *testing.T) {
+	recording := `{"format":"ftop-recording","version":1}` + "\n" +
+		`{"timestamp":"2026-10-17T03:00:00Z","processes":[]}` + "\n" +
+		`{"timestamp":"2026-10-17T03:00:01Z","proc`
+
+	frames, err := openRecording(writeGzipped(t, recording))
+	assert.Equal(t, err, nil)
+	defer frames.Close()
+	assert.Equal(t, frames.count(), 1)
+}
+
+func TestOpenRecording_TooNew(t *testing.T) {
+	recording := `{"format":"ftop-recording","version":9999}` + "\n"
+
+	_, err := openRecording(writeGzipped(t, recording))
+	assert.Equal(t, err != nil, true)
+}
+
+func TestOpenRecording_NotARecording(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "README.md")
+	assert.Equal(t, os.WriteFile(path, []byte("# Hello\n"), 0o600), nil)
+
+	_, err := openRecording(path)
+	assert.Equal(t, err != nil, true)
+}
+
+func TestOpenRecording_TruncatedMember(t *testing.T) {
+	path := makeRecording(t)
+	frames, err := openRecording(path)
+	assert.Equal(t, err, nil)
+	lastOffset := frames.locations[2].offset
+	frames.Close()
+
+	// Killed while writing the last frame
+	contents, err := os.ReadFile(path)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, os.WriteFile(path, contents[:lastOffset+20], 0o600), nil)
+
+	frames, err = openRecording(path)
+	assert.Equal(t, err, nil)
+	defer frames.Close()
+	assert.Equal(t, frames.count(), 2)
+	assert.Equal(t, frames.frame(1).Sysload.LoadAverage1M, 1.0)
+}
@@ -0,0 +1,382 @@
+package snapshots
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os/exec"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/walles/ftop/internal/log"
+	"github.com/walles/ftop/internal/processes"
+)
+
+// How long to wait for an agent to confirm that it has sent a signal
+const remoteSignalTimeout = 10 * time.Second
+
+// Snapshots from an ftop agent running on some other machine
+type Remote struct {
+	conn     io.ReadWriteCloser
+	hostname string // As reported by the agent
+
+	// If false, the agent will refuse to signal any processes
+	signalsAllowed bool
+
+	onUpdate chan struct{}
+
+	mutex     sync.Mutex
+	latest    Snapshot
+	histories *processes.RecordHistories
+	err       error // Set when the connection is lost
+
+	writeMutex sync.Mutex // Protects conn writes and lastRequestId
+
+	lastRequestId  int
+	pendingMutex   sync.Mutex
+	pendingSignals map[int]chan string // Request IDs to where to send the result
+}
+
+// Connect to an agent started using "ftop --agent --listen address"
+func DialAgent(address string) (*Remote, error) {
+	conn, err := net.Dial("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	remote, err := NewRemote(conn)
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("%s: %w", address, err)
+	}
+	return remote, nil
+}
+
+// The command for starting an agent on an SSH destination. If interactive is
+// false, SSH fails rather than prompting for passwords.
+func SshAgentCommand(destination string, allowKill bool, interactive bool) []string {
+	command := []string{"ssh"}
+	if !interactive {
+		command = append(command, "-o", "BatchMode=yes")
+	}
+
+	// "--" so that destinations can't be mistaken for SSH options
+	command = append(command, "--", destination, "ftop", "--agent")
+	if allowKill {
+		command = append(command, "--allow-kill")
+	}
+	return command
+}
+
+// Run a command that starts an agent, "ssh host ftop --agent" for example, and
+// talk to it through the command's stdin and stdout.
+//
+// Whatever the command says on stderr ends up in the returned error if it
+// fails.
+func StartAgent(argv []string) (*Remote, error) {
+	conn, err := startAgentCommand(argv)
+	if err != nil {
+		return nil, err
+	}
+
+	remote, err := NewRemote(conn)
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("%s: %w%s", strings.Join(argv, " "), err, conn.stderrSuffix())
+	}
+	return remote, nil
+}
+
+// Talk to an agent over conn. Blocks until the agent has said hello, so that
+// connection problems are reported before we return.
+func NewRemote(conn io.ReadWriteCloser) (*Remote, error) {
+	unzipped, err := gzip.NewReader(conn)
+	if err != nil {
+		return nil, fmt.Errorf("no response from ftop agent: %w", err)
+	}
+	reader := bufio.NewReader(unzipped)
+
+	line, err := readLine(reader, maxMessageLength)
+	if err != nil {
+		return nil, fmt.Errorf("no response from ftop agent: %w", err)
+	}
+	var hello agentHello
+	err = json.Unmarshal(line, &hello)
+	if err != nil || hello.Format != agentFormat {
+		return nil, fmt.Errorf("not an ftop agent")
+	}
+	if hello.Version > AGENT_PROTOCOL_VERSION {
+		return nil, fmt.Errorf("agent speaks protocol version %d, this ftop only supports up to version %d", hello.Version, AGENT_PROTOCOL_VERSION)
+	}
+
+	remote := &Remote{
+		conn:           conn,
+		hostname:       hello.Hostname,
+		signalsAllowed: hello.SignalsAllowed,
+		onUpdate:       make(chan struct{}, 1),
+		histories:      processes.NewRecordHistories(),
+		pendingSignals: make(map[int]chan string),
+	}
+
+	go func() {
+		defer func() {
+			log.PanicHandler("remote", recover(), debug.Stack())
+		}()
+
+		err := remote.receive(reader)
+		if err == nil {
+			err = errors.New("connection closed")
+		}
+		log.Infof("Lost connection to %s: %v", remote.hostname, err)
+
+		remote.mutex.Lock()
+		remote.err = err
+		remote.mutex.Unlock()
+		remote.notify()
+
+		// Nothing more will happen, let anybody waiting for updates know
+		close(remote.onUpdate)
+	}()
+
+	return remote, nil
+}
+
+// Closed when the connection is lost, check Err() for why
+func (r *Remote) OnUpdate() <-chan struct{} {
+	return r.onUpdate
+}
+
+// The latest snapshot we got from the agent. Empty until the first one
+// arrives.
+func (r *Remote) Snapshot() Snapshot {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.latest
+}
+
+// Name of the machine the agent is running on
+func (r *Remote) Hostname() string {
+	return r.hostname
+}
+
+// Non-nil if we have lost the connection to the agent
+func (r *Remote) Err() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.err
+}
+
+// False if the agent will refuse to signal any processes
+func (r *Remote) SignalsAllowed() bool {
+	return r.signalsAllowed
+}
+
+func (r *Remote) Close() {
+	err := r.conn.Close()
+	if err != nil {
+		log.Infof("Closing connection to %s failed: %v", r.hostname, err)
+	}
+}
+
+// Ask the agent to signal these processes, parents first. Signal names are
+// without the SIG prefix, "TERM" for example.
+func (r *Remote) Signal(targets []*processes.Process, signalName string) error {
+	if !r.signalsAllowed {
+		return fmt.Errorf("not allowed, start the agent with --allow-kill to allow it")
+	}
+
+	result := make(chan string, 1)
+
+	r.writeMutex.Lock()
+	r.lastRequestId++
+	request := signalRequest{Id: r.lastRequestId, Signal: signalName}
+	r.pendingMutex.Lock()
+	r.pendingSignals[request.Id] = result
+	r.pendingMutex.Unlock()
+	for _, target := range targets {
+		request.Targets = append(request.Targets, target.Record())
+	}
+
+	encoded, err := json.Marshal(clientMessage{Signal: &request})
+	if err == nil {
+		_, err = r.conn.Write(append(encoded, '\n'))
+	}
+	r.writeMutex.Unlock()
+
+	defer func() {
+		r.pendingMutex.Lock()
+		delete(r.pendingSignals, request.Id)
+		r.pendingMutex.Unlock()
+	}()
+
+	if err != nil {
+		return err
+	}
+
+	select {
+	case excuse := <-result:
+		if excuse != "" {
+			return errors.New(excuse)
+		}
+		return nil
+	case <-time.After(remoteSignalTimeout):
+		return fmt.Errorf("no response from %s", r.hostname)
+	}
+}
+
+// True if the process was alive in the latest snapshot from the agent
+func (r *Remote) IsAlive(p *processes.Process) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, candidate := range r.latest.Processes {
+		if candidate.SameAs(p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Returns when the connection fails
+func (r *Remote) receive(reader *bufio.Reader) error {
+	for {
+		line, err := readLine(reader, maxMessageLength)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var message agentMessage
+		err = json.Unmarshal(line, &message)
+		if err != nil {
+			return fmt.Errorf("bad message from agent: %w", err)
+		}
+
+		if message.SignalResult != nil {
+			r.pendingMutex.Lock()
+			result, found := r.pendingSignals[message.SignalResult.Id]
+			r.pendingMutex.Unlock()
+			if found {
+				result <- message.SignalResult.Error
+			}
+		}
+
+		if message.Frame != nil {
+			f := message.Frame
+
+			r.mutex.Lock()
+			r.latest = Snapshot{
+				Timestamp: f.Timestamp,
+				Processes: processes.RestoreProcesses(f.Processes, r.histories, f.Timestamp),
+				IoStats:   f.IoStats,
+				Sysload:   f.Sysload,
+				Launches:  f.Launches,
+			}
+			r.mutex.Unlock()
+
+			r.notify()
+		}
+	}
+}
+
+// Notify asynchronously in case nobody is listening
+func (r *Remote) notify() {
+	select {
+	case r.onUpdate <- struct{}{}:
+	default:
+	}
+}
+
+// The stdin and stdout of an agent command, see StartAgent()
+type agentCommand struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout io.ReadCloser
+	stderr *lockedBuffer
+}
+
+func startAgentCommand(argv []string) (*agentCommand, error) {
+	if len(argv) == 0 {
+		return nil, errors.New("no agent command given")
+	}
+
+	cmd := exec.Command(argv[0], argv[1:]...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr := &lockedBuffer{}
+	cmd.Stderr = stderr
+
+	err = cmd.Start()
+	if err != nil {
+		return nil, err
+	}
+
+	return &agentCommand{cmd: cmd, stdin: stdin, stdout: stdout, stderr: stderr}, nil
+}
+
+func (c *agentCommand) Read(p []byte) (int, error) {
+	return c.stdout.Read(p)
+}
+
+func (c *agentCommand) Write(p []byte) (int, error) {
+	return c.stdin.Write(p)
+}
+
+// Closing stdin makes the agent exit
+func (c *agentCommand) Close() error {
+	err := c.stdin.Close()
+	go func() {
+		// Reap it whenever it's done
+		_ = c.cmd.Wait()
+	}()
+	return err
+}
+
+// ": <what the command said on stderr>", or "" if it said nothing
+func (c *agentCommand) stderrSuffix() string {
+	// Give the command a moment to explain itself before it exits
+	time.Sleep(100 * time.Millisecond)
+
+	stderr := strings.TrimSpace(c.stderr.String())
+	if stderr == "" {
+		return ""
+	}
+	return ": " + stderr
+}
+
+// The command writes stderr from its own goroutine, while we may be reading it
+// from another one
+type lockedBuffer struct {
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buffer.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buffer.String()
+}
@@ -0,0 +1,177 @@
+package snapshots
+
+import (
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/walles/ftop/internal/log"
+)
+
+// When the recording has gaps in it, from a laptop sleeping for example, don't
+// make the user wait for more than this between two frames
+const maxReplayFrameInterval = 5 * time.Second
+
+// Plays back a recording made by a Recorder, in the same pace as it was
+// recorded
+type Replay struct {
+	onUpdate chan struct{}
+
+	// Wakes up the playback goroutine when the user has changed something
+	controls chan struct{}
+
+	frames *recordingFrames
+
+	mutex    sync.Mutex
+	timeline timeline
+	paused   bool
+}
+
+// Frames are read from the recording when needed, call Close() when done
+func NewReplay(path string) (*Replay, error) {
+	frames, err := openRecording(path)
+	if err != nil {
+		return nil, err
+	}
+
+	replay := &Replay{
+		onUpdate: make(chan struct{}, 1),
+		controls: make(chan struct{}, 1),
+		frames:   frames,
+		timeline: timeline{frames: frames},
+	}
+	replay.timeline.goTo(0)
+	replay.onUpdate <- struct{}{} // Get the first frame on screen
+
+	go func() {
+		defer func() {
+			log.PanicHandler("replay", recover(), debug.Stack())
+		}()
+		replay.play()
+	}()
+
+	return replay, nil
+}
+
+// Stop reading from the recording. Don't call Snapshot() after this.
+func (r *Replay) Close() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.frames.Close()
+}
+
+func (r *Replay) OnUpdate() <-chan struct{} {
+	return r.onUpdate
+}
+
+func (r *Replay) Snapshot() Snapshot {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.timeline.snapshot()
+}
+
+// Which frame we are showing, out of how many, and whether playback is paused.
+// Index is zero based.
+func (r *Replay) Position() (index int, count int, paused bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.timeline.index, r.timeline.frames.count(), r.paused
+}
+
+func (r *Replay) TogglePause() {
+	r.mutex.Lock()
+	if r.paused && r.timeline.index == r.timeline.frames.count()-1 {
+		// At the end, start over from the beginning
+		r.timeline.goTo(0)
+	}
+	r.paused = !r.paused
+	r.mutex.Unlock()
+
+	r.changed()
+}
+
+// Pause and move this many frames forwards, or backwards if negative
+func (r *Replay) Step(frames int) {
+	r.mutex.Lock()
+	r.paused = true
+	r.timeline.goTo(r.timeline.index + frames)
+	r.mutex.Unlock()
+
+	r.changed()
+}
+
+// Move this far forwards in time, or backwards if negative. Doesn't affect
+// pausing.
+func (r *Replay) Seek(delta time.Duration) {
+	r.mutex.Lock()
+	frames := r.timeline.frames
+	index := r.timeline.index
+	target := frames.timestamp(index).Add(delta)
+	if delta > 0 {
+		for index < frames.count()-1 && frames.timestamp(index).Before(target) {
+			index++
+		}
+	} else {
+		for index > 0 && frames.timestamp(index).After(target) {
+			index--
+		}
+	}
+	r.timeline.goTo(index)
+	r.mutex.Unlock()
+
+	r.changed()
+}
+
+// Make the playback goroutine reconsider its timing, and the UI redraw
+func (r *Replay) changed() {
+	select {
+	case r.controls <- struct{}{}:
+	default:
+	}
+
+	select {
+	case r.onUpdate <- struct{}{}:
+	default:
+	}
+}
+
+func (r *Replay) play() {
+	for {
+		r.mutex.Lock()
+		frames := r.timeline.frames
+		index := r.timeline.index
+		if index == frames.count()-1 {
+			// At the end
+			r.paused = true
+		}
+		paused := r.paused
+		wait := min(frames.timestamp(min(index+1, frames.count()-1)).Sub(frames.timestamp(index)), maxReplayFrameInterval)
+		r.mutex.Unlock()
+
+		if paused {
+			<-r.controls
+			continue
+		}
+
+		select {
+		case <-r.controls:
+			// Something changed, start over
+			continue
+		case <-time.After(wait):
+		}
+
+		r.mutex.Lock()
+		if !r.paused {
+			r.timeline.goTo(r.timeline.index + 1)
+		}
+		r.mutex.Unlock()
+
+		select {
+		case r.onUpdate <- struct{}{}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package snapshots
+
+import (
+	"testing"
+	"time"
+
+	"github.com/walles/ftop/internal/assert"
+)
+
+func TestReplay(t *testing.T) {
+	replay, err := NewReplay(makeRecording(t))
+	assert.Equal(t, err, nil)
+	defer replay.Close()
+
+	replay.Step(1)
+	index, count, paused := replay.Position()
+	assert.Equal(t, index, 1)
+	assert.Equal(t, count, 3)
+	assert.Equal(t, paused, true)
+	assert.Equal(t, replay.Snapshot().Sysload.LoadAverage1M, 1.0)
+
+	// Processes should have their histories rebuilt up until the current frame
+	snapshot := replay.Snapshot()
+	for _, p := range snapshot.Processes {
+		assert.Equal(t, len(p.History().Samples()), 2)
+	}
+
+	replay.Step(10)
+	index, _, _ = replay.Position()
+	assert.Equal(t, index, 2)
+
+	replay.Seek(-time.Minute)
+	index, _, _ = replay.Position()
+	assert.Equal(t, index, 0)
+	for _, p := range replay.Snapshot().Processes {
+		assert.Equal(t, len(p.History().Samples()), 1)
+	}
+
+	replay.Seek(time.Second)
+	index, _, _ = replay.Position()
+	assert.Equal(t, index, 1)
+}
@@ -0,0 +1,231 @@
+package snapshots
+
+import (
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/walles/ftop/internal/log"
+	"github.com/walles/ftop/internal/processes"
+)
+
+// How far back a Rewinder lets the user go unless asked otherwise
+const DEFAULT_REWIND_LENGTH = 10 * time.Minute
+
+// Passes snapshots from another source through, while keeping the last length
+// of them around for the user to step back through
+type Rewinder struct {
+	source   Source
+	onUpdate chan struct{}
+	length   time.Duration
+
+	mutex    sync.Mutex
+	latest   Snapshot
+	frames   sharedFrames // Oldest first, the last one is the same as latest
+	timeline timeline     // Moves around in frames
+	rewound  bool         // If false, we are showing the latest snapshot
+	ended    bool         // True after our source's updates have ended
+}
+
+func NewRewinder(source Source, length time.Duration) *Rewinder {
+	rewinder := &Rewinder{
+		source:   source,
+		onUpdate: make(chan struct{}, 1),
+		length:   length,
+	}
+
+	go func() {
+		defer func() {
+			log.PanicHandler("rewinder", recover(), debug.Stack())
+		}()
+
+		for range source.OnUpdate() {
+			rewinder.add(source.Snapshot())
+			rewinder.notify()
+		}
+
+		// No more updates coming, let anybody waiting for them know
+		rewinder.mutex.Lock()
+		rewinder.ended = true
+		close(rewinder.onUpdate)
+		rewinder.mutex.Unlock()
+	}()
+
+	return rewinder
+}
+
+// Closed if our source's OnUpdate() channel is closed
+func (r *Rewinder) OnUpdate() <-chan struct{} {
+	return r.onUpdate
+}
+
+func (r *Rewinder) Wrapped() Source {
+	return r.source
+}
+
+// The latest snapshot, or an older one if the user has stepped back in time
+func (r *Rewinder) Snapshot() Snapshot {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.rewound {
+		return r.timeline.snapshot()
+	}
+	return r.latest
+}
+
+// True if Snapshot() returns something older than the latest snapshot
+func (r *Rewinder) IsRewound() bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.rewound
+}
+
+// Move this many snapshots forwards in time, or backwards if negative. Moving
+// forwards to the latest snapshot stops rewinding.
+func (r *Rewinder) Step(frames int) {
+	r.mutex.Lock()
+	latestIndex := len(r.frames) - 1
+	index := latestIndex
+	if r.rewound {
+		index = r.timeline.index
+	}
+	target := max(0, index+frames)
+
+	if target >= latestIndex {
+		r.stopRewinding()
+	} else {
+		r.rewound = true
+		r.timeline.goTo(target)
+	}
+	r.mutex.Unlock()
+
+	r.notify()
+}
+
+// Stop rewinding, and go back to showing the latest snapshot
+func (r *Rewinder) Live() {
+	r.mutex.Lock()
+	r.stopRewinding()
+	r.mutex.Unlock()
+
+	r.notify()
+}
+
+// Must be called with the mutex held
+func (r *Rewinder) stopRewinding() {
+	r.rewound = false
+
+	// Our process histories will be outdated by the next time we rewind. Also,
+	// let the garbage collector have them.
+	r.timeline.reset()
+}
+
+func (r *Rewinder) add(snapshot Snapshot) {
+	f := toFrame(snapshot)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var previous []*processes.ProcessRecord
+	if len(r.frames) > 0 {
+		previous = r.frames[len(r.frames)-1].records
+	}
+	records := shareRecords(f.Processes, previous)
+	f.Processes = nil
+
+	r.latest = snapshot
+	r.frames = append(r.frames, sharedFrame{frame: f, records: records})
+
+	tooOld := 0
+	for tooOld < len(r.frames)-1 && snapshot.Timestamp.Sub(r.frames[tooOld].Timestamp) > r.length {
+		tooOld++
+	}
+	if tooOld > 0 {
+		// Clear the dropped frames so that the garbage collector can have them
+		clear(r.frames[:tooOld])
+		r.frames = r.frames[tooOld:]
+		r.timeline.droppedOldest(tooOld)
+	}
+
+	r.timeline.frames = r.frames
+}
+
+// Frames sharing unchanged process records with the frame before them. At any
+// point in time most processes are idle, so that's most records.
+type sharedFrames []sharedFrame
+
+type sharedFrame struct {
+	frame // Without any Processes, those are in records
+
+	records []*processes.ProcessRecord
+}
+
+func (f sharedFrames) count() int {
+	return len(f)
+}
+
+func (f sharedFrames) timestamp(index int) time.Time {
+	return f[index].Timestamp
+}
+
+func (f sharedFrames) frame(index int) frame {
+	restored := f[index].frame
+	restored.Processes = make([]processes.ProcessRecord, 0, len(f[index].records))
+	for _, record := range f[index].records {
+		restored.Processes = append(restored.Processes, *record)
+	}
+	return restored
+}
+
+// Reuse records from the previous frame for processes that haven't changed.
+// For the ones that have, reuse the strings, command lines are re-read every
+// update and would otherwise take up lots of space.
+func shareRecords(records []processes.ProcessRecord, previous []*processes.ProcessRecord) []*processes.ProcessRecord {
+	byPid := make(map[int]*processes.ProcessRecord, len(previous))
+	for _, record := range previous {
+		byPid[record.Pid] = record
+	}
+
+	shared := make([]*processes.ProcessRecord, 0, len(records))
+	for _, record := range records {
+		old, found := byPid[record.Pid]
+		if found && record.Equal(*old) {
+			shared = append(shared, old)
+			continue
+		}
+
+		if found {
+			if record.Cmdline == old.Cmdline {
+				record.Cmdline = old.Cmdline
+			}
+			if record.Username == old.Username {
+				record.Username = old.Username
+			}
+			if record.Cgroup == old.Cgroup {
+				record.Cgroup = old.Cgroup
+			}
+		}
+
+		// A copy of its own, so that we don't keep all of records alive
+		shared = append(shared, &record)
+	}
+
+	return shared
+}
+
+// Notify asynchronously in case nobody is listening
+func (r *Rewinder) notify() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.ended {
+		return
+	}
+
+	select {
+	case r.onUpdate <- struct{}{}:
+	default:
+	}
+}
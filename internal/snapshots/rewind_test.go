@@ -0,0 +1,77 @@
+package snapshots
+
+import (
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/walles/ftop/internal/assert"
+	"github.com/walles/ftop/internal/processes"
+)
+
+func TestRewinder(t *testing.T) {
+	source := &fakeSource{onUpdate: make(chan struct{})}
+	rewinder := NewRewinder(source, DEFAULT_REWIND_LENGTH)
+
+	start := time.Date(2026, 10, 17, 3, 0, 0, 0, time.UTC)
+	push := func(timestamp time.Time) {
+		source.snapshot = Snapshot{
+			Timestamp: timestamp,
+			Processes: []processes.Process{{Pid: 1, Cmdline: "init", Username: "root"}},
+		}
+		source.onUpdate <- struct{}{}
+		<-rewinder.OnUpdate()
+	}
+	for i := range 5 {
+		push(start.Add(time.Duration(i) * time.Second))
+	}
+
+	assert.Equal(t, rewinder.IsRewound(), false)
+	assert.Equal(t, rewinder.Snapshot().Timestamp, start.Add(4*time.Second))
+
+	rewinder.Step(-1)
+	<-rewinder.OnUpdate()
+	assert.Equal(t, rewinder.IsRewound(), true)
+	assert.Equal(t, rewinder.Snapshot().Timestamp, start.Add(3*time.Second))
+	assert.Equal(t, len(rewinder.Snapshot().Processes[0].History().Samples()), 4)
+
+	// New snapshots shouldn't move what we're looking at
+	push(start.Add(5 * time.Second))
+	assert.Equal(t, rewinder.Snapshot().Timestamp, start.Add(3*time.Second))
+
+	rewinder.Step(-100)
+	<-rewinder.OnUpdate()
+	assert.Equal(t, rewinder.Snapshot().Timestamp, start)
+
+	// Stepping past the latest snapshot takes us back to live
+	rewinder.Step(100)
+	<-rewinder.OnUpdate()
+	assert.Equal(t, rewinder.IsRewound(), false)
+	assert.Equal(t, rewinder.Snapshot().Timestamp, start.Add(5*time.Second))
+
+	// Snapshots older than the rewind length should be forgotten
+	rewinder.Step(-100)
+	<-rewinder.OnUpdate()
+	push(start.Add(DEFAULT_REWIND_LENGTH + 2*time.Second))
+	assert.Equal(t, rewinder.Snapshot().Timestamp, start.Add(2*time.Second))
+}
+
+func TestShareRecords(t *testing.T) {
+	previous := []*processes.ProcessRecord{{Pid: 1, Cmdline: "init"}, {Pid: 2, Cmdline: "bash", RssKb: 10}}
+	records := []processes.ProcessRecord{
+		{Pid: 1, Cmdline: string([]byte("init"))},
+		{Pid: 2, Cmdline: string([]byte("bash")), RssKb: 20},
+		{Pid: 3, Cmdline: "vi"},
+	}
+
+	shared := shareRecords(records, previous)
+
+	// Unchanged, same record
+	assert.Equal(t, shared[0], previous[0])
+
+	// Changed, new record but same command line
+	assert.Equal(t, shared[1].RssKb, 20)
+	assert.Equal(t, unsafe.StringData(shared[1].Cmdline), unsafe.StringData(previous[1].Cmdline))
+
+	assert.Equal(t, shared[2].Cmdline, "vi")
+}
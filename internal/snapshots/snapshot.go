@@ -0,0 +1,84 @@
+package snapshots
+
+import (
+	"time"
+
+	"github.com/walles/ftop/internal/io"
+	"github.com/walles/ftop/internal/processes"
+	"github.com/walles/ftop/internal/sysload"
+)
+
+// Everything ftop shows, at one point in time
+type Snapshot struct {
+	Timestamp time.Time
+	Processes []processes.Process
+	IoStats   []io.Stat
+	Sysload   sysload.Sysload
+	Launches  *processes.LaunchNode // nil until something has been launched
+}
+
+// Where the UI gets its snapshots from. Could be live trackers, could be a
+// recording.
+type Source interface {
+	// Receives a value whenever there is a new snapshot to get
+	OnUpdate() <-chan struct{}
+
+	Snapshot() Snapshot
+}
+
+// Sources passing through snapshots from some other source
+type wrapper interface {
+	Wrapped() Source
+}
+
+// Find the source of type T, either source itself or one wrapped by it. Returns
+// the zero value if there is no such source.
+func Find[T Source](source Source) T {
+	for source != nil {
+		if found, ok := source.(T); ok {
+			return found
+		}
+
+		w, ok := source.(wrapper)
+		if !ok {
+			break
+		}
+		source = w.Wrapped()
+	}
+
+	var none T
+	return none
+}
+
+// Snapshots of what is happening on this machine right now
+type LiveSource struct {
+	procsTracker *processes.Tracker
+	ioTracker    *io.Tracker
+}
+
+func NewLiveSource() *LiveSource {
+	return &LiveSource{
+		procsTracker: processes.NewTracker(),
+		ioTracker:    io.NewTracker(),
+	}
+}
+
+func (s *LiveSource) OnUpdate() <-chan struct{} {
+	return s.procsTracker.OnUpdate
+}
+
+func (s *LiveSource) Snapshot() Snapshot {
+	load, err := sysload.GetSysload()
+	if err != nil {
+		// FIXME: Handle this better. What would the user want here?
+		panic(err)
+	}
+
+	return Snapshot{
+		Timestamp: time.Now(),
+		Processes: s.procsTracker.Processes(),
+		IoStats:   s.ioTracker.Stats(),
+		Sysload:   load,
+		Launches:  s.procsTracker.Launches(),
+	}
+}
@@ -0,0 +1,105 @@
+package snapshots
+
+import (
+	"time"
+
+	"github.com/walles/ftop/internal/processes"
+)
+
+// Where a timeline gets its frames from, oldest first
+type frameStore interface {
+	count() int
+	timestamp(index int) time.Time
+
+	// Can be slow, call it only for frames you're going to show
+	frame(index int) frame
+}
+
+// Frames we can move around in
+type timeline struct {
+	frames frameStore
+	index  int // Of the frame to show, see snapshot()
+
+	// Restored from frames[currentIndex]. Processes is nil if there is
+	// nothing restored.
+	current      Snapshot
+	currentIndex int
+
+	// Up until and including frames[currentIndex], complete from
+	// frames[historiesFrom] and onwards
+	histories     *processes.RecordHistories
+	historiesFrom int
+}
+
+// Callers must hold their mutexes
+func (t *timeline) goTo(index int) {
+	t.index = max(0, min(index, t.frames.count()-1))
+}
+
+// The frame at index, restored and with process histories.
+//
+// Restoring the histories can take a while, which is why we don't do it in
+// goTo(). Holding down a key means lots of goTo() calls for each snapshot()
+// call.
+//
+// Callers must hold their mutexes.
+func (t *timeline) snapshot() Snapshot {
+	if t.current.Processes != nil && t.currentIndex == t.index {
+		return t.current
+	}
+
+	if t.current.Processes != nil && t.index < t.currentIndex {
+		// Going backwards, forget what happened after the frame we're going to
+		t.historiesFrom = max(t.historiesFrom, t.currentIndex-processes.HISTORY_LENGTH+1)
+		t.histories.DropFrom(t.frames.timestamp(t.index))
+	}
+
+	// Every step back leaves the histories covering less time, start over
+	// before they get too short
+	tooShort := t.historiesFrom > 0 && t.index-t.historiesFrom+1 < processes.HISTORY_LENGTH/2
+	tooFarAhead := t.index > t.currentIndex+processes.HISTORY_LENGTH
+
+	if t.current.Processes == nil || tooShort || tooFarAhead {
+		// Rebuild the process histories from the frames leading up to this one
+		t.histories = processes.NewRecordHistories()
+		t.historiesFrom = max(0, t.index-processes.HISTORY_LENGTH+1)
+		for i := t.historiesFrom; i < t.index; i++ {
+			f := t.frames.frame(i)
+			t.histories.Add(f.Processes, f.Timestamp)
+		}
+	} else {
+		// Going forwards, add the frames we skipped. Some of them may have been
+		// dropped already.
+		for i := max(0, t.currentIndex+1); i < t.index; i++ {
+			f := t.frames.frame(i)
+			t.histories.Add(f.Processes, f.Timestamp)
+		}
+	}
+
+	f := t.frames.frame(t.index)
+	t.current = Snapshot{
+		Timestamp: f.Timestamp,
+		Processes: processes.RestoreProcesses(f.Processes, t.histories, f.Timestamp),
+		IoStats:   f.IoStats,
+		Sysload:   f.Sysload,
+		Launches:  f.Launches,
+	}
+	t.currentIndex = t.index
+
+	return t.current
+}
+
+// Call after dropping the n oldest frames from our store. If the frame to show
+// was among them, show the oldest remaining one instead.
+func (t *timeline) droppedOldest(n int) {
+	t.index = max(0, t.index-n)
+	t.currentIndex -= n
+	t.historiesFrom = max(0, t.historiesFrom-n)
+}
+
+// Forget everything we have restored, the next snapshot() call will restore
+// from scratch
+func (t *timeline) reset() {
+	t.current = Snapshot{}
+	t.histories = nil
+}
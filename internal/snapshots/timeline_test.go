@@ -0,0 +1,73 @@
+package snapshots
+
+import (
+	"testing"
+	"time"
+
+	"github.com/walles/ftop/internal/assert"
+	"github.com/walles/ftop/internal/processes"
+)
+
+// Frames kept in memory just the way they are, for testing
+type frameSlice []frame
+
+func (f frameSlice) count() int {
+	return len(f)
+}
+
+func (f frameSlice) timestamp(index int) time.Time {
+	return f[index].Timestamp
+}
+
+func (f frameSlice) frame(index int) frame {
+	return f[index]
+}
+
+func TestTimelineSteppingBack(t *testing.T) {
+	start := time.Date(2026, 10, 17, 3, 0, 0, 0, time.UTC)
+	var frames frameSlice
+	for i := range 20 {
+		records := []processes.ProcessRecord{{Pid: 1, Cmdline: "init", StartTime: start, RssKb: i}}
+		if i < 15 {
+			// Exits before the last frames
+			records = append(records, processes.ProcessRecord{Pid: 2, Cmdline: "make", StartTime: start, RssKb: 100 + i})
+		}
+		frames = append(frames, frame{Timestamp: start.Add(time.Duration(i) * time.Second), Processes: records})
+	}
+
+	// Histories as they would be if we restored the frame at index from scratch
+	fresh := func(index int) map[int][]processes.HistorySample {
+		restored := timeline{frames: frames}
+		restored.goTo(index)
+		return histories(restored.snapshot())
+	}
+
+	tl := timeline{frames: frames}
+	tl.goTo(19)
+	tl.snapshot()
+	built := tl.histories
+
+	tl.goTo(18)
+	assert.Equal(t, len(histories(tl.snapshot())[1]), 19)
+
+	// The exited process should get its history back
+	tl.goTo(10)
+	snapshot := tl.snapshot()
+	assert.Equal(t, len(snapshot.Processes), 2)
+	assert.SlicesEqual(t, histories(snapshot)[1], fresh(10)[1])
+	assert.SlicesEqual(t, histories(snapshot)[2], fresh(10)[2])
+
+	tl.goTo(12)
+	assert.SlicesEqual(t, histories(tl.snapshot())[2], fresh(12)[2])
+
+	// Stepping around shouldn't have needed any rebuilds
+	assert.Equal(t, tl.histories == built, true)
+}
+
+func histories(snapshot Snapshot) map[int][]processes.HistorySample {
+	byPid := make(map[int][]processes.HistorySample)
+	for _, p := range snapshot.Processes {
+		byPid[p.Pid] = p.History().Samples()
+	}
+	return byPid
+}
@@ -13,6 +13,9 @@ type Theme struct {
 
 	highlightedForeground twin.Color
 
+	// For things that need attention, like stuck processes
+	warningForeground twin.Color
+
 	// FIXME: Split into terminalForeground and fallbackForeground?
 	foreground twin.Color
 
@@ -58,6 +61,7 @@ func newDarkTheme(bg *twin.Color) Theme {
 		foreground:         twin.NewColorHex(0xdddddd),
 
 		highlightedForeground: twin.NewColorHex(0xbdebbe),
+		warningForeground:     twin.NewColorHex(0xff8f70),
 
 		loadBarMaxCpu: twin.NewColorHex(0x5f1f22),
 		loadBarMaxRam: twin.NewColorHex(0x1e3568),
@@ -75,6 +79,7 @@ func newLightTheme(bg *twin.Color) Theme {
 		foreground:         twin.NewColorHex(0x000000),
 
 		highlightedForeground: twin.NewColorHex(0x009000),
+		warningForeground:     twin.NewColorHex(0xc03000),
 
 		loadBarMaxCpu: twin.NewColorHex(0xffcccc),
 		loadBarMaxRam: twin.NewColorHex(0xccccff),
@@ -104,6 +109,10 @@ func (t Theme) HighlightedForeground() twin.Color {
 	return t.highlightedForeground
 }
 
+func (t Theme) WarningForeground() twin.Color {
+	return t.warningForeground
+}
+
 func (t Theme) LoadBarMin() twin.Color {
 	return t.Background()
 }
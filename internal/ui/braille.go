@@ -0,0 +1,43 @@
+package ui
+
+// Each braille character has two columns of four dots each
+const BrailleDotsPerRow = 4
+
+// https://en.wikipedia.org/wiki/Braille_Patterns#Identifying.2C_naming_and_ordering
+var brailleLeftBars = []rune{0x00, 0x40, 0x44, 0x46, 0x47}
+var brailleRightBars = []rune{0x00, 0x80, 0xA0, 0xB0, 0xB8}
+
+// Render a bar chart using braille characters, two bars per character.
+//
+// Each entry in bars is the height of one bar in dots, and can go from 0 up to
+// BrailleDotsPerRow * height. Bars grow from the bottom.
+//
+// Returns one string per row, top row first. If there is an odd number of bars,
+// the last character will have an empty right half.
+func BrailleBars(bars []int, height int) []string {
+	rows := make([]string, height)
+	for row := range height {
+		// Dots below this row, bars need to be taller than this to show up here
+		dotsBelow := (height - 1 - row) * BrailleDotsPerRow
+
+		rowRunes := make([]rune, 0, (len(bars)+1)/2)
+		for i := 0; i < len(bars); i += 2 {
+			left := clampDots(bars[i] - dotsBelow)
+
+			right := 0
+			if i+1 < len(bars) {
+				right = clampDots(bars[i+1] - dotsBelow)
+			}
+
+			rowRunes = append(rowRunes, rune(0x2800)+brailleLeftBars[left]+brailleRightBars[right])
+		}
+
+		rows[row] = string(rowRunes)
+	}
+
+	return rows
+}
+
+func clampDots(dots int) int {
+	return max(0, min(dots, BrailleDotsPerRow))
+}
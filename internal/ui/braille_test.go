@@ -0,0 +1,23 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/walles/ftop/internal/assert"
+)
+
+func TestBrailleBarsOneRow(t *testing.T) {
+	assert.SlicesEqual(t, BrailleBars([]int{0, 1, 2, 3, 4}, 1), []string{"⢀⣴⡇"})
+}
+
+func TestBrailleBarsTwoRows(t *testing.T) {
+	assert.SlicesEqual(t, BrailleBars([]int{8, 5, 4, 0}, 2), []string{
+		"⣇⠀",
+		"⣿⡇",
+	})
+}
+
+func TestBrailleBarsOverflow(t *testing.T) {
+	// Too tall bars should be cut off rather than wrap around
+	assert.SlicesEqual(t, BrailleBars([]int{9, -1}, 1), []string{"⡇"})
+}